@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/database"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+var achievementsCmd = &cobra.Command{
+	Use:   "achievements",
+	Short: "List your streaks and milestones",
+	Long: `Lists the milestones you've reached, newest first, and your current streaks of
+days and weeks in a row with an entry.
+
+Milestones are recorded when "thicc add" reaches them, and follow the entries
+when they're deleted, restored or changed:
+  - every 5 lbs or 2 kg lost (or gained, when your goal is to gain)
+  - moving into a BMI category closer to Normal
+  - halfway to your goal, and reaching it
+  - 7, 30, 100 and 365 days in a row with an entry
+  - 4, 12, 26 and 52 weeks in a row with an entry`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+
+		achievements, err := models.GetAchievements(db)
+		if err != nil {
+			fmt.Printf("Error retrieving achievements: %v\n", err)
+			return
+		}
+		weights, err := models.SelectWeights(db, models.WeightSelection{})
+		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+
+		days := analytics.Days(weights)
+		today := models.GetTodayDate()
+		dayStreak, _ := analytics.Streaks(days, today)
+		weekStreak, _ := analytics.WeekStreaks(days, today)
+		fmt.Println(display.RenderAchievementsTable(achievements, dayStreak, weekStreak))
+	},
+}
+
+// syncAchievements brings the recorded achievements in line with the current
+// entries after entries were deleted, restored or changed: achievements of
+// entries that are gone are removed, as are ones the entries no longer reach,
+// such as after a weight was changed, and milestones the remaining entries
+// reach are recorded, so one lost with a deleted entry moves to the next entry
+// that reaches it. Without settings, such as after a reset, nothing is recorded.
+func syncAchievements(db *database.DB, settings *models.Settings) error {
+	if err := models.PruneAchievements(db); err != nil {
+		return err
+	}
+	if settings == nil {
+		return nil
+	}
+
+	achievements, err := models.GetAchievements(db)
+	if err != nil {
+		return err
+	}
+	weights, err := models.SelectWeights(db, models.WeightSelection{})
+	if err != nil {
+		return err
+	}
+	var stale []int
+	for _, a := range analytics.StaleAchievements(achievements, weights, settings) {
+		stale = append(stale, a.ID)
+	}
+	if err := models.DeleteAchievements(db, stale...); err != nil {
+		return err
+	}
+
+	_, err = recordAchievements(db, settings, 0)
+	return err
+}
+
+// recordAchievements records the milestones reached by the entries and
+// returns the titles of those the entry with the given ID reached, to
+// celebrate. Milestones reached before are recorded too, so the list
+// includes ones from before achievements were tracked.
+func recordAchievements(db *database.DB, settings *models.Settings, id int) ([]string, error) {
+	weights, err := models.SelectWeights(db, models.WeightSelection{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Milestones reached without the new entry aren't its doing
+	var others []models.Weight
+	for _, w := range weights {
+		if w.ID != id {
+			others = append(others, w)
+		}
+	}
+	before := make(map[string]bool)
+	for _, m := range analytics.Milestones(others, settings) {
+		before[m.Key] = true
+	}
+
+	var reached []string
+	for _, m := range analytics.Milestones(weights, settings) {
+		title := display.MilestoneTitle(m, settings)
+		recorded, err := models.RecordAchievement(db, models.Achievement{Key: m.Key, Title: title, Date: m.Date, WeightID: m.WeightID})
+		if err != nil {
+			return reached, err
+		}
+		if recorded && !before[m.Key] {
+			reached = append(reached, title)
+		}
+	}
+	return reached, nil
+}
@@ -6,21 +6,42 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/display"
 	"github.com/tryonlinux/thicc/internal/models"
 	"github.com/tryonlinux/thicc/internal/validation"
 )
 
+var (
+	addTime string
+	addNote string
+)
+
 var addCmd = &cobra.Command{
 	Use:   "add <weight> [date]",
 	Short: "Add a new weight entry",
-	Long:  `Add a new weight entry with optional date (defaults to today). Date format: YYYY-MM-DD`,
-	Args:  cobra.RangeArgs(1, 2),
+	Long: `Add a new weight entry with optional date (defaults to today).
+Dates can be YYYY-MM-DD, a numeric date such as 05/01/2024 (see "thicc config date_order"),
+or e.g. yesterday, "3 days ago", "last monday" or "jan 5". Dates after today need --allow-future.
+
+The weight may have a unit suffix (75kg, 165.4lb) or be given in stones and pounds
+(11st 4lb); it is converted to your weight unit. Set "thicc config locale" to type
+decimal commas such as 75,4.
+
+Examples:
+  thicc add 70.5
+  thicc add 165.4lb
+  thicc add "11st 4lb"
+  thicc add 70.5 yesterday
+  thicc add 70.5 2024-12-15 --time 07:30 --note "after run"
+  thicc add 70.5 "3d ago"
+  thicc add 70.5 -- -3d         # Offsets starting with "-" go after --`,
+	Args: cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
 		db := GetDB()
 		settings := GetSettings()
 
 		// Parse and validate weight
-		weight, err := validation.ParseAndValidateWeight(args[0])
+		weight, err := parseWeightArg(args[0])
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
@@ -29,27 +50,72 @@ var addCmd = &cobra.Command{
 		// Parse date (default to today)
 		date := models.GetTodayDate()
 		if len(args) == 2 {
-			date = strings.TrimSpace(args[1])
-			// Validate date format and validity
-			if err := validation.ValidateDate(date); err != nil {
+			date, err = parseEntryDate(args[1])
+			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				return
 			}
 		}
 
+		// Validate optional time and note
+		timeOfDay := strings.TrimSpace(addTime)
+		if err := validation.ValidateTime(timeOfDay); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		note := strings.TrimSpace(addNote)
+		if err := validation.ValidateNote(note); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
 		// Calculate BMI
 		bmi := calculator.CalculateBMI(weight, settings.Height, settings.WeightUnit, settings.HeightUnit)
 
-		// Add to database
-		err = models.AddWeight(db, date, weight, bmi)
+		// Add to database, marking the goals and milestones the entry reached,
+		// and journal both so they can be undone
+		id, reachedGoals, err := models.AddEntry(db, models.Weight{
+			Date:   date,
+			Time:   timeOfDay,
+			Weight: weight,
+			BMI:    bmi,
+			Note:   note,
+		})
 		if err != nil {
 			fmt.Printf("Error adding weight: %v\n", err)
 			return
 		}
 
-		fmt.Printf("Added weight: %.2f %s on %s (BMI: %.1f)\n", weight, settings.WeightUnit, date, bmi)
+		fmt.Printf("Added weight: %s on %s (BMI: %s)\n", display.FormatWeightFor(weight, settings), date, display.FormatBMI(bmi))
+
+		// Goals are celebrated with the achievements below, so only milestones are
+		for _, g := range reachedGoals {
+			if g.IsMilestone() {
+				fmt.Println(display.FormatCelebration("Reached your milestone of " + display.FormatWeightFor(g.Target, settings)))
+			}
+		}
+		if len(reachedGoals) > 0 {
+			if err := reloadSettings(); err != nil {
+				fmt.Printf("Error getting settings: %v\n", err)
+				return
+			}
+		}
+
+		// Celebrate milestones the new entry reached
+		reached, err := recordAchievements(db, settings, id)
+		if err != nil {
+			fmt.Printf("Warning: could not record achievements: %v\n", err)
+		}
+		for _, title := range reached {
+			fmt.Println(display.FormatCelebration(title))
+		}
 
 		// Show updated table
 		showCmd.Run(cmd, []string{})
 	},
 }
+
+func init() {
+	addCmd.Flags().StringVar(&addTime, "time", "", "time of day (HH:MM)")
+	addCmd.Flags().StringVar(&addNote, "note", "", "note for the entry; words starting with # are tags")
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar [year]",
+	Short: "Show a calendar of the days you weighed in",
+	Long: `Shows a year of weigh-ins as a calendar, one column per week, like GitHub's
+contribution graph. Days with entries are shaded by how much your weight
+changed since the previous entry: green towards your goal and red away from
+it, darker for bigger changes. Below the calendar are your current and
+longest streaks of days in a row with an entry.
+
+Examples:
+  thicc calendar         # This year
+  thicc calendar 2024    # A past year`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		today := models.GetTodayDate()
+		year := time.Now().Year()
+		if len(args) == 1 {
+			y, err := strconv.Atoi(strings.TrimSpace(args[0]))
+			if err != nil || y < 1 || y > 9999 {
+				fmt.Println("Error: year must be a number, e.g. 2024")
+				return
+			}
+			year = y
+		}
+
+		// Include the year before, for the change on the year's first days
+		// and streaks running into it
+		weights, err := models.GetWeightsBetweenDates(db, fmt.Sprintf("%04d-01-01", year-1), fmt.Sprintf("%04d-12-31", year))
+		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+
+		opts, err := outputOptions()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Println(display.RenderCalendar(year, analytics.Days(weights), settings, today, opts))
+	},
+}
+
+func init() {
+	calendarCmd.Flags().IntVar(&showWidth, "width", 0, "output width in columns (default: terminal width)")
+}
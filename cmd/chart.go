@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/graphics"
+)
+
+var (
+	chartFormat string
+	chartOutput string
+	chartWidth  int
+	chartHeight int
+)
+
+const (
+	// chartDefaultWidth and chartDefaultHeight are the size of exported charts in pixels
+	chartDefaultWidth  = 1000
+	chartDefaultHeight = 500
+
+	// chartMinSize is the smallest width or height of an exported chart in pixels
+	chartMinSize = 200
+
+	// chartMaxSize is the largest width or height of an exported chart in pixels
+	chartMaxSize = 8000
+)
+
+var chartCmd = &cobra.Command{
+	Use:   "chart [number|date]",
+	Short: "Save a chart of your weights as SVG or PNG",
+	Long: `Saves a chart of weight entries to an image file, for sharing or pasting into
+documents. The chart has the weights and their 7-day trend over time, the
+goal line, BMI category bands and markers for entries with notes.
+
+The format is taken from --format, or else the output file's extension.
+Use -o - to write the chart to standard output. Entries in the trash are left
+out unless --include-trash is given.
+
+Examples:
+  thicc chart                              # Last 20 entries to weight-chart.svg
+  thicc chart 90 -o progress.png           # Last 90 entries as PNG
+  thicc chart 2024-01-01 -o progress.svg   # Entries since 2024-01-01
+  thicc chart "3m ago" -o progress.svg     # Or -- -3m; offsets starting with "-" go after --
+  thicc chart --format png --width 1600 --height 800 -o progress.png`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		format := chartFormat
+		if format == "" {
+			format = graphics.FormatForPath(chartOutput)
+		}
+		if format == "" {
+			format = graphics.ExportFormats[0]
+		}
+		format = strings.ToLower(format)
+		if !slices.Contains(graphics.ExportFormats, format) {
+			fmt.Printf("Error: unknown chart format %q: use %s\n", format, strings.Join(graphics.ExportFormats, ", "))
+			return
+		}
+
+		if chartWidth < chartMinSize || chartWidth > chartMaxSize || chartHeight < chartMinSize || chartHeight > chartMaxSize {
+			fmt.Printf("Error: width and height must be between %d and %d pixels\n", chartMinSize, chartMaxSize)
+			return
+		}
+
+		weights, _, err := loadWeights(db, args, includeTrash)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if len(weights) == 0 {
+			fmt.Println("No weights to chart. Add one with: thicc add <weight> [date]")
+			return
+		}
+		data := display.PrepareChartFor(weights, settings)
+
+		output := chartOutput
+		if output == "" {
+			output = "weight-chart." + format
+		}
+		err = writeOutput(output, func(w io.Writer) error {
+			return graphics.ExportChart(w, format, data, settings, chartWidth, chartHeight)
+		})
+		if err != nil {
+			// Keep errors out of output written to standard output
+			fmt.Fprintf(errorOutput(output), "Error saving chart: %v\n", err)
+			return
+		}
+		if output == "-" {
+			return
+		}
+
+		fmt.Printf("Saved chart of %d entries to %s\n", len(weights), output)
+	},
+}
+
+func init() {
+	chartCmd.Flags().StringVar(&chartFormat, "format", "", "image format: "+strings.Join(graphics.ExportFormats, ", ")+" (default: from the output file's extension, or svg)")
+	chartCmd.Flags().StringVarP(&chartOutput, "output", "o", "", "file to save the chart to, or - for standard output (default: weight-chart.<format>)")
+	chartCmd.Flags().IntVar(&chartWidth, "width", chartDefaultWidth, "chart width in pixels")
+	chartCmd.Flags().IntVar(&chartHeight, "height", chartDefaultHeight, "chart height in pixels")
+	registerIncludeTrash(chartCmd.Flags())
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/validation"
+)
+
+// configKey describes a setting that can be changed with the config command
+type configKey struct {
+	key         string
+	description string
+	validate    func(value string) error
+	// convertsWeights marks settings whose change rewrites every weight entry
+	convertsWeights bool
+}
+
+// configKeys lists the settings the config command can change
+var configKeys = []configKey{
+	{key: "weight_unit", description: "unit for weights; changing it converts every entry (lbs, kg or st)", validate: validation.ValidateWeightUnit, convertsWeights: true},
+	{key: models.DateOrderKey, description: "day/month order for numeric dates such as 05/01/2024 (mdy or dmy)", validate: validation.ValidateDateOrder},
+	{key: models.LocaleKey, description: "language code for number input, e.g. de accepts 75,4 (en, de, fr-CA, ...)", validate: validation.ValidateLocale},
+	{key: models.PrecisionKey, description: "decimals to show weights with, 0 to 4, or auto to follow the increment", validate: validation.ValidatePrecision},
+	{key: models.IncrementKey, description: "smallest step of your scale, e.g. 0.2 or 0.05; new weights are rounded to it (or none)", validate: validation.ValidateIncrement},
+	{key: models.BMIStandardKey, description: "BMI category cut-offs: who, or asian for the lower WHO cut-offs for Asian populations", validate: validation.ValidateBMIStandard},
+	{key: models.SexKey, description: "sex for the ideal weight formulas in goal suggest and BMI-for-age (male, female or none)", validate: validation.ValidateSex},
+	{key: models.BirthDateKey, description: "birth date (YYYY-MM-DD) for BMI-for-age percentiles under 20, or none", validate: validation.ValidateBirthDate},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config [key] [value]",
+	Short: "Show or change preferences",
+	Long: `Shows or changes preferences. With no arguments, lists every preference and its value.
+
+Examples:
+  thicc config                 # List preferences
+  thicc config date_order      # Show one preference
+  thicc config date_order dmy  # Read 05/01/2024 as 5 January 2024
+  thicc config locale de       # Accept decimal commas such as 75,4
+  thicc config weight_unit st  # Convert all entries and the goal to stones
+  thicc config increment 0.2   # Round new weights to 0.2 and show one decimal
+  thicc config precision 1     # Show weights with one decimal
+  thicc config bmi_standard asian  # Use the WHO cut-offs for Asian populations`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+
+		values, err := models.CaptureSettings(db)
+		if err != nil {
+			fmt.Printf("Error getting settings: %v\n", err)
+			return
+		}
+
+		if len(args) == 0 {
+			var rows [][]string
+			for _, k := range configKeys {
+				rows = append(rows, []string{k.key, configValue(values, k.key), k.description})
+			}
+			fmt.Println(display.RenderConfigTable(rows))
+			return
+		}
+
+		key := strings.ToLower(strings.TrimSpace(args[0]))
+		var found *configKey
+		for i := range configKeys {
+			if configKeys[i].key == key {
+				found = &configKeys[i]
+			}
+		}
+		if found == nil {
+			fmt.Printf("Error: Unknown preference %q. Run \"thicc config\" to list them.\n", key)
+			return
+		}
+
+		if len(args) == 1 {
+			fmt.Println(configValue(values, key))
+			return
+		}
+
+		value := strings.ToLower(strings.TrimSpace(args[1]))
+		if err := found.validate(value); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		if found.convertsWeights {
+			changeWeightUnit(value)
+			return
+		}
+
+		if err := models.SetSettingJournaled(db, key, value, fmt.Sprintf("Set %s to %s", key, value)); err != nil {
+			fmt.Printf("Error updating %s: %v\n", key, err)
+			return
+		}
+
+		fmt.Printf("%s set to %s\n", key, value)
+	},
+}
+
+// configValue returns a stored setting value, or its default when unset
+func configValue(values map[string]string, key string) string {
+	if value, ok := values[key]; ok {
+		return value
+	}
+	return models.SettingDefaults[key]
+}
+
+// changeWeightUnit converts every entry, the goal weight and goals to a new unit
+func changeWeightUnit(unit string) {
+	db := GetDB()
+	settings := GetSettings()
+
+	if unit == settings.WeightUnit {
+		fmt.Printf("weight_unit is already %s\n", unit)
+		return
+	}
+
+	// The whole table changes, so the conversion is journaled with a copy of
+	// every entry and goal for undo
+	converted, err := models.ChangeWeightUnit(db, settings.WeightUnit, unit)
+	if err != nil {
+		fmt.Printf("Error converting weights: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Converted %d entries and the goal weight from %s to %s\n", converted, settings.WeightUnit, unit)
+}
@@ -2,38 +2,87 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/tryonlinux/thicc/internal/models"
 )
 
+var deleteSelection selectionFlags
+
 var deleteCmd = &cobra.Command{
-	Use:   "delete <weightId>",
-	Short: "Delete a weight entry",
-	Long:  `Delete a weight entry by its ID (shown in the show command).`,
-	Args:  cobra.ExactArgs(1),
+	Use:   "delete [weightIds]",
+	Short: "Delete weight entries",
+	Long: `Delete weight entries by ID (shown in the show command), date range and/or tag.
+Tags are words starting with # in an entry's note, such as "#import".
+Deleted entries are moved to the trash and can be restored with "thicc trash restore".
+When more than one entry is selected, they are listed and you are asked to confirm.
+
+Examples:
+  thicc delete 3                                  # Delete entry 3
+  thicc delete 3,5,9-14                           # Delete entries 3, 5 and 9 to 14
+  thicc delete --from 2024-01-01 --to 2024-01-31  # Delete all entries in January 2024
+  thicc delete --tag import                       # Delete entries noted with #import
+  thicc delete 9-14 --yes                         # Delete without asking`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		db := GetDB()
+		settings := GetSettings()
+
+		idList := ""
+		if len(args) == 1 {
+			idList = strings.TrimSpace(args[0])
+		}
+		if idList == "" && !deleteSelection.hasFilters() {
+			fmt.Println("Error: Give weight IDs, a date range with --from/--to or a tag with --tag")
+			return
+		}
 
-		// Parse weight ID
-		id, err := strconv.Atoi(strings.TrimSpace(args[0]))
-		if err != nil || id <= 0 {
-			fmt.Println("Error: Weight ID must be a positive number")
+		sel, err := deleteSelection.selection(idList)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		// Delete from database
-		err = models.DeleteWeight(db, id)
+		before, err := selectEntries(db, sel)
 		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+		if len(before) == 0 {
+			fmt.Println("No matching entries to delete.")
+			return
+		}
+
+		if !confirmEntries(before, settings, "moved to the trash", deleteSelection.yes) {
+			return
+		}
+
+		// Move to trash
+		ids := weightIDs(before)
+		summary := fmt.Sprintf("Deleted %d entries", len(ids))
+		if len(ids) == 1 {
+			summary = fmt.Sprintf("Deleted entry %d (%s)", ids[0], before[0].Date)
+		}
+		if err := models.TrashWeights(db, ids, summary); err != nil {
 			fmt.Printf("Error deleting weight: %v\n", err)
 			return
 		}
+		if err := syncAchievements(db, settings); err != nil {
+			fmt.Printf("Warning: could not update achievements: %v\n", err)
+		}
 
-		fmt.Printf("Deleted weight entry with ID %d\n", id)
+		if len(ids) == 1 {
+			fmt.Printf("Moved weight entry with ID %d to the trash (restore with: thicc trash restore %d)\n", ids[0], ids[0])
+		} else {
+			fmt.Printf("Moved %d weight entries to the trash (undo with: thicc undo)\n", len(ids))
+		}
 
 		// Show updated table
 		showCmd.Run(cmd, []string{})
 	},
 }
+
+func init() {
+	deleteSelection.register(deleteCmd)
+}
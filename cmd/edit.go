@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/editor"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <weightId>",
+	Short: "Edit a weight entry in your editor",
+	Long: `Opens a weight entry in $VISUAL or $EDITOR as a small TOML document.
+Change the date, time, weight or note, then save and close the editor to apply.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		// Parse weight ID
+		id, err := strconv.Atoi(strings.TrimSpace(args[0]))
+		if err != nil || id <= 0 {
+			fmt.Println("Error: Weight ID must be a positive number")
+			return
+		}
+
+		entry, err := models.GetWeight(db, id)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		edited, err := editor.Edit(formatEntryDocument(entry, settings), "thicc-entry-*.toml")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		update, err := parseEntryDocument(edited, entry)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		if update.IsEmpty() {
+			fmt.Println("No changes made.")
+			return
+		}
+
+		if err := applyWeightUpdate(db, settings, "edit", []int{id}, update); err != nil {
+			fmt.Printf("Error modifying weight: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Updated weight entry %d\n", id)
+
+		// Show updated table
+		showCmd.Run(cmd, []string{})
+	},
+}
+
+// entryFields returns the fields of a weight entry as written in the editor document
+func entryFields(w *models.Weight) map[string]string {
+	return map[string]string{
+		"date":   w.Date,
+		"time":   w.Time,
+		"weight": strconv.FormatFloat(w.Weight, 'f', -1, 64),
+		"note":   w.Note,
+	}
+}
+
+// formatEntryDocument renders a weight entry as the TOML document shown in the editor
+func formatEntryDocument(w *models.Weight, settings *models.Settings) string {
+	fields := entryFields(w)
+	var doc strings.Builder
+	fmt.Fprintf(&doc, "# Editing weight entry %d (created %s)\n", w.ID, w.CreatedAt)
+	doc.WriteString("# Save and close the editor to apply changes. Lines starting with # are ignored.\n")
+	fmt.Fprintf(&doc, "# date: YYYY-MM-DD (or e.g. yesterday), time: HH:MM or empty, weight: in %s\n\n", settings.WeightUnit)
+	fmt.Fprintf(&doc, "date = %s\n", strconv.Quote(fields["date"]))
+	fmt.Fprintf(&doc, "time = %s\n", strconv.Quote(fields["time"]))
+	fmt.Fprintf(&doc, "weight = %s\n", fields["weight"])
+	fmt.Fprintf(&doc, "note = %s\n", strconv.Quote(fields["note"]))
+	return doc.String()
+}
+
+// parseEntryDocument parses an edited entry document and returns the fields
+// that differ from the original entry. Only fields whose text was changed are
+// parsed, so an unchanged weight isn't rounded again and an unchanged date
+// isn't checked again.
+func parseEntryDocument(doc string, original *models.Weight) (models.WeightUpdate, error) {
+	var update models.WeightUpdate
+
+	changed, err := editor.ChangedValues(doc, entryFields(original))
+	if err != nil {
+		return update, err
+	}
+
+	for key, value := range changed {
+		switch key {
+		case "date":
+			date, err := parseEntryDate(value)
+			if err != nil {
+				return update, fmt.Errorf("date: %w", err)
+			}
+			if date != original.Date {
+				update.Date = &date
+			}
+		case "time":
+			update.Time = &value
+		case "weight":
+			weight, err := parseWeightArg(value)
+			if err != nil {
+				return update, fmt.Errorf("weight: %w", err)
+			}
+			if weight != original.Weight {
+				update.Weight = &weight
+			}
+		case "note":
+			update.Note = &value
+		}
+	}
+
+	return update, nil
+}
@@ -2,42 +2,414 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
+	"math"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/database"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
 	"github.com/tryonlinux/thicc/internal/validation"
 )
 
+var (
+	goalDeadline   string
+	goalMilestones []string
+	goalRange      string
+	goalBMI        float64
+)
+
 var goalCmd = &cobra.Command{
-	Use:   "goal <weight>",
+	Use:   "goal [weight]",
 	Short: "Set your goal weight",
-	Long:  `Set or update your goal weight target.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Set a new goal weight, replacing the active goal, which is kept in the goal history.
+The weight may have a unit suffix (70kg, 145lb) or be given in stones and pounds (10st 5lb).
+Without a weight, shows the active goal. For maintenance, give a range to stay within
+instead of a weight; it's shaded on the graph. Or give a BMI to aim for at your height.
+
+A goal can have a deadline and milestones: targets on the way to it, drawn as lines of
+their own on the graph. Goals and milestones are marked achieved when an entry reaches them.
+
+Examples:
+  thicc goal 145
+  thicc goal 145 --by 2026-12-31
+  thicc goal 145 --milestone 160 --milestone 155
+  thicc goal --range 143-147   # Stay between 143 and 147
+  thicc goal --bmi 23          # The weight for a BMI of 23
+  thicc goal suggest           # Suggested goals for your height
+  thicc goal milestone 150     # Add a milestone to the active goal
+  thicc goal list              # Show the active goal and its milestones
+  thicc goal history           # Show every goal you've set
+  thicc goal abandon           # Give up on the active goal`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		given := 0
+		for _, set := range []bool{len(args) == 1, goalRange != "", goalBMI != 0} {
+			if set {
+				given++
+			}
+		}
+		if given == 0 {
+			goalListCmd.Run(cmd, args)
+			return
+		}
+		if given > 1 {
+			fmt.Println("Error: give only one of a goal weight, --range or --bmi")
+			return
+		}
+
+		settings := GetSettings()
+
+		// Parse and validate goal weight, range or BMI, and the deadline
+		var goal models.Goal
+		var err error
+		switch {
+		case goalRange != "":
+			goal.RangeLow, goal.RangeHigh, err = parseGoalRange(goalRange)
+			goal.Target = (goal.RangeLow + goal.RangeHigh) / 2
+		case goalBMI != 0:
+			if err = validation.ValidateBMI(goalBMI); err == nil {
+				goal.Target = settings.RoundWeight(calculator.WeightForBMI(goalBMI, settings.Height, settings.WeightUnit, settings.HeightUnit))
+			}
+		default:
+			goal.Target, err = parseWeightArg(args[0])
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if goalDeadline != "" {
+			goal.Deadline, err = parseFilterDate(goalDeadline)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if goal.Deadline <= models.GetTodayDate() {
+				fmt.Println("Error: the deadline must be after today")
+				return
+			}
+		}
+
+		setGoal(cmd, goal, goalMilestones)
+	},
+}
+
+var goalSuggestCmd = &cobra.Command{
+	Use:   "suggest [name]",
+	Short: "Suggest goals for your height",
+	Long: `Suggests goals from your height: the weights for a healthy BMI of 18.5 to 24.9, and
+the Devine, Robinson, Miller and Hamwi ideal body weight formulas, which need your
+sex (thicc config sex <male|female>). Give a suggestion's name to adopt it as your
+goal; the healthy BMI range becomes a goal range.
+
+Examples:
+  thicc goal suggest           # List suggestions
+  thicc goal suggest healthy   # Stay within the healthy BMI range
+  thicc goal suggest devine    # Aim for the Devine ideal weight`,
+	Args: cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		settings := GetSettings()
+		suggestions := analytics.SuggestGoals(settings)
+
+		if len(args) == 0 {
+			latest, err := latestWeight(GetDB())
+			if err != nil {
+				fmt.Printf("Error retrieving weights: %v\n", err)
+				return
+			}
+			fmt.Println(display.RenderGoalSuggestions(suggestions, latest, settings))
+			if settings.Sex == "" {
+				fmt.Println("Set your sex to see ideal body weight formulas: thicc config sex <male|female>")
+			}
+			return
+		}
+
+		suggestion, ok := analytics.FindSuggestion(suggestions, args[0])
+		if !ok {
+			if settings.Sex == "" {
+				fmt.Printf("Error: unknown suggestion %q. Ideal weight formulas need your sex: thicc config sex <male|female>\n", args[0])
+			} else {
+				fmt.Printf("Error: unknown suggestion %q. Run \"thicc goal suggest\" to list them.\n", args[0])
+			}
+			return
+		}
+
+		goal := models.Goal{Target: settings.RoundWeight(suggestion.Low)}
+		if suggestion.IsRange() {
+			goal.RangeLow, goal.RangeHigh = settings.RoundWeight(suggestion.Low), settings.RoundWeight(suggestion.High)
+			goal.Target = (goal.RangeLow + goal.RangeHigh) / 2
+		}
+		setGoal(cmd, goal, nil)
+	},
+}
+
+var goalListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the active goal and its milestones",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		goal, err := activeGoal(db)
+		if err != nil {
+			fmt.Printf("Error retrieving goals: %v\n", err)
+			return
+		}
+		if goal == nil {
+			fmt.Println("No active goal. Set one with: thicc goal <weight>")
+			return
+		}
+		milestones, err := models.GetMilestones(db, goal.ID)
+		if err != nil {
+			fmt.Printf("Error retrieving goals: %v\n", err)
+			return
+		}
+		weights, err := models.SelectWeights(db, models.WeightSelection{})
+		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+
+		fmt.Println(display.RenderActiveGoal(*goal, milestones, weights, settings))
+	},
+}
+
+var goalHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show every goal you've set",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		if _, err := activeGoal(db); err != nil {
+			fmt.Printf("Error retrieving goals: %v\n", err)
+			return
+		}
+		goals, err := models.GetGoals(db)
+		if err != nil {
+			fmt.Printf("Error retrieving goals: %v\n", err)
+			return
+		}
+		if len(goals) == 0 {
+			fmt.Println("No goals yet. Set one with: thicc goal <weight>")
+			return
+		}
+
+		milestones := make(map[int][]models.Goal, len(goals))
+		for _, g := range goals {
+			if milestones[g.ID], err = models.GetMilestones(db, g.ID); err != nil {
+				fmt.Printf("Error retrieving goals: %v\n", err)
+				return
+			}
+		}
+
+		fmt.Println(display.RenderGoalHistory(goals, milestones, settings))
+	},
+}
+
+var goalAbandonCmd = &cobra.Command{
+	Use:   "abandon",
+	Short: "Give up on the active goal",
+	Long: `Marks the active goal and its milestones abandoned. The goal stays in the goal
+history, and the graph keeps showing its weight until you set a new goal.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		db := GetDB()
 		settings := GetSettings()
 
-		// Parse and validate goal weight
-		goalWeight, err := validation.ParseAndValidateWeight(args[0])
+		goal, err := activeGoal(db)
+		if err != nil {
+			fmt.Printf("Error retrieving goals: %v\n", err)
+			return
+		}
+		if goal == nil {
+			fmt.Printf("Error: %v\n", models.ErrNoActiveGoal)
+			return
+		}
+
+		target := display.FormatGoalTarget(*goal, settings)
+		if err := models.AbandonGoal(db, goal.ID, models.GetTodayDate(), fmt.Sprintf("Abandoned goal of %s", target)); err != nil {
+			fmt.Printf("Error abandoning goal: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Abandoned goal of %s. Set a new one with: thicc goal <weight>\n", target)
+	},
+}
+
+var goalMilestoneCmd = &cobra.Command{
+	Use:   "milestone <weight>",
+	Short: "Add a milestone to the active goal",
+	Long: `Adds a milestone to the active goal: a target on the way to it, between your
+latest weight and the goal. Milestones are drawn as lines of their own on the graph.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		goal, err := activeGoal(db)
+		if err != nil {
+			fmt.Printf("Error retrieving goals: %v\n", err)
+			return
+		}
+		if goal == nil {
+			fmt.Printf("Error: %v\n", models.ErrNoActiveGoal)
+			return
+		}
+		latest, err := latestWeight(db)
+		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+		milestone, err := parseMilestone(args[0], latest, goal.TargetFrom(latest))
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		// Update in database
-		_, err = db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES ('goal_weight', ?)",
-			strconv.FormatFloat(goalWeight, 'f', 2, 64))
+		weight := display.FormatWeightFor(milestone, settings)
+		err = models.AddMilestone(db, models.Goal{
+			ParentID:    goal.ID,
+			Target:      milestone,
+			StartDate:   models.GetTodayDate(),
+			StartWeight: latest,
+		}, fmt.Sprintf("Added milestone %s", weight))
 		if err != nil {
-			fmt.Printf("Error updating goal weight: %v\n", err)
+			fmt.Printf("Error adding milestone: %v\n", err)
 			return
 		}
 
-		// Update settings in memory
-		settings.GoalWeight = goalWeight
+		if err := reloadSettings(); err != nil {
+			fmt.Printf("Error getting settings: %v\n", err)
+			return
+		}
 
-		fmt.Printf("Goal weight set to %.2f %s\n", goalWeight, settings.WeightUnit)
+		fmt.Printf("Added milestone %s on the way to %s\n", weight, display.FormatGoalTarget(*goal, settings))
 
-		// Show updated table
 		showCmd.Run(cmd, []string{})
 	},
 }
+
+// setGoal replaces the active goal with a new one and its milestones, parsed
+// from their inputs, then shows the updated table
+func setGoal(cmd *cobra.Command, goal models.Goal, milestoneInputs []string) {
+	db := GetDB()
+	settings := GetSettings()
+
+	startWeight, err := latestWeight(db)
+	if err != nil {
+		fmt.Printf("Error retrieving weights: %v\n", err)
+		return
+	}
+	var milestones []float64
+	for _, input := range milestoneInputs {
+		milestone, err := parseMilestone(input, startWeight, goal.TargetFrom(startWeight))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		milestones = append(milestones, milestone)
+	}
+
+	// A goal weight set before goals were recorded goes into the history
+	// first, so the new goal replaces it
+	if _, err := activeGoal(db); err != nil {
+		fmt.Printf("Error updating goal weight: %v\n", err)
+		return
+	}
+
+	goal.StartDate, goal.StartWeight = models.GetTodayDate(), startWeight
+	target := display.FormatGoalTarget(goal, settings)
+	summary := fmt.Sprintf("Set goal weight to %s", target)
+	if goal.IsRange() {
+		summary = fmt.Sprintf("Set goal range to %s", target)
+	}
+	if _, err := models.SetGoal(db, goal, milestones, summary); err != nil {
+		fmt.Printf("Error updating goal weight: %v\n", err)
+		return
+	}
+
+	if err := reloadSettings(); err != nil {
+		fmt.Printf("Error getting settings: %v\n", err)
+		return
+	}
+
+	if goal.IsRange() {
+		fmt.Printf("Goal range set to %s\n", target)
+	} else {
+		fmt.Printf("Goal weight set to %s\n", target)
+	}
+
+	// Show updated table
+	showCmd.Run(cmd, []string{})
+}
+
+// activeGoal returns the active goal, first recording the goal weight as one
+// when no goals have been recorded yet
+func activeGoal(db *database.DB) (*models.Goal, error) {
+	if err := models.SeedGoal(db, GetSettings()); err != nil {
+		return nil, err
+	}
+	return models.GetActiveGoal(db)
+}
+
+// latestWeight returns the weight of the newest entry, or zero when there are none
+func latestWeight(db *database.DB) (float64, error) {
+	weights, err := models.GetWeights(db, 1)
+	if err != nil || len(weights) == 0 {
+		return 0, err
+	}
+	return weights[0].Weight, nil
+}
+
+// parseMilestone parses a milestone weight, which must lie between the latest
+// weight and the goal. Without entries it only has to differ from the goal.
+func parseMilestone(input string, latest, goal float64) (float64, error) {
+	milestone, err := parseWeightArg(input)
+	if err != nil {
+		return 0, err
+	}
+	if milestone == goal {
+		return 0, fmt.Errorf("milestone %s is the goal itself", input)
+	}
+	between := math.Min(latest, goal) < milestone && milestone < math.Max(latest, goal)
+	if latest != 0 && !between {
+		return 0, fmt.Errorf("milestone %s must be between your latest weight and the goal", input)
+	}
+	return milestone, nil
+}
+
+// parseGoalRange parses a goal range such as 143-147, or 65kg-67kg
+func parseGoalRange(input string) (low, high float64, err error) {
+	lowInput, highInput, ok := strings.Cut(input, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid goal range %q: use low-high, e.g. 143-147", input)
+	}
+	if low, err = parseWeightArg(strings.TrimSpace(lowInput)); err != nil {
+		return 0, 0, err
+	}
+	if high, err = parseWeightArg(strings.TrimSpace(highInput)); err != nil {
+		return 0, 0, err
+	}
+	if low >= high {
+		return 0, 0, fmt.Errorf("invalid goal range %q: the low end must be below the high end", input)
+	}
+	return low, high, nil
+}
+
+func init() {
+	goalCmd.Flags().StringVar(&goalDeadline, "by", "", "date to reach the goal by")
+	goalCmd.Flags().StringVar(&goalRange, "range", "", "a range to stay within instead of a goal weight, e.g. 143-147")
+	goalCmd.Flags().Float64Var(&goalBMI, "bmi", 0, "a BMI to aim for instead of a goal weight, e.g. 23")
+	goalCmd.Flags().StringArrayVar(&goalMilestones, "milestone", nil, "a target on the way to the goal (repeatable)")
+
+	goalCmd.AddCommand(goalListCmd)
+	goalCmd.AddCommand(goalHistoryCmd)
+	goalCmd.AddCommand(goalAbandonCmd)
+	goalCmd.AddCommand(goalMilestoneCmd)
+	goalCmd.AddCommand(goalSuggestCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/validation"
+)
+
+var growthImportSex string
+
+var growthCmd = &cobra.Command{
+	Use:   "growth",
+	Short: "Show BMI-for-age percentiles of entries made before age 20",
+	Long: `Adult BMI categories don't apply to children and teens, whose BMI is instead compared
+with others of the same age and sex. With a birth date and sex set, entries made from age 2
+up to 20 show their BMI-for-age percentile, z-score and category: underweight below the 5th
+percentile, healthy weight, overweight from the 85th and obesity from the 95th. The header
+of show uses them instead of the adult categories.
+
+The percentiles come from the CDC's BMI-for-age growth reference (bmiagerev.csv from the
+CDC growth charts data files), which ships with thicc. To use another, such as a WHO table,
+import it; it replaces the CDC's for the sexes it covers.
+
+Examples:
+  thicc config birth_date 2015-04-02
+  thicc config sex female
+  thicc growth
+  thicc growth import bmi-girls-z-who-2007.csv --sex female`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		if settings.BirthDate == "" {
+			fmt.Println("Set a birth date first: thicc config birth_date <YYYY-MM-DD>")
+			return
+		}
+		if settings.Sex == "" {
+			fmt.Println("Warning: set a sex to compare with the growth reference: thicc config sex <male|female>")
+		}
+		if len(settings.Growth) == 0 {
+			fmt.Println("Warning: no growth reference available. Import one with: thicc growth import <file>")
+		}
+
+		weights, err := models.GetWeights(db, display.DefaultDisplayLimit)
+		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+		if len(weights) == 0 {
+			fmt.Println("No weights tracked. Add one with: thicc add <weight> [date]")
+			return
+		}
+		if _, ok := analytics.ChildBMI(weights[len(weights)-1].BMI, weights[len(weights)-1].Date, settings); !ok {
+			fmt.Println("No entries were made before age 20; adult BMI categories apply.")
+			return
+		}
+
+		fmt.Println(display.RenderGrowthTable(weights, settings))
+	},
+}
+
+var growthImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a BMI-for-age growth reference to use instead of the CDC's",
+	Long: `Imports a BMI-for-age growth reference from a CSV file of LMS values, used instead of
+the CDC reference that ships with thicc for the sexes it covers. The file needs a header row naming Agemos (or Month),
+L, M and S columns, and a Sex column (1 for male, 2 for female) like the CDC's
+bmiagerev.csv. For a file covering one sex, such as a WHO table, give it with --sex.
+
+Examples:
+  thicc growth import bmiagerev.csv
+  thicc growth import bmi-girls-z-who-2007.csv --sex female`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+
+		if growthImportSex != "" {
+			if err := validation.ValidateSex(growthImportSex); err != nil || growthImportSex == "none" {
+				fmt.Println("Error: --sex must be 'male' or 'female'")
+				return
+			}
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer file.Close()
+
+		values, err := calculator.ParseLMSTable(file, growthImportSex)
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", args[0], err)
+			return
+		}
+		if err := models.ImportGrowthReference(db, values); err != nil {
+			fmt.Printf("Error importing growth reference: %v\n", err)
+			return
+		}
+
+		table := calculator.NewLMSTable(values)
+		for _, sex := range []string{calculator.Male, calculator.Female} {
+			if ages := table[sex]; len(ages) > 0 {
+				fmt.Printf("Imported %d %s ages from %.1f to %.1f months\n", len(ages), sex, ages[0].AgeMonths, ages[len(ages)-1].AgeMonths)
+			}
+		}
+	},
+}
+
+func init() {
+	growthImportCmd.Flags().StringVar(&growthImportSex, "sex", "", "the sex a file without a Sex column is for (male or female)")
+
+	growthCmd.AddCommand(growthImportCmd)
+}
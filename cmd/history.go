@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [number]",
+	Short: "List recent changes",
+	Long: `Lists recent changes that can be undone or redone, newest first.
+
+Examples:
+  thicc history       # Show last 20 changes
+  thicc history 50    # Show last 50 changes`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{skipSetupAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+
+		limit := display.DefaultDisplayLimit
+		if len(args) == 1 {
+			num, err := strconv.Atoi(strings.TrimSpace(args[0]))
+			if err != nil || num <= 0 {
+				fmt.Println("Error: Number must be positive")
+				return
+			}
+			limit = num
+		}
+
+		ops, err := models.GetOperations(db, limit)
+		if err != nil {
+			fmt.Printf("Error retrieving history: %v\n", err)
+			return
+		}
+
+		fmt.Println(display.RenderOperationsTable(ops))
+	},
+}
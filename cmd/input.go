@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/validation"
+)
+
+// allowFuture permits dates after today on entries (set by --allow-future)
+var allowFuture bool
+
+// parseEntryDate parses a date to store on an entry and returns it in
+// YYYY-MM-DD format. Future dates are rejected unless --allow-future is given.
+func parseEntryDate(input string) (string, error) {
+	now := time.Now()
+
+	date, err := validation.NormalizeDate(input, now, validation.DateOrder(GetSettings().DateOrder))
+	if err != nil {
+		return "", err
+	}
+
+	if !allowFuture {
+		if err := validation.ValidateNotFuture(date, now); err != nil {
+			return "", err
+		}
+	}
+
+	return date, nil
+}
+
+// parseFilterDate parses a date used to select entries and returns it in
+// YYYY-MM-DD format. Future dates are allowed since nothing is stored.
+func parseFilterDate(input string) (string, error) {
+	return validation.NormalizeDate(input, time.Now(), validation.DateOrder(GetSettings().DateOrder))
+}
+
+// parseWeightArg parses a weight typed by the user, converting any unit suffix
+// to the configured weight unit and reading decimals according to the locale.
+// The weight is rounded to the scale increment or precision when one is set,
+// and validated again since rounding can take it out of range or to zero.
+func parseWeightArg(input string) (float64, error) {
+	settings := GetSettings()
+	weight, err := validation.ParseWeightInput(input, settings.WeightUnit, settings.Locale)
+	if err != nil {
+		return 0, err
+	}
+	weight = settings.RoundWeight(weight)
+	if err := validation.ValidateWeight(weight); err != nil {
+		return 0, err
+	}
+	return weight, nil
+}
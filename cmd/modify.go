@@ -2,51 +2,205 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/database"
 	"github.com/tryonlinux/thicc/internal/models"
 	"github.com/tryonlinux/thicc/internal/validation"
 )
 
+var (
+	modifyDate      string
+	modifyTime      string
+	modifyWeight    string
+	modifyNote      string
+	modifySelection selectionFlags
+)
+
 var modifyCmd = &cobra.Command{
-	Use:   "modify <weightId> <weight>",
-	Short: "Modify a weight entry",
-	Long:  `Modify a weight entry by its ID (shown in the show command).`,
-	Args:  cobra.ExactArgs(2),
+	Use:   "modify [weightIds] [weight]",
+	Short: "Modify weight entries",
+	Long: `Modify weight entries by ID (shown in the show command), date range and/or tag.
+Tags are words starting with # in an entry's note, such as "#import".
+Only the fields you pass are changed; entries keep their IDs.
+When more than one entry is selected, they are listed and you are asked to confirm.
+With --from/--to or --tag, give the new weight with --weight rather than as an argument.
+
+Examples:
+  thicc modify 5 69.8                 # Change the weight
+  thicc modify 5 --date 2024-01-02    # Move the entry to another date
+  thicc modify 5 --time 07:30         # Set the time of day (use "" to clear)
+  thicc modify 5 --note "after run"   # Set the note (use "" to clear)
+  thicc modify 3,5,9-14 --note "new scale"
+  thicc modify --from 2024-01-01 --to 2024-01-31 --time 07:00 --yes
+  thicc modify --from 2024-01-01 --to 2024-01-31 --weight 70 --yes
+  thicc modify --tag import --time 07:00`,
+	Args: cobra.MaximumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		db := GetDB()
 		settings := GetSettings()
 
-		// Parse weight ID
-		id, err := strconv.Atoi(strings.TrimSpace(args[0]))
-		if err != nil || id <= 0 {
-			fmt.Println("Error: Weight ID must be a positive number")
+		// With a date range or tag, a lone argument could be an ID list or a
+		// weight, so the weight must be given with --weight
+		if modifySelection.hasFilters() && len(args) > 0 {
+			fmt.Println("Error: With --from/--to or --tag, select entries by those flags only and give the new weight with --weight")
+			return
+		}
+
+		idList := ""
+		if len(args) >= 1 {
+			idList = strings.TrimSpace(args[0])
+		}
+		if idList == "" && !modifySelection.hasFilters() {
+			fmt.Println("Error: Give weight IDs, a date range with --from/--to or a tag with --tag")
 			return
 		}
 
-		// Parse and validate weight
-		weight, err := validation.ParseAndValidateWeight(args[1])
+		sel, err := modifySelection.selection(idList)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		// Calculate new BMI
-		bmi := calculator.CalculateBMI(weight, settings.Height, settings.WeightUnit, settings.HeightUnit)
+		// The weight may be given positionally or with --weight, but not both
+		weightArg := modifyWeight
+		if len(args) == 2 {
+			if cmd.Flags().Changed("weight") {
+				fmt.Println("Error: Give the weight either as an argument or with --weight, not both")
+				return
+			}
+			weightArg = args[1]
+		}
 
-		// Update in database
-		err = models.ModifyWeight(db, id, weight, bmi)
+		var update models.WeightUpdate
+		if len(args) == 2 || cmd.Flags().Changed("weight") {
+			weight, err := parseWeightArg(weightArg)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			update.Weight = &weight
+		}
+		if cmd.Flags().Changed("date") {
+			date, err := parseEntryDate(modifyDate)
+			if err != nil {
+				fmt.Printf("Error: --date: %v\n", err)
+				return
+			}
+			update.Date = &date
+		}
+		if cmd.Flags().Changed("time") {
+			timeOfDay := strings.TrimSpace(modifyTime)
+			update.Time = &timeOfDay
+		}
+		if cmd.Flags().Changed("note") {
+			note := strings.TrimSpace(modifyNote)
+			update.Note = &note
+		}
+
+		if update.IsEmpty() {
+			fmt.Println("Error: Nothing to modify. Pass a weight or at least one of --weight, --date, --time, --note")
+			return
+		}
+
+		entries, err := selectEntries(db, sel)
 		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Println("No matching entries to modify.")
+			return
+		}
+
+		if !confirmEntries(entries, settings, "modified", modifySelection.yes) {
+			return
+		}
+
+		ids := weightIDs(entries)
+		if err := applyWeightUpdate(db, settings, "modify", ids, update); err != nil {
 			fmt.Printf("Error modifying weight: %v\n", err)
 			return
 		}
 
-		fmt.Printf("Updated weight entry %d to %.2f %s (BMI: %.1f)\n", id, weight, settings.WeightUnit, bmi)
+		if len(ids) == 1 {
+			fmt.Printf("Updated weight entry %d\n", ids[0])
+		} else {
+			fmt.Printf("Updated %d weight entries\n", len(ids))
+		}
 
 		// Show updated table
 		showCmd.Run(cmd, []string{})
 	},
 }
+
+func init() {
+	modifyCmd.Flags().StringVar(&modifyWeight, "weight", "", "new weight")
+	modifyCmd.Flags().StringVar(&modifyDate, "date", "", "new date (YYYY-MM-DD, yesterday, -3d, jan 5, ...)")
+	modifyCmd.Flags().StringVar(&modifyTime, "time", "", "new time of day (HH:MM)")
+	modifyCmd.Flags().StringVar(&modifyNote, "note", "", "new note")
+	modifySelection.register(modifyCmd)
+}
+
+// applyWeightUpdate validates the fields set in the update, recalculates BMI
+// when the weight changes, and saves the entries and journals the change under
+// the given command in one transaction. Achievements are then updated to match.
+func applyWeightUpdate(db *database.DB, settings *models.Settings, command string, ids []int, update models.WeightUpdate) error {
+	if update.Date != nil {
+		if err := validation.ValidateDate(*update.Date); err != nil {
+			return err
+		}
+	}
+	if update.Time != nil {
+		if err := validation.ValidateTime(*update.Time); err != nil {
+			return err
+		}
+	}
+	if update.Note != nil {
+		if err := validation.ValidateNote(*update.Note); err != nil {
+			return err
+		}
+	}
+	if update.Weight != nil {
+		if err := validation.ValidateWeight(*update.Weight); err != nil {
+			return err
+		}
+		bmi := calculator.CalculateBMI(*update.Weight, settings.Height, settings.WeightUnit, settings.HeightUnit)
+		update.BMI = &bmi
+	}
+
+	summary := fmt.Sprintf("Modified %d entries (%s)", len(ids), describeWeightUpdate(update))
+	if len(ids) == 1 {
+		summary = fmt.Sprintf("Modified entry %d (%s)", ids[0], describeWeightUpdate(update))
+	}
+	if err := models.ChangeWeights(db, command, summary, ids, update); err != nil {
+		return err
+	}
+
+	// Changed weights and dates can reach different milestones, or no longer
+	// reach ones that were recorded
+	if err := syncAchievements(db, settings); err != nil {
+		fmt.Printf("Warning: could not update achievements: %v\n", err)
+	}
+	return nil
+}
+
+// describeWeightUpdate lists the fields changed by an update for the history
+func describeWeightUpdate(update models.WeightUpdate) string {
+	var fields []string
+	if update.Date != nil {
+		fields = append(fields, "date")
+	}
+	if update.Time != nil {
+		fields = append(fields, "time")
+	}
+	if update.Weight != nil {
+		fields = append(fields, "weight")
+	}
+	if update.Note != nil {
+		fields = append(fields, "note")
+	}
+	return strings.Join(fields, ", ")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"io"
+	"os"
+)
+
+// writeOutput writes a generated file, like a chart or report, with write.
+// A path of "-" writes to standard output. A file that fails part way
+// through is removed rather than left incomplete.
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// errorOutput returns where to print errors about writing a generated file:
+// standard error when the file goes to standard output, else standard output
+// like other messages
+func errorOutput(path string) io.Writer {
+	if path == "-" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
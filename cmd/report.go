@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/database"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/pdf"
+	"github.com/tryonlinux/thicc/internal/report"
+)
+
+var (
+	reportOutput   string
+	reportRange    selectionFlags
+	reportPageSize string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate a progress report file",
+	Long: `Generates a progress report of your weight entries to share, e.g. with a coach.
+
+Reports take the same selection as show: the last number of entries, or the
+entries since a date. Use --from and --to instead for a date range. Entries in
+the trash are left out unless --include-trash is given.
+
+Examples:
+  thicc report html                        # Last 20 entries to thicc-report.html
+  thicc report html 2024-01-01 -o 2024.html
+  thicc report html "3 months ago" -o -    # Write to standard output
+  thicc report pdf --page letter           # Printable report to thicc-report.pdf
+  thicc report pdf --from 2024-01-01 --to 2024-06-30`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var reportHTMLCmd = &cobra.Command{
+	Use:   "html [number|date]",
+	Short: "Generate a self-contained HTML report",
+	Long: `Generates a single HTML file with summary statistics, goal progress, a chart,
+monthly summaries and every selected entry. Styles and the chart are embedded,
+so the file can be emailed or opened offline.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		writeReport(args, "thicc-report.html", func(w io.Writer, weights []models.Weight, settings *models.Settings) error {
+			return report.WriteHTML(w, weights, settings, time.Now())
+		})
+	},
+}
+
+var reportPDFCmd = &cobra.Command{
+	Use:   "pdf [number|date]",
+	Short: "Generate a printable PDF report",
+	Long: `Generates a PDF report to print or give to a doctor, with your height, units
+and goal, a chart, the rate of change, BMI category history, monthly averages
+and notable events. Use --page for A4 or Letter paper.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		size, err := pdf.PageSizeByName(reportPageSize)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		writeReport(args, "thicc-report.pdf", func(w io.Writer, weights []models.Weight, settings *models.Settings) error {
+			return report.WritePDF(w, weights, settings, size, time.Now())
+		})
+	},
+}
+
+func init() {
+	reportCmd.PersistentFlags().StringVarP(&reportOutput, "output", "o", "", "file to save the report to, or - for standard output")
+	reportCmd.PersistentFlags().StringVar(&reportRange.from, "from", "", "report on entries on or after this date")
+	reportCmd.PersistentFlags().StringVar(&reportRange.to, "to", "", "report on entries on or before this date")
+	registerIncludeTrash(reportCmd.PersistentFlags())
+	reportPDFCmd.Flags().StringVar(&reportPageSize, "page", pdf.A4.Name, "paper size: a4 or letter")
+	reportCmd.AddCommand(reportHTMLCmd)
+	reportCmd.AddCommand(reportPDFCmd)
+}
+
+// writeReport loads the entries selected by args and writes a report of them
+// with write to the --output file, or else to the default file
+func writeReport(args []string, defaultOutput string, write func(w io.Writer, weights []models.Weight, settings *models.Settings) error) {
+	db := GetDB()
+	settings := GetSettings()
+
+	weights, err := reportWeights(db, args)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(weights) == 0 {
+		fmt.Println("No weights to report on. Add one with: thicc add <weight> [date]")
+		return
+	}
+
+	output := reportOutput
+	if output == "" {
+		output = defaultOutput
+	}
+	err = writeOutput(output, func(w io.Writer) error {
+		return write(w, weights, settings)
+	})
+	if err != nil {
+		// Keep errors out of output written to standard output
+		fmt.Fprintf(errorOutput(output), "Error saving report: %v\n", err)
+		return
+	}
+	if output == "-" {
+		return
+	}
+
+	fmt.Printf("Saved report of %d entries to %s\n", len(weights), output)
+}
+
+// reportWeights loads the entries in the --from and --to date range, or else
+// the entries selected by args as for show
+func reportWeights(db *database.DB, args []string) ([]models.Weight, error) {
+	if !reportRange.hasDateRange() {
+		weights, _, err := loadWeights(db, args, includeTrash)
+		return weights, err
+	}
+	if len(args) > 0 {
+		return nil, errors.New("give either a number or date argument, or --from and --to, not both")
+	}
+	sel, err := reportRange.selection("")
+	if err != nil {
+		return nil, err
+	}
+	sel.IncludeTrash = includeTrash
+	weights, err := models.SelectWeights(db, sel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve weights: %w", err)
+	}
+	return weights, nil
+}
@@ -1,54 +1,55 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/tryonlinux/thicc/internal/models"
 )
 
+var resetPurge bool
+
 var resetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "Clear all data and start over",
-	Long:  `Deletes all weight entries and settings. You will be prompted to reconfigure on next launch. This action cannot be undone.`,
+	Long: `Deletes all weight entries, goals and settings. You will be prompted to reconfigure on next launch.
+The reset can be reverted with "thicc undo". Use --purge to also erase the undo history,
+which permanently destroys all data.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		db := GetDB()
 
 		// Get confirmation from user
-		fmt.Println("WARNING: This will delete ALL weight entries and settings.")
-		fmt.Print("Are you sure you want to continue? (yes/no): ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+		if resetPurge {
+			fmt.Println("WARNING: This will permanently delete ALL weight entries, settings and undo history.")
+			fmt.Println("This action cannot be undone.")
+		} else {
+			fmt.Println("WARNING: This will delete ALL weight entries and settings.")
+		}
+		confirmed, err := confirm("Are you sure you want to continue? (yes/no): ")
 		if err != nil {
 			fmt.Printf("Error reading input: %v\n", err)
 			return
 		}
-
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "yes" && response != "y" {
+		if !confirmed {
 			fmt.Println("Reset cancelled.")
 			return
 		}
 
-		// Delete all weights
-		_, err = db.Exec("DELETE FROM weights")
-		if err != nil {
-			fmt.Printf("Error deleting weights: %v\n", err)
-			return
-		}
-
-		// Reset settings
-		err = models.ResetSettings(db)
-		if err != nil {
-			fmt.Printf("Error resetting settings: %v\n", err)
+		// Everything is deleted and journaled together, so a reset is never
+		// kept without the copy that undoes it
+		if _, err := models.ResetData(db, resetPurge); err != nil {
+			fmt.Printf("Error resetting data: %v\n", err)
 			return
 		}
 
 		fmt.Println("\nAll weight entries and settings have been deleted.")
+		if !resetPurge {
+			fmt.Println("Run \"thicc undo\" to restore them.")
+		}
 		fmt.Println("You will be prompted to reconfigure on next launch.")
 	},
 }
+
+func init() {
+	resetCmd.Flags().BoolVar(&resetPurge, "purge", false, "permanently delete everything, including the undo history")
+}
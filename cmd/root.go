@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/tryonlinux/thicc/internal/config"
@@ -13,6 +15,10 @@ import (
 var db *database.DB
 var settings *models.Settings
 
+// skipSetupAnnotation marks commands that must run without first-launch setup,
+// such as undo, which may need to restore settings removed by reset
+const skipSetupAnnotation = "skipSetup"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "thicc",
@@ -24,12 +30,30 @@ var rootCmd = &cobra.Command{
 			showCmd.Run(cmd, args)
 		}
 	},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		initDatabase(cmd)
+	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		// Clean up database connection on exit
 		cleanupDatabase()
 	},
 }
 
+// dateOffsetArg matches date offsets such as -3d, which look like flags when
+// passed as arguments
+var dateOffsetArg = regexp.MustCompile(`^-\d+[dwmy]$`)
+
+// flagError explains how to pass a date offset such as -3d as an argument
+// when it was taken for a flag
+func flagError(cmd *cobra.Command, err error) error {
+	for _, word := range strings.Fields(err.Error()) {
+		if dateOffsetArg.MatchString(word) {
+			return fmt.Errorf("%w\nPut a date offset after -- (... -- %s), or write it as \"%s ago\"", err, word, word[1:])
+		}
+	}
+	return err
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -39,19 +63,33 @@ func Execute() {
 }
 
 func init() {
-	cobra.OnInitialize(initDatabase)
+	rootCmd.SetFlagErrorFunc(flagError)
+	rootCmd.PersistentFlags().BoolVar(&allowFuture, "allow-future", false, "allow entry dates after today")
 
 	// Add all subcommands
 	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(chartCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(summaryCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(calendarCmd)
+	rootCmd.AddCommand(achievementsCmd)
+	rootCmd.AddCommand(growthCmd)
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(modifyCmd)
+	rootCmd.AddCommand(editCmd)
 	rootCmd.AddCommand(goalCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(resetCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+	rootCmd.AddCommand(historyCmd)
 }
 
 // initDatabase initializes the database connection
-func initDatabase() {
+func initDatabase(cmd *cobra.Command) {
 	dbPath, err := config.GetDatabasePath()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting database path: %v\n", err)
@@ -72,7 +110,7 @@ func initDatabase() {
 	}
 
 	// First launch - prompt for setup
-	if settings == nil {
+	if settings == nil && cmd.Annotations[skipSetupAnnotation] == "" {
 		settings, err = models.SetupSettings(db)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error setting up: %v\n", err)
@@ -91,6 +129,14 @@ func GetSettings() *models.Settings {
 	return settings
 }
 
+// reloadSettings re-reads settings after they were changed outside of a command's
+// own update, e.g. by undo or redo. Settings are nil when none are stored.
+func reloadSettings() error {
+	var err error
+	settings, err = models.GetSettings(db)
+	return err
+}
+
 // cleanupDatabase closes the database connection
 func cleanupDatabase() {
 	if db != nil {
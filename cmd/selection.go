@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/tryonlinux/thicc/internal/database"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/validation"
+)
+
+// selectionFlags holds the entry selection flags shared by commands that
+// work on several entries at once
+type selectionFlags struct {
+	from string
+	to   string
+	tag  string
+	yes  bool
+}
+
+// includeTrash is set by the --include-trash flag of the commands that export entries
+var includeTrash bool
+
+// registerIncludeTrash adds the --include-trash flag to a command that exports entries
+func registerIncludeTrash(flags *pflag.FlagSet) {
+	flags.BoolVar(&includeTrash, "include-trash", false, "include entries in the trash")
+}
+
+// register adds the selection flags to a command
+func (f *selectionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.from, "from", "", "select entries on or after this date")
+	cmd.Flags().StringVar(&f.to, "to", "", "select entries on or before this date")
+	cmd.Flags().StringVar(&f.tag, "tag", "", "select entries whose note has this #tag")
+	cmd.Flags().BoolVarP(&f.yes, "yes", "y", false, "apply to several entries without asking for confirmation")
+}
+
+// hasDateRange reports whether --from or --to was given
+func (f *selectionFlags) hasDateRange() bool {
+	return f.from != "" || f.to != ""
+}
+
+// hasFilters reports whether --from, --to or --tag was given
+func (f *selectionFlags) hasFilters() bool {
+	return f.hasDateRange() || f.tag != ""
+}
+
+// selection builds a selection from an optional ID list argument and the filter flags
+func (f *selectionFlags) selection(idList string) (models.WeightSelection, error) {
+	var sel models.WeightSelection
+
+	if idList != "" {
+		ids, err := validation.ParseIDList(idList)
+		if err != nil {
+			return sel, err
+		}
+		sel.IDs = ids
+	}
+
+	var err error
+	if f.from != "" {
+		sel.From, err = parseFilterDate(f.from)
+		if err != nil {
+			return sel, fmt.Errorf("--from: %w", err)
+		}
+	}
+	if f.to != "" {
+		sel.To, err = parseFilterDate(f.to)
+		if err != nil {
+			return sel, fmt.Errorf("--to: %w", err)
+		}
+	}
+	if sel.From != "" && sel.To != "" && sel.From > sel.To {
+		return sel, fmt.Errorf("--from date must not be after --to date")
+	}
+	if f.tag != "" {
+		sel.Tag, err = validation.ParseTag(f.tag)
+		if err != nil {
+			return sel, fmt.Errorf("--tag: %w", err)
+		}
+	}
+
+	return sel, nil
+}
+
+// selectEntries retrieves the entries matching a selection and warns about
+// requested IDs that don't exist
+func selectEntries(db *database.DB, sel models.WeightSelection) ([]models.Weight, error) {
+	weights, err := models.SelectWeights(db, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sel.IDs) > 0 && !(sel.From != "" || sel.To != "" || sel.Tag != "") {
+		found := make(map[int]bool)
+		for _, w := range weights {
+			found[w.ID] = true
+		}
+		var missing []string
+		for _, id := range sel.IDs {
+			if !found[id] {
+				missing = append(missing, fmt.Sprintf("%d", id))
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Printf("Warning: No entries with ID %s\n", strings.Join(missing, ", "))
+		}
+	}
+
+	return weights, nil
+}
+
+// confirmEntries previews the entries a command will change and asks for
+// confirmation. A single entry, or --yes, needs no confirmation.
+func confirmEntries(weights []models.Weight, settings *models.Settings, action string, yes bool) bool {
+	if len(weights) <= 1 || yes {
+		return true
+	}
+
+	fmt.Printf("The following %d entries will be %s:\n", len(weights), action)
+	fmt.Println(display.RenderEntriesTable(weights, settings))
+
+	confirmed, err := confirm("Are you sure you want to continue? (yes/no): ")
+	if err != nil {
+		fmt.Printf("Error reading input: %v\n", err)
+		return false
+	}
+	if !confirmed {
+		fmt.Println("Cancelled.")
+	}
+	return confirmed
+}
+
+// confirm asks a yes/no question on stdin
+func confirm(prompt string) (bool, error) {
+	fmt.Print(prompt)
+
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "yes" || response == "y", nil
+}
+
+// weightIDs returns the IDs of the given entries
+func weightIDs(weights []models.Weight) []int {
+	ids := make([]int, len(weights))
+	for i, w := range weights {
+		ids[i] = w.ID
+	}
+	return ids
+}
@@ -1,14 +1,41 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/database"
 	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/graphics"
 	"github.com/tryonlinux/thicc/internal/models"
-	"github.com/tryonlinux/thicc/internal/validation"
+)
+
+var (
+	showWidth  int
+	showHeight int
+	showChart  string
+	showImage  string
+	showBands  bool
+)
+
+const (
+	// imageCellWidth and imageCellHeight are the assumed size of a terminal
+	// cell in pixels, used to pick the chart image's resolution
+	imageCellWidth  = 10
+	imageCellHeight = 20
+
+	// imageMinColumns and imageMaxColumns bound the width of the chart image in cells
+	imageMinColumns = 40
+	imageMaxColumns = 120
+
+	// imageMinRows and imageMaxRows bound the height of the chart image in cells
+	imageMinRows = 10
+	imageMaxRows = 24
 )
 
 var showCmd = &cobra.Command{
@@ -19,61 +46,166 @@ var showCmd = &cobra.Command{
 Examples:
   thicc show          # Show last 20 entries
   thicc show 50       # Show last 50 entries
-  thicc show 2024-01-01  # Show entries from 2024-01-01 to today (table shows last 20)`,
+  thicc show 2024-01-01  # Show entries from 2024-01-01 to today (table shows last 20)
+  thicc show "1 month ago"  # Show entries from a month ago to today
+  thicc show -- -2w   # Offsets starting with "-" go after --, or write "2w ago"
+
+The table and graph are sized to the terminal, with the graph below the table
+on narrow terminals. Use --width and --height to size output that isn't going
+to a terminal, e.g. when piping to a file.
+
+The graph is drawn with braille dots by default, or ASCII characters when the
+terminal's locale isn't UTF-8. Use --chart to pick braille, block or ascii.
+Use --bmi-bands to draw lines where the BMI categories start.
+
+In terminals that show images (Kitty, Ghostty, iTerm2, WezTerm, or ones
+with Sixel support such as foot), the graph is drawn as an image below the
+table. Use --image to pick the protocol, or --image none for the text graph.`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		db := GetDB()
 		settings := GetSettings()
 
-		var weights []models.Weight
-		var err error
-		limit := display.DefaultDisplayLimit
+		weights, limit, err := loadWeights(db, args, false)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
 
-		if len(args) == 0 {
-			// Default: show last entries
-			weights, err = models.GetWeights(db, display.DefaultDisplayLimit)
-		} else {
-			arg := strings.TrimSpace(args[0])
-
-			// Check if it's a number (limit) or date
-			if num, err := strconv.Atoi(arg); err == nil {
-				// It's a number
-				limit = num
-				if limit <= 0 {
-					fmt.Println("Error: Number must be positive")
-					return
-				}
-				weights, err = models.GetWeights(db, limit)
-				if err != nil {
-					fmt.Printf("Error retrieving weights: %v\n", err)
-					return
-				}
-			} else {
-				// Try to parse as a date
-				if err := validation.ValidateDate(arg); err == nil {
-					// It's a valid date
-					startDate := arg
-					endDate := models.GetTodayDate()
-					weights, err = models.GetWeightsBetweenDates(db, startDate, endDate)
-					if err != nil {
-						fmt.Printf("Error retrieving weights: %v\n", err)
-						return
-					}
-					// For graph, use all weights; for table display, it will be truncated in render
-				} else {
-					fmt.Println("Error: Argument must be a positive number or a date in YYYY-MM-DD format")
-					return
-				}
-			}
+		// Render table and graph
+		opts, err := outputOptions()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
 
+		protocol, err := imageProtocol()
 		if err != nil {
-			fmt.Printf("Error retrieving weights: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		// Render table and graph
-		output := display.RenderWeightsTable(weights, settings, limit)
+		// Draw the graph as an image below the table when the terminal can show one
+		if image, rows := chartImage(weights, settings, opts, protocol); image != "" {
+			opts.HideGraph = true
+			if opts.Height > 0 {
+				opts.Height = max(opts.Height-rows-1, 1)
+			}
+			fmt.Println(display.RenderWeightsTableOptions(weights, settings, limit, opts))
+			fmt.Println()
+			fmt.Println(image)
+			return
+		}
+
+		output := display.RenderWeightsTableOptions(weights, settings, limit, opts)
 		fmt.Println(output)
 	},
 }
+
+func init() {
+	showCmd.Flags().IntVar(&showWidth, "width", 0, "output width in columns (default: terminal width)")
+	showCmd.Flags().IntVar(&showHeight, "height", 0, "output height in lines (default: terminal height)")
+	showCmd.Flags().StringVar(&showChart, "chart", "", "graph style: "+strings.Join(display.ChartNames, ", ")+" (default: braille, or ascii without UTF-8)")
+	showCmd.Flags().BoolVar(&showBands, "bmi-bands", false, "draw lines on the graph where the BMI categories start")
+	showCmd.Flags().StringVar(&showImage, "image", "auto", "draw the graph as an image: "+strings.Join(graphics.ProtocolNames, ", "))
+}
+
+// loadWeights returns the entries selected by the optional argument of show
+// and chart: the last number of entries, or the entries since a date. Entries
+// in the trash are left out unless includeTrash is set. It also returns how
+// many entries to list in a table.
+func loadWeights(db *database.DB, args []string, includeTrash bool) ([]models.Weight, int, error) {
+	sel := models.WeightSelection{Limit: display.DefaultDisplayLimit, IncludeTrash: includeTrash}
+	limit := display.DefaultDisplayLimit
+
+	if len(args) > 0 {
+		arg := strings.TrimSpace(args[0])
+
+		// Check if it's a number (limit) or date
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n <= 0 {
+				return nil, 0, errors.New("number must be positive")
+			}
+			sel.Limit, limit = n, n
+		} else {
+			startDate, err := parseFilterDate(arg)
+			if err != nil {
+				return nil, 0, errors.New("argument must be a positive number or a date, e.g. 2024-01-01 or \"2 weeks ago\"")
+			}
+			// For the graph, use all weights; the table is truncated when rendered
+			sel.Limit, sel.From, sel.To = 0, startDate, models.GetTodayDate()
+		}
+	}
+
+	weights, err := models.SelectWeights(db, sel)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve weights: %w", err)
+	}
+	return weights, limit, nil
+}
+
+// outputOptions returns how to render the table and graph. The space to render
+// in is given by the --width and --height flags, or else the terminal size.
+// Output that isn't going to a terminal uses the default fixed sizes unless
+// the flags are given. The chart style is given by --chart, or else picked
+// by whether the terminal renders Unicode, and BMI category lines by --bmi-bands.
+func outputOptions() (display.Options, error) {
+	opts := display.Options{Width: showWidth, Height: showHeight, Chart: display.DefaultChartRenderer(), BMICutoffs: showBands}
+	if showChart != "" {
+		renderer, err := display.ChartRendererByName(showChart)
+		if err != nil {
+			return opts, err
+		}
+		opts.Chart = renderer
+	}
+
+	if fd := os.Stdout.Fd(); term.IsTerminal(fd) {
+		if width, height, err := term.GetSize(fd); err == nil {
+			if opts.Width <= 0 {
+				opts.Width = width
+			}
+			if opts.Height <= 0 {
+				opts.Height = height
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// imageProtocol returns the graphics protocol given by --image. With auto,
+// images are only drawn when the output is a terminal that shows them.
+func imageProtocol() (graphics.Protocol, error) {
+	if showImage == "auto" && !term.IsTerminal(os.Stdout.Fd()) {
+		return graphics.ProtocolNone, nil
+	}
+	return graphics.ParseProtocol(showImage)
+}
+
+// chartImage returns the escape sequence that shows the graph as an image
+// using the protocol, and the number of rows it takes. It returns an empty
+// string when the graph should be drawn as text instead.
+func chartImage(weights []models.Weight, settings *models.Settings, opts display.Options, protocol graphics.Protocol) (string, int) {
+	if len(weights) == 0 || protocol == graphics.ProtocolNone {
+		return "", 0
+	}
+
+	columns, rows := display.GraphWidth*2, display.GraphHeight
+	if opts.Width > 0 {
+		columns = min(max(opts.Width, imageMinColumns), imageMaxColumns)
+		rows = columns / 4
+	}
+	if opts.Height > 0 {
+		// Leave most of the terminal to the table
+		rows = min(rows, opts.Height/3)
+	}
+	rows = min(max(rows, imageMinRows), imageMaxRows)
+
+	data := display.PrepareChartFor(weights, settings)
+	img := graphics.RenderChart(data, settings, columns*imageCellWidth, rows*imageCellHeight)
+	image, err := graphics.Encode(img, protocol, columns, rows)
+	if err != nil {
+		return "", 0
+	}
+	return image, rows
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+var statsRange selectionFlags
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics of your weights",
+	Long: `Shows how your weights are spread and how much they vary: the median,
+standard deviation and percentiles, the average fluctuation and largest gain
+and loss from one day to the next, the longest losing and gaining streaks, the
+average on each day of the week and a histogram of weights.
+
+Day to day figures use the last entry of each day. Fluctuation and the largest
+gain and loss only compare days with entries one day apart, while streaks run
+over days with entries whatever the gap between them.
+
+All entries are included unless --from or --to is given.
+
+Examples:
+  thicc stats                        # Statistics of all entries
+  thicc stats --from "3 months ago"
+  thicc stats --from 2024-01-01 --to 2024-06-30`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		sel, err := statsRange.selection("")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		weights, err := models.SelectWeights(db, sel)
+		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+
+		opts, err := outputOptions()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Println(display.RenderStats(analytics.Statistics(weights), settings, opts))
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsRange.from, "from", "", "include entries on or after this date")
+	statsCmd.Flags().StringVar(&statsRange.to, "to", "", "include entries on or before this date")
+	statsCmd.Flags().IntVar(&showWidth, "width", 0, "output width in columns (default: terminal width)")
+}
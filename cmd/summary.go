@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/report"
+)
+
+// summaryFormats lists the output formats of the summary command, the default first
+var summaryFormats = []string{"table", "json", "csv"}
+
+var (
+	summaryBy     string
+	summaryFormat string
+	summaryRange  selectionFlags
+)
+
+var summaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize weights by week, month or year",
+	Long: `Summarizes weight entries by calendar week, month or year: the number of
+entries, the average, lowest and highest weights, the change from the first
+to the last entry and the rate of change per week. Weeks start on Monday.
+
+Below the table, a bar chart shows how the average changed from one period to
+the next, in green when moving towards your goal and red when moving away.
+
+All entries are summarized unless --from or --to is given. Use --format json
+or csv for output to use in other tools, and --include-trash to include
+entries in the trash.
+
+Examples:
+  thicc summary                          # Monthly summary of all entries
+  thicc summary --by week --from "3 months ago"
+  thicc summary --by year --format csv > years.csv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		period, err := analytics.ParsePeriod(summaryBy)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		format := strings.ToLower(summaryFormat)
+		if !slices.Contains(summaryFormats, format) {
+			fmt.Printf("Error: unknown format %q: use %s\n", summaryFormat, strings.Join(summaryFormats, ", "))
+			return
+		}
+
+		sel, err := summaryRange.selection("")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		sel.IncludeTrash = includeTrash
+		weights, err := models.SelectWeights(db, sel)
+		if err != nil {
+			fmt.Printf("Error retrieving weights: %v\n", err)
+			return
+		}
+		periods := analytics.Summaries(weights, period)
+
+		switch format {
+		case "json":
+			err = report.WriteSummaryJSON(os.Stdout, report.SummaryRecords(periods, period, settings))
+		case "csv":
+			err = report.WriteSummaryCSV(os.Stdout, report.SummaryRecords(periods, period, settings))
+		default:
+			opts, optsErr := outputOptions()
+			if optsErr != nil {
+				fmt.Printf("Error: %v\n", optsErr)
+				return
+			}
+			fmt.Println(display.RenderSummary(periods, period, settings, opts))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing summary: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	summaryCmd.Flags().StringVar(&summaryBy, "by", string(analytics.Month), "period to group entries by: week, month or year")
+	summaryCmd.Flags().StringVar(&summaryFormat, "format", summaryFormats[0], "output format: "+strings.Join(summaryFormats, ", "))
+	summaryCmd.Flags().StringVar(&summaryRange.from, "from", "", "summarize entries on or after this date")
+	summaryCmd.Flags().StringVar(&summaryRange.to, "to", "", "summarize entries on or before this date")
+	registerIncludeTrash(summaryCmd.Flags())
+	summaryCmd.Flags().IntVar(&showWidth, "width", 0, "output width in columns (default: terminal width)")
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/validation"
+)
+
+var trashOlderThan string
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted weight entries",
+	Long: `Deleted weight entries are moved to the trash, where they can be restored or removed for good.
+
+Examples:
+  thicc trash list                    # Show entries in the trash
+  thicc trash restore 5               # Move entry 5 back out of the trash
+  thicc trash empty                   # Permanently remove everything in the trash
+  thicc trash empty --older-than 30d  # Only remove entries deleted more than 30 days ago`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		trashListCmd.Run(cmd, args)
+	},
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List entries in the trash",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+		settings := GetSettings()
+
+		weights, err := models.GetTrashedWeights(db)
+		if err != nil {
+			fmt.Printf("Error retrieving trash: %v\n", err)
+			return
+		}
+
+		fmt.Println(display.RenderTrashTable(weights, settings))
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <weightId>",
+	Short: "Restore an entry from the trash",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+
+		// Parse weight ID
+		id, err := strconv.Atoi(strings.TrimSpace(args[0]))
+		if err != nil || id <= 0 {
+			fmt.Println("Error: Weight ID must be a positive number")
+			return
+		}
+
+		if err := models.RestoreWeight(db, id); err != nil {
+			fmt.Printf("Error restoring weight: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Restored weight entry with ID %d\n", id)
+		if err := syncAchievements(db, GetSettings()); err != nil {
+			fmt.Printf("Warning: could not update achievements: %v\n", err)
+		}
+
+		// Show updated table
+		showCmd.Run(cmd, []string{})
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently remove entries from the trash",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+
+		var cutoff time.Time
+		if trashOlderThan != "" {
+			days, err := validation.ParseDays(trashOlderThan)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			cutoff = time.Now().AddDate(0, 0, -days)
+		}
+
+		trashed, err := models.GetTrashedWeightsBefore(db, cutoff)
+		if err != nil {
+			fmt.Printf("Error retrieving trash: %v\n", err)
+			return
+		}
+		if len(trashed) == 0 {
+			fmt.Println("Nothing to remove from the trash.")
+			return
+		}
+
+		if err := models.PurgeWeights(db, weightIDs(trashed)...); err != nil {
+			fmt.Printf("Error emptying trash: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Permanently removed %d entries from the trash.\n", len(trashed))
+	},
+}
+
+func init() {
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "only remove entries deleted longer ago than this, e.g. 30d or 4w")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+}
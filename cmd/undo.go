@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+var undoCmd = &cobra.Command{
+	Use:         "undo",
+	Short:       "Undo the last change",
+	Long:        `Reverts the most recent change made by add, modify, edit, delete, trash, goal, config or reset. See "thicc history" for the list of changes.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{skipSetupAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+
+		op, err := models.UndoOperation(db)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Undid #%d: %s\n", op.ID, op.Summary)
+		showAfterJournalChange(cmd)
+	},
+}
+
+var redoCmd = &cobra.Command{
+	Use:         "redo",
+	Short:       "Redo the last undone change",
+	Long:        `Reapplies the change most recently reverted by undo. Making any new change discards the changes that can be redone.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{skipSetupAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		db := GetDB()
+
+		op, err := models.RedoOperation(db)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Redid #%d: %s\n", op.ID, op.Summary)
+		showAfterJournalChange(cmd)
+	},
+}
+
+// showAfterJournalChange reloads settings changed by undo or redo, updates
+// achievements and shows the updated table
+func showAfterJournalChange(cmd *cobra.Command) {
+	if err := reloadSettings(); err != nil {
+		fmt.Printf("Error getting settings: %v\n", err)
+		return
+	}
+
+	// Achievements aren't journaled, so they're updated from the entries undo
+	// or redo brought back or removed
+	if err := syncAchievements(GetDB(), GetSettings()); err != nil {
+		fmt.Printf("Warning: could not update achievements: %v\n", err)
+	}
+
+	// Redoing a reset removes the settings again
+	if GetSettings() == nil {
+		fmt.Println("No settings stored. You will be prompted to reconfigure on next launch.")
+		return
+	}
+
+	showCmd.Run(cmd, []string{})
+}
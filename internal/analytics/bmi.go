@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"math"
+
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// BMIPeriod is a run of consecutive entries in the same BMI category
+type BMIPeriod struct {
+	Category calculator.BMICategory
+	From     string // date of the first entry
+	To       string // date of the last entry
+	Entries  int
+	MinBMI   float64
+	MaxBMI   float64
+}
+
+// BMIHistory splits weights, stored newest first, into runs in the same WHO
+// BMI category, oldest first
+func BMIHistory(weights []models.Weight) []BMIPeriod {
+	return BMIHistoryFor(weights, calculator.BMIStandardFor(calculator.WHOStandard))
+}
+
+// BMIHistoryFor splits weights, stored newest first, into runs in the same
+// category of a BMI standard, oldest first
+func BMIHistoryFor(weights []models.Weight, standard calculator.BMIStandard) []BMIPeriod {
+	var periods []BMIPeriod
+	for i := len(weights) - 1; i >= 0; i-- {
+		w := weights[i]
+		category := standard.Category(w.BMI)
+		if n := len(periods); n > 0 && periods[n-1].Category == category {
+			p := &periods[n-1]
+			p.To = w.Date
+			p.Entries++
+			p.MinBMI = math.Min(p.MinBMI, w.BMI)
+			p.MaxBMI = math.Max(p.MaxBMI, w.BMI)
+			continue
+		}
+		periods = append(periods, BMIPeriod{Category: category, From: w.Date, To: w.Date, Entries: 1, MinBMI: w.BMI, MaxBMI: w.BMI})
+	}
+	return periods
+}
+
+// BMIForAge compares a child's BMI with the growth reference for their age and sex
+type BMIForAge struct {
+	AgeMonths  float64
+	ZScore     float64
+	Percentile float64 // 0 to 100
+	Category   calculator.BMICategory
+	Known      bool // the growth reference covers the age and sex; otherwise only AgeMonths is set
+}
+
+// ChildBMI compares a BMI on a YYYY-MM-DD date with the growth reference when
+// the person was under 20 then. It reports false for adults and when the birth
+// date isn't set, so the adult categories apply.
+func ChildBMI(bmi float64, date string, settings *models.Settings) (BMIForAge, bool) {
+	age, ok := settings.AgeMonths(date)
+	if !ok || age >= calculator.PediatricMaxAgeMonths {
+		return BMIForAge{}, false
+	}
+
+	child := BMIForAge{AgeMonths: age}
+	lms, ok := settings.Growth.At(settings.Sex, age)
+	if !ok || age < calculator.PediatricMinAgeMonths {
+		return child, true
+	}
+	child.ZScore = lms.ZScore(bmi)
+	child.Percentile = calculator.Percentile(child.ZScore)
+	child.Category = calculator.CategoryForPercentile(child.Percentile)
+	child.Known = true
+	return child, true
+}
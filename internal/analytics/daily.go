@@ -0,0 +1,109 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// Day is a day with weight entries: the last weight entered that day and its
+// change from the last weight of the previous day with entries
+type Day struct {
+	Date   string
+	Weight float64
+	Change float64
+	First  bool // there's no earlier day to compare with
+}
+
+// Days returns the days with entries in weights, stored newest first, oldest
+// day first
+func Days(weights []models.Weight) []Day {
+	var days []Day
+	for i := len(weights) - 1; i >= 0; i-- {
+		w := weights[i]
+		n := len(days)
+		if n > 0 && days[n-1].Date == w.Date {
+			// Later entries on the same day replace earlier ones
+			days[n-1].Weight = w.Weight
+			if n > 1 {
+				days[n-1].Change = w.Weight - days[n-2].Weight
+			}
+			continue
+		}
+
+		day := Day{Date: w.Date, Weight: w.Weight, First: n == 0}
+		if n > 0 {
+			day.Change = w.Weight - days[n-1].Weight
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// Streaks returns the number of consecutive days with entries up to today,
+// and the most consecutive days with entries. Days are oldest first. The
+// current streak isn't broken until a whole day passes without an entry, so
+// it counts up to yesterday when there's no entry yet today.
+func Streaks(days []Day, today string) (current, longest int) {
+	run := 0
+	var last time.Time
+	for _, d := range days {
+		t, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		run = nextRun(run, last, t, 1)
+		last = t
+		longest = max(longest, run)
+	}
+
+	end, err := time.Parse("2006-01-02", today)
+	if err == nil && run > 0 && !last.After(end) && !last.Before(end.AddDate(0, 0, -1)) {
+		current = run
+	}
+	return current, longest
+}
+
+// WeekStreaks returns the number of consecutive weeks with entries up to this
+// week, and the most consecutive weeks with entries. Days are oldest first.
+// Like Streaks, the current streak counts up to last week while there's no
+// entry yet this week.
+func WeekStreaks(days []Day, today string) (current, longest int) {
+	run := 0
+	var last time.Time
+	for _, d := range days {
+		t, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		week := WeekStart(t)
+		run = nextRun(run, last, week, 7)
+		last = week
+		longest = max(longest, run)
+	}
+
+	end, err := time.Parse("2006-01-02", today)
+	if err == nil && run > 0 {
+		thisWeek := WeekStart(end)
+		if !last.After(thisWeek) && !last.Before(thisWeek.AddDate(0, 0, -7)) {
+			current = run
+		}
+	}
+	return current, longest
+}
+
+// DaysInRange returns the number of days with entries on or after a
+// YYYY-MM-DD date, and how many of them ended within a weight range. Days are
+// oldest first.
+func DaysInRange(days []Day, since string, low, high float64) (in, total int) {
+	for _, d := range days {
+		if d.Date < since {
+			continue
+		}
+		total++
+		if d.Weight >= low && d.Weight <= high {
+			in++
+		}
+	}
+	return in, total
+}
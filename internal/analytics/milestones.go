@@ -0,0 +1,209 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// MilestoneKind is a kind of milestone
+type MilestoneKind int
+
+// Milestone kinds
+const (
+	MilestoneLost       MilestoneKind = iota // lost a multiple of the milestone step
+	MilestoneGained                          // gained a multiple of the step, when the goal is to gain
+	MilestoneBMI                             // moved into a BMI category closer to Normal
+	MilestoneHalfway                         // halfway from the starting weight to the goal
+	MilestoneGoal                            // reached the goal weight
+	MilestoneDayStreak                       // entries on consecutive days
+	MilestoneWeekStreak                      // entries in consecutive weeks
+)
+
+// Milestone is a milestone reached by an entry
+type Milestone struct {
+	Key      string // identifies the milestone, so it's only recorded once
+	Kind     MilestoneKind
+	Amount   float64 // the weight lost or gained, the goal weight, or the streak length
+	Category string  // the BMI category reached
+	Date     string  // date of the entry that reached it
+	WeightID int     // the entry that reached it
+}
+
+// Streak lengths that are milestones
+var (
+	DayStreakMilestones  = []int{7, 30, 100, 365}
+	WeekStreakMilestones = []int{4, 12, 26, 52}
+)
+
+// MilestoneStep returns the weight lost or gained between milestones in a
+// weight unit: 5 lb, or 2 kg
+func MilestoneStep(unit string) float64 {
+	switch unit {
+	case "kg":
+		return 2
+	case "st":
+		return 5 / calculator.LbsPerSt
+	}
+	return 5
+}
+
+// Milestones returns the milestones reached by weights, stored newest first,
+// in date order, each with the first entry that reached it. Losses and gains
+// are measured from the oldest entry, in the direction of the goal.
+func Milestones(weights []models.Weight, settings *models.Settings) []Milestone {
+	if len(weights) == 0 {
+		return nil
+	}
+
+	oldest := weights[len(weights)-1]
+	start := oldest.Weight
+	goal := settings.GoalFrom(start)
+	losing := start >= goal
+	step := MilestoneStep(settings.WeightUnit)
+	goalKey := strconv.FormatFloat(goal, 'f', -1, 64)
+
+	var milestones []Milestone
+	reached := func(w models.Weight, m Milestone) {
+		m.Date, m.WeightID = w.Date, w.ID
+		milestones = append(milestones, m)
+	}
+
+	steps := 0
+	standard := settings.BMICutoffs()
+	category := standard.Category(oldest.BMI)
+	halfway, atGoal := false, false
+	var dayRun, weekRun int
+	var lastDay, lastWeek time.Time
+
+	for i := len(weights) - 1; i >= 0; i-- {
+		w := weights[i]
+
+		// Weight lost or gained towards the goal, in whole steps. A small
+		// tolerance keeps e.g. 9.999999 from missing the 10 lb milestone.
+		progress := start - w.Weight
+		kind := MilestoneLost
+		if !losing {
+			progress, kind = -progress, MilestoneGained
+		}
+		for n := int(math.Floor(progress/step + 1e-9)); steps < n; {
+			steps++
+			key := fmt.Sprintf("lost-%d", steps)
+			if kind == MilestoneGained {
+				key = fmt.Sprintf("gained-%d", steps)
+			}
+			reached(w, Milestone{Key: key, Kind: kind, Amount: float64(steps) * step})
+		}
+
+		if c := standard.Category(w.BMI); c != category {
+			if standard.Distance(c) < standard.Distance(category) {
+				reached(w, Milestone{Key: "bmi-" + c.Name, Kind: MilestoneBMI, Category: c.Name})
+			}
+			category = c
+		}
+
+		if start != goal && i < len(weights)-1 {
+			if !halfway && GoalProgress(start, w.Weight, goal) >= 0.5 {
+				halfway = true
+				reached(w, Milestone{Key: "halfway-" + goalKey, Kind: MilestoneHalfway, Amount: goal})
+			}
+			if !atGoal && GoalProgress(start, w.Weight, goal) >= 1 {
+				atGoal = true
+				reached(w, Milestone{Key: "goal-" + goalKey, Kind: MilestoneGoal, Amount: goal})
+			}
+		}
+
+		t, err := time.Parse("2006-01-02", w.Date)
+		if err != nil {
+			continue
+		}
+		dayRun = nextRun(dayRun, lastDay, t, 1)
+		lastDay = t
+		for _, n := range DayStreakMilestones {
+			if dayRun == n {
+				reached(w, Milestone{Key: fmt.Sprintf("streak-days-%d", n), Kind: MilestoneDayStreak, Amount: float64(n)})
+			}
+		}
+		week := WeekStart(t)
+		weekRun = nextRun(weekRun, lastWeek, week, 7)
+		lastWeek = week
+		for _, n := range WeekStreakMilestones {
+			if weekRun == n {
+				reached(w, Milestone{Key: fmt.Sprintf("streak-weeks-%d", n), Kind: MilestoneWeekStreak, Amount: float64(n)})
+			}
+		}
+	}
+
+	return firstReached(milestones)
+}
+
+// StaleAchievements returns the recorded achievements the entries no longer
+// reach, or reach with a different entry, such as after an entry was changed
+// so it no longer counts. Halfway and goal milestones of other goals than the
+// current one can't be judged again, so they are kept.
+func StaleAchievements(achievements []models.Achievement, weights []models.Weight, settings *models.Settings) []models.Achievement {
+	reached := make(map[string]int)
+	for _, m := range Milestones(weights, settings) {
+		reached[m.Key] = m.WeightID
+	}
+
+	var goalKey string
+	if len(weights) > 0 {
+		goalKey = strconv.FormatFloat(settings.GoalFrom(weights[len(weights)-1].Weight), 'f', -1, 64)
+	}
+
+	var stale []models.Achievement
+	for _, a := range achievements {
+		if id, ok := reached[a.Key]; ok && id == a.WeightID {
+			continue
+		}
+		if otherGoal(a.Key, goalKey) {
+			continue
+		}
+		stale = append(stale, a)
+	}
+	return stale
+}
+
+// otherGoal reports whether a milestone key is a halfway or goal milestone
+// of a goal other than the one with goalKey
+func otherGoal(key, goalKey string) bool {
+	for _, prefix := range []string{"halfway-", "goal-"} {
+		if goal, ok := strings.CutPrefix(key, prefix); ok {
+			return goal != goalKey
+		}
+	}
+	return false
+}
+
+// nextRun returns the length of a run of consecutive days or weeks after the
+// day or week t, given the run up to the previous one, last. Entries in the
+// same day or week keep the run as it is.
+func nextRun(run int, last, t time.Time, days int) int {
+	switch {
+	case run > 0 && t.Equal(last):
+		return run
+	case run > 0 && t.Equal(last.AddDate(0, 0, days)):
+		return run + 1
+	}
+	return 1
+}
+
+// firstReached keeps the first of the milestones with the same key, such as a
+// streak reached again after it was broken
+func firstReached(milestones []Milestone) []Milestone {
+	seen := make(map[string]bool)
+	var first []Milestone
+	for _, m := range milestones {
+		if !seen[m.Key] {
+			seen[m.Key] = true
+			first = append(first, m)
+		}
+	}
+	return first
+}
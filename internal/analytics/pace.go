@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"math"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+const (
+	// SafeWeeklyChange is the fastest change per week, as a fraction of body
+	// weight, that is considered safe to aim for
+	SafeWeeklyChange = 0.01
+
+	// PaceTrendDays is the number of days of entries the current trend is measured over
+	PaceTrendDays = 28
+)
+
+// GoalPace compares the pace needed to reach the goal by its deadline with
+// the current trend
+type GoalPace struct {
+	Deadline    string  // YYYY-MM-DD; empty when the goal has none
+	WeeksLeft   float64 // weeks from today to the deadline; zero or less once it has passed
+	Required    float64 // change per week needed to reach the goal by the deadline; negative to lose
+	Trend       float64 // change per week over the last PaceTrendDays of entries
+	OnTrack     bool    // the goal is reached, or the trend reaches it by the deadline
+	TooFast     bool    // the required change is more than SafeWeeklyChange of the latest weight
+	GoalBMI     float64 // BMI at the lowest goal weight; zero when the height isn't known
+	Underweight bool    // the goal weight is below a healthy BMI
+}
+
+// Pace works out the pace to reach the goal weight by a deadline from the
+// weights, stored newest first. Today and the deadline are YYYY-MM-DD. Without
+// a deadline only the trend and the goal's BMI are filled in. For a goal range
+// the pace is to its nearest end.
+func Pace(weights []models.Weight, settings *models.Settings, deadline, today string) (GoalPace, error) {
+	pace := GoalPace{Deadline: deadline}
+	if deadline != "" {
+		end, err := time.Parse("2006-01-02", deadline)
+		if err != nil {
+			return pace, err
+		}
+		start, err := time.Parse("2006-01-02", today)
+		if err != nil {
+			return pace, err
+		}
+		pace.WeeksLeft = end.Sub(start).Hours() / 24 / 7
+	}
+
+	// The BMI at the goal weight, or at the bottom of the goal range
+	if settings.Height > 0 {
+		pace.GoalBMI = calculator.CalculateBMI(settings.GoalFrom(0), settings.Height, settings.WeightUnit, settings.HeightUnit)
+		pace.Underweight = pace.GoalBMI < calculator.HealthyBMIMin
+	}
+	if len(weights) == 0 {
+		return pace, nil
+	}
+
+	latest := weights[0].Weight
+	goal := settings.GoalFrom(latest)
+	pace.Trend = RatePerWeek(Recent(weights, PaceTrendDays))
+	if latest == goal {
+		pace.OnTrack = true
+		return pace, nil
+	}
+	if pace.WeeksLeft > 0 {
+		pace.Required = (goal - latest) / pace.WeeksLeft
+		pace.TooFast = math.Abs(pace.Required) > latest*SafeWeeklyChange
+		projected := latest + pace.Trend*pace.WeeksLeft
+		pace.OnTrack = (goal-latest)*(goal-projected) <= 0
+	}
+	return pace, nil
+}
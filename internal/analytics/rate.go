@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// RatePerWeek returns how fast weights, stored newest first, change per week:
+// the slope of the least-squares line through them, so a single unusual
+// weigh-in at either end doesn't skew it. It returns 0 when the entries don't
+// span at least two days.
+func RatePerWeek(weights []models.Weight) float64 {
+	var days, values []float64
+	var first time.Time
+	for i := len(weights) - 1; i >= 0; i-- {
+		t, err := time.Parse("2006-01-02", weights[i].Date)
+		if err != nil {
+			continue
+		}
+		if len(days) == 0 {
+			first = t
+		}
+		days = append(days, t.Sub(first).Hours()/24)
+		values = append(values, weights[i].Weight)
+	}
+	if len(days) < 2 || days[len(days)-1] == 0 {
+		return 0
+	}
+
+	var meanDay, meanValue float64
+	for i := range days {
+		meanDay += days[i]
+		meanValue += values[i]
+	}
+	meanDay /= float64(len(days))
+	meanValue /= float64(len(days))
+
+	var covariance, variance float64
+	for i := range days {
+		covariance += (days[i] - meanDay) * (values[i] - meanValue)
+		variance += (days[i] - meanDay) * (days[i] - meanDay)
+	}
+	return covariance / variance * 7
+}
+
+// Recent returns the weights, stored newest first, within the given number of
+// days before the newest one
+func Recent(weights []models.Weight, days int) []models.Weight {
+	if len(weights) == 0 {
+		return nil
+	}
+	newest, err := time.Parse("2006-01-02", weights[0].Date)
+	if err != nil {
+		return weights
+	}
+	cutoff := newest.AddDate(0, 0, -days).Format("2006-01-02")
+	for i, w := range weights {
+		if w.Date < cutoff {
+			return weights[:i]
+		}
+	}
+	return weights
+}
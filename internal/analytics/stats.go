@@ -0,0 +1,231 @@
+package analytics
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// HistogramBins is the number of bins weights are counted into
+const HistogramBins = 8
+
+// StatsPercentiles are the percentiles of the weights that statistics report
+var StatsPercentiles = []float64{10, 25, 75, 90}
+
+// Stats holds the distribution and variability of a series of weight entries
+type Stats struct {
+	Summary
+	Median      float64
+	StdDev      float64   // sample standard deviation of the weights
+	Percentiles []float64 // the weights at StatsPercentiles
+
+	// DailyFluctuation is the average size of the change from one day to the
+	// next, over days with entries on consecutive days. It is zero without any.
+	DailyFluctuation float64
+	LargestGain      DayChange // largest gain from one day to the next; zero without one
+	LargestLoss      DayChange // largest loss from one day to the next; zero without one
+
+	LosingStreak  Streak // longest run of days with entries, each lower than the one before
+	GainingStreak Streak // longest run of days with entries, each higher than the one before
+
+	Weekdays  []WeekdayAverage // Monday first, only weekdays with entries
+	Histogram []Bin
+}
+
+// DayChange is the change in weight to a day from the day before
+type DayChange struct {
+	Date   string
+	Change float64
+}
+
+// Streak is a run of days with entries, each changing the same way from the
+// day with entries before it. Days counts the changes, so it's zero without a run.
+type Streak struct {
+	Days   int
+	From   string // the day before the first change
+	To     string // the day of the last change
+	Change float64
+}
+
+// WeekdayAverage is the average of the days with entries on one weekday
+type WeekdayAverage struct {
+	Weekday time.Weekday
+	Days    int
+	Mean    float64
+}
+
+// Bin is a range of weights in a histogram, from Low up to High, with the
+// number of entries in it. The last bin includes its High.
+type Bin struct {
+	Low   float64
+	High  float64
+	Count int
+}
+
+// Statistics computes the distribution and variability of weights, stored
+// newest first. Day to day figures use the last weight of each day.
+func Statistics(weights []models.Weight) Stats {
+	if len(weights) == 0 {
+		return Stats{}
+	}
+
+	values := make([]float64, len(weights))
+	for i, w := range weights {
+		values[i] = w.Weight
+	}
+	slices.Sort(values)
+
+	s := Stats{Summary: Summarize(weights), Median: Quantile(values, 50)}
+	for _, p := range StatsPercentiles {
+		s.Percentiles = append(s.Percentiles, Quantile(values, p))
+	}
+	s.StdDev = StdDev(values)
+
+	days := Days(weights)
+	s.DailyFluctuation, s.LargestGain, s.LargestLoss = dayToDay(days)
+	s.LosingStreak, s.GainingStreak = changeStreaks(days)
+	s.Weekdays = weekdayAverages(days)
+	s.Histogram = Histogram(values, HistogramBins)
+	return s
+}
+
+// Quantile returns the value at a percentile, from 0 to 100, of sorted
+// values, interpolating between the two nearest values
+func Quantile(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := percentile / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := min(lo+1, len(sorted)-1)
+	return sorted[lo] + (rank-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// StdDev returns the sample standard deviation of values, zero for fewer than two
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(squares / float64(len(values)-1))
+}
+
+// Histogram counts sorted values into bins of equal width from the lowest
+// value to the highest. Equal values all go into a single bin.
+func Histogram(sorted []float64, bins int) []Bin {
+	if len(sorted) == 0 || bins < 1 {
+		return nil
+	}
+	low, high := sorted[0], sorted[len(sorted)-1]
+	if low == high {
+		return []Bin{{Low: low, High: high, Count: len(sorted)}}
+	}
+
+	width := (high - low) / float64(bins)
+	histogram := make([]Bin, bins)
+	for i := range histogram {
+		histogram[i] = Bin{Low: low + float64(i)*width, High: low + float64(i+1)*width}
+	}
+	histogram[bins-1].High = high
+	for _, v := range sorted {
+		i := min(int((v-low)/width), bins-1)
+		histogram[i].Count++
+	}
+	return histogram
+}
+
+// dayToDay returns the average size of the changes between consecutive days,
+// and the largest gain and loss among them
+func dayToDay(days []Day) (fluctuation float64, gain, loss DayChange) {
+	var total float64
+	var count int
+	for i := 1; i < len(days); i++ {
+		if !consecutive(days[i-1].Date, days[i].Date) {
+			continue
+		}
+		change := days[i].Change
+		total += math.Abs(change)
+		count++
+		if change > gain.Change {
+			gain = DayChange{Date: days[i].Date, Change: change}
+		}
+		if change < loss.Change {
+			loss = DayChange{Date: days[i].Date, Change: change}
+		}
+	}
+	if count > 0 {
+		fluctuation = total / float64(count)
+	}
+	return fluctuation, gain, loss
+}
+
+// consecutive reports whether the second YYYY-MM-DD date is the day after the first
+func consecutive(first, second string) bool {
+	a, errA := time.Parse("2006-01-02", first)
+	b, errB := time.Parse("2006-01-02", second)
+	return errA == nil && errB == nil && b.Equal(a.AddDate(0, 0, 1))
+}
+
+// changeStreaks returns the longest runs of losses and of gains from one day
+// with entries to the next. Days without a change break both.
+func changeStreaks(days []Day) (losing, gaining Streak) {
+	var run Streak
+	for i := 1; i < len(days); i++ {
+		change := days[i].Change
+		sameWay := run.Days > 0 && (change < 0) == (run.Change < 0)
+		switch {
+		case change == 0:
+			run = Streak{}
+			continue
+		case sameWay:
+			run.Days++
+			run.To = days[i].Date
+			run.Change += change
+		default:
+			run = Streak{Days: 1, From: days[i-1].Date, To: days[i].Date, Change: change}
+		}
+
+		if change < 0 && run.Days > losing.Days {
+			losing = run
+		}
+		if change > 0 && run.Days > gaining.Days {
+			gaining = run
+		}
+	}
+	return losing, gaining
+}
+
+// weekdayAverages returns the average weight of the days with entries on
+// each weekday, Monday first, leaving out weekdays without any
+func weekdayAverages(days []Day) []WeekdayAverage {
+	var sums [7]float64
+	var counts [7]int
+	for _, d := range days {
+		t, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		sums[t.Weekday()] += d.Weight
+		counts[t.Weekday()]++
+	}
+
+	var averages []WeekdayAverage
+	for i := range 7 {
+		weekday := time.Weekday((i + 1) % 7)
+		if counts[weekday] > 0 {
+			averages = append(averages, WeekdayAverage{Weekday: weekday, Days: counts[weekday], Mean: sums[weekday] / float64(counts[weekday])})
+		}
+	}
+	return averages
+}
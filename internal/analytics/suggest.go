@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"strings"
+
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// HealthyRangeSuggestion is the key of the suggested healthy BMI range
+const HealthyRangeSuggestion = "healthy"
+
+// GoalSuggestion is a goal weight, or a range of weights, suggested from the
+// user's height
+type GoalSuggestion struct {
+	Key  string  // name to adopt the suggestion by, e.g. "devine"
+	Name string  // description, e.g. "Devine formula"
+	Low  float64 // weight in the user's unit
+	High float64 // top of a range; equal to Low for a single weight
+}
+
+// IsRange reports whether the suggestion is a range of weights
+func (s GoalSuggestion) IsRange() bool {
+	return s.High > s.Low
+}
+
+// SuggestGoals suggests goals from the stored height: the weights for a
+// healthy BMI, then the ideal body weight formulas when the sex is known
+func SuggestGoals(settings *models.Settings) []GoalSuggestion {
+	suggestions := []GoalSuggestion{{
+		Key:  HealthyRangeSuggestion,
+		Name: "Healthy BMI range",
+		Low:  calculator.WeightForBMI(calculator.HealthyBMIMin, settings.Height, settings.WeightUnit, settings.HeightUnit),
+		High: calculator.WeightForBMI(calculator.HealthyBMIMax, settings.Height, settings.WeightUnit, settings.HeightUnit),
+	}}
+	if settings.Sex == "" {
+		return suggestions
+	}
+
+	for _, formula := range calculator.IdealWeightFormulas {
+		kg := formula.IdealWeight(settings.Height, settings.HeightUnit, settings.Sex)
+		weight := calculator.FromKg(kg, settings.WeightUnit)
+		suggestions = append(suggestions, GoalSuggestion{
+			Key:  strings.ToLower(formula.Name),
+			Name: formula.Name + " formula",
+			Low:  weight,
+			High: weight,
+		})
+	}
+	return suggestions
+}
+
+// FindSuggestion returns the suggestion with a key, ignoring case
+func FindSuggestion(suggestions []GoalSuggestion, key string) (GoalSuggestion, bool) {
+	for _, s := range suggestions {
+		if strings.EqualFold(s.Key, key) {
+			return s, true
+		}
+	}
+	return GoalSuggestion{}, false
+}
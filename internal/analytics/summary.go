@@ -0,0 +1,164 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// Summary holds statistics over a series of weight entries
+type Summary struct {
+	Count  int
+	From   string  // date of the oldest entry
+	To     string  // date of the newest entry
+	Start  float64 // oldest weight
+	Latest float64 // newest weight
+	Min    float64
+	Max    float64
+	Mean   float64
+}
+
+// Change returns the change in weight from the oldest entry to the newest
+func (s Summary) Change() float64 {
+	return s.Latest - s.Start
+}
+
+// Summarize computes statistics over weights, stored newest first
+func Summarize(weights []models.Weight) Summary {
+	if len(weights) == 0 {
+		return Summary{}
+	}
+
+	newest, oldest := weights[0], weights[len(weights)-1]
+	s := Summary{
+		Count:  len(weights),
+		From:   oldest.Date,
+		To:     newest.Date,
+		Start:  oldest.Weight,
+		Latest: newest.Weight,
+		Min:    math.MaxFloat64,
+		Max:    -math.MaxFloat64,
+	}
+
+	var total float64
+	for _, w := range weights {
+		total += w.Weight
+		s.Min = math.Min(s.Min, w.Weight)
+		s.Max = math.Max(s.Max, w.Weight)
+	}
+	s.Mean = total / float64(len(weights))
+
+	return s
+}
+
+// Period is a calendar period to group entries by
+type Period string
+
+// Calendar periods. Weeks start on Monday.
+const (
+	Week  Period = "week"
+	Month Period = "month"
+	Year  Period = "year"
+)
+
+// Periods lists the calendar periods by name
+var Periods = []Period{Week, Month, Year}
+
+// ParsePeriod returns the calendar period with the given name
+func ParsePeriod(name string) (Period, error) {
+	for _, p := range Periods {
+		if string(p) == strings.ToLower(strings.TrimSpace(name)) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown period %q: use week, month or year", name)
+}
+
+// Start returns the first day of the period containing a YYYY-MM-DD date
+func (p Period) Start(date string) time.Time {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}
+	}
+	switch p {
+	case Week:
+		return WeekStart(t)
+	case Year:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// WeekStart returns the Monday on or before a day
+func WeekStart(t time.Time) time.Time {
+	// Go counts weekdays from Sunday
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+// End returns the last day of the period starting on start
+func (p Period) End(start time.Time) time.Time {
+	switch p {
+	case Week:
+		return start.AddDate(0, 0, 6)
+	case Year:
+		return start.AddDate(1, 0, -1)
+	}
+	return start.AddDate(0, 1, -1)
+}
+
+// PeriodSummary summarizes the entries in one calendar period
+type PeriodSummary struct {
+	Start time.Time // first day of the period
+	Summary
+	RatePerWeek float64 // see RatePerWeek
+}
+
+// Summaries summarizes weights, stored newest first, by calendar period,
+// oldest period first. Periods without entries are left out.
+func Summaries(weights []models.Weight, period Period) []PeriodSummary {
+	var periods []PeriodSummary
+	// Walk oldest first, collecting each period's entries, which stay newest first
+	end := len(weights)
+	for i := len(weights) - 1; i >= 0; i-- {
+		start := period.Start(weights[i].Date)
+		if i > 0 && period.Start(weights[i-1].Date).Equal(start) {
+			continue
+		}
+		periods = append(periods, PeriodSummary{
+			Start:       start,
+			Summary:     Summarize(weights[i:end]),
+			RatePerWeek: RatePerWeek(weights[i:end]),
+		})
+		end = i
+	}
+	return periods
+}
+
+// MonthlySummaries summarizes weights, stored newest first, by calendar
+// month, oldest month first
+func MonthlySummaries(weights []models.Weight) []PeriodSummary {
+	return Summaries(weights, Month)
+}
+
+// MeanChanges returns the change in each period's mean weight from the
+// period before it, starting with the second period
+func MeanChanges(periods []PeriodSummary) []float64 {
+	var changes []float64
+	for i := 1; i < len(periods); i++ {
+		changes = append(changes, periods[i].Mean-periods[i-1].Mean)
+	}
+	return changes
+}
+
+// GoalProgress returns how far the way from the start weight to the goal the
+// latest weight is, from 0 to 1. Moving away from the goal counts as no
+// progress; starting at the goal counts as reaching it.
+func GoalProgress(start, latest, goal float64) float64 {
+	if start == goal {
+		return 1
+	}
+	return math.Max(0, math.Min(1, (start-latest)/(start-goal)))
+}
@@ -1,9 +1,17 @@
 package calculator
 
+import "math"
+
 // CalculateBMI calculates BMI based on weight, height, and units
 func CalculateBMI(weight, height float64, weightUnit, heightUnit string) float64 {
 	var bmi float64
 
+	// Stones are converted to kg and handled with the metric formulas
+	if weightUnit == "st" {
+		weight = weight * KgPerSt
+		weightUnit = "kg"
+	}
+
 	if weightUnit == "kg" && heightUnit == "cm" {
 		// BMI = kg / (m^2)
 		heightInMeters := height / 100.0
@@ -24,3 +32,111 @@ func CalculateBMI(weight, height float64, weightUnit, heightUnit string) float64
 
 	return bmi
 }
+
+// BMICategory is a named range of BMI values, from Min up to but not including Max
+type BMICategory struct {
+	Name string
+	Min  float64
+	Max  float64
+}
+
+// HealthyBMIMin is the lowest BMI that isn't underweight
+const HealthyBMIMin = 18.5
+
+// BMICategories are the WHO adult BMI categories
+var BMICategories = []BMICategory{
+	{Name: "Underweight", Min: 0, Max: 18.5},
+	{Name: "Normal", Min: 18.5, Max: 25},
+	{Name: "Overweight", Min: 25, Max: 30},
+	{Name: "Obese class I", Min: 30, Max: 35},
+	{Name: "Obese class II", Min: 35, Max: 40},
+	{Name: "Obese class III", Min: 40, Max: math.Inf(1)},
+}
+
+// AsianBMICategories are the WHO categories with the lower cut-offs suggested
+// for Asian populations, who have higher health risks at a lower BMI
+var AsianBMICategories = []BMICategory{
+	{Name: "Underweight", Min: 0, Max: 18.5},
+	{Name: "Normal", Min: 18.5, Max: 23},
+	{Name: "Overweight", Min: 23, Max: 27.5},
+	{Name: "Obese class I", Min: 27.5, Max: 32.5},
+	{Name: "Obese class II", Min: 32.5, Max: 37.5},
+	{Name: "Obese class III", Min: 37.5, Max: math.Inf(1)},
+}
+
+// BMIStandard is a set of BMI categories with the cut-offs for a population
+type BMIStandard struct {
+	Key        string // name of the standard in the bmi_standard setting
+	Name       string
+	Categories []BMICategory // from the lowest BMI up
+}
+
+// BMI standards, by key
+const (
+	WHOStandard   = "who"
+	AsianStandard = "asian"
+)
+
+// BMIStandards lists the BMI standards, the default first
+var BMIStandards = []BMIStandard{
+	{Key: WHOStandard, Name: "WHO", Categories: BMICategories},
+	{Key: AsianStandard, Name: "WHO Asian", Categories: AsianBMICategories},
+}
+
+// BMIStandardFor returns the BMI standard with a key, or the WHO standard
+// when there is none
+func BMIStandardFor(key string) BMIStandard {
+	for _, standard := range BMIStandards {
+		if standard.Key == key {
+			return standard
+		}
+	}
+	return BMIStandards[0]
+}
+
+// Category returns the category a BMI falls into
+func (s BMIStandard) Category(bmi float64) BMICategory {
+	for _, category := range s.Categories {
+		if bmi < category.Max {
+			return category
+		}
+	}
+	return s.Categories[len(s.Categories)-1]
+}
+
+// Prime returns the BMI Prime of a BMI: its ratio to the upper limit of the
+// normal category, so 1.0 is the top of the normal range
+func (s BMIStandard) Prime(bmi float64) float64 {
+	for _, category := range s.Categories {
+		if category.Name == "Normal" {
+			return bmi / category.Max
+		}
+	}
+	return 0
+}
+
+// Distance returns how many categories a category is from Normal
+func (s BMIStandard) Distance(c BMICategory) int {
+	index, normal := 0, 0
+	for i, category := range s.Categories {
+		if category == c {
+			index = i
+		}
+		if category.Name == "Normal" {
+			normal = i
+		}
+	}
+	return max(index-normal, normal-index)
+}
+
+// WeightForBMI returns the weight at which a person of the given height has
+// the given BMI. BMI is proportional to weight, so this scales the BMI of a
+// unit weight.
+func WeightForBMI(bmi, height float64, weightUnit, heightUnit string) float64 {
+	return bmi / CalculateBMI(1, height, weightUnit, heightUnit)
+}
+
+// CategoryForBMI returns the WHO BMI category a BMI falls into
+func CategoryForBMI(bmi float64) BMICategory {
+	return BMIStandards[0].Category(bmi)
+}
@@ -0,0 +1,190 @@
+package calculator
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Ages, in months, BMI is judged by BMI-for-age percentiles instead of the
+// adult categories. The CDC reference starts at two years old.
+const (
+	PediatricMinAgeMonths = 24
+	PediatricMaxAgeMonths = 240
+)
+
+// BMIForAgeCategories are the CDC BMI-for-age categories for children and
+// teens. Their Min and Max are percentiles rather than BMIs.
+var BMIForAgeCategories = []BMICategory{
+	{Name: "Underweight", Min: 0, Max: 5},
+	{Name: "Healthy weight", Min: 5, Max: 85},
+	{Name: "Overweight", Min: 85, Max: 95},
+	{Name: "Obesity", Min: 95, Max: math.Inf(1)},
+}
+
+// CategoryForPercentile returns the BMI-for-age category of a percentile
+func CategoryForPercentile(percentile float64) BMICategory {
+	for _, category := range BMIForAgeCategories {
+		if percentile < category.Max {
+			return category
+		}
+	}
+	return BMIForAgeCategories[len(BMIForAgeCategories)-1]
+}
+
+// cdcBMIForAge is the CDC's BMI-for-age growth reference, bmiagerev.csv from
+// the CDC growth charts data files
+//
+//go:embed bmiagerev.csv
+var cdcBMIForAge string
+
+// cdcGrowthReference is the embedded reference, parsed once. A file with rows
+// that can't be read is a build mistake, so it panics rather than quietly
+// leaving thicc without a reference. A file with only its header row has no
+// reference to read.
+var cdcGrowthReference = func() []LMS {
+	if strings.Count(strings.TrimSpace(cdcBMIForAge), "\n") == 0 {
+		return nil
+	}
+	values, err := ParseLMSTable(strings.NewReader(cdcBMIForAge), "")
+	if err != nil {
+		panic(fmt.Sprintf("embedded bmiagerev.csv: %v", err))
+	}
+	return values
+}()
+
+// CDCGrowthReference returns the CDC's BMI-for-age growth reference embedded
+// in thicc, which is used for the sexes no reference was imported for
+func CDCGrowthReference() []LMS {
+	return cdcGrowthReference
+}
+
+// LMS holds the Box-Cox power (L), median (M) and coefficient of variation (S)
+// of the BMI of children of one sex at one age, from a growth reference
+type LMS struct {
+	Sex       string // Male or Female
+	AgeMonths float64
+	L         float64
+	M         float64
+	S         float64
+}
+
+// ZScore returns how many standard deviations a BMI is from the median for the age
+func (p LMS) ZScore(bmi float64) float64 {
+	if p.L == 0 {
+		return math.Log(bmi/p.M) / p.S
+	}
+	return (math.Pow(bmi/p.M, p.L) - 1) / (p.L * p.S)
+}
+
+// Percentile returns the percentile, from 0 to 100, of a z-score
+func Percentile(z float64) float64 {
+	return 50 * math.Erfc(-z/math.Sqrt2)
+}
+
+// LMSTable is a growth reference: LMS values by sex, in order of age
+type LMSTable map[string][]LMS
+
+// ErrInvalidLMSTable is returned for growth reference files that can't be read
+var ErrInvalidLMSTable = errors.New("growth reference must be a CSV file with Agemos (or Month), L, M and S columns, and a Sex column (1 male, 2 female) unless the sex is given")
+
+// NewLMSTable builds a growth reference from LMS values, sorting them by age
+func NewLMSTable(values []LMS) LMSTable {
+	table := make(LMSTable)
+	for _, v := range values {
+		table[v.Sex] = append(table[v.Sex], v)
+	}
+	for _, values := range table {
+		sort.Slice(values, func(i, j int) bool { return values[i].AgeMonths < values[j].AgeMonths })
+	}
+	return table
+}
+
+// ParseLMSTable reads a growth reference in the CSV format of the CDC's
+// bmiagerev.csv: a header row naming Sex (1 for male, 2 for female), Agemos,
+// L, M and S columns; other columns, such as percentiles, are ignored.
+// Files for one sex, such as the WHO's with a Month column, need the sex given.
+func ParseLMSTable(r io.Reader, sex string) ([]LMS, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil || len(records) < 2 {
+		return nil, ErrInvalidLMSTable
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	age, ok := columns["agemos"]
+	if !ok {
+		age, ok = columns["month"]
+	}
+	l, okL := columns["l"]
+	m, okM := columns["m"]
+	s, okS := columns["s"]
+	sexColumn, okSex := columns["sex"]
+	if !ok || !okL || !okM || !okS || (!okSex && sex == "") {
+		return nil, ErrInvalidLMSTable
+	}
+
+	var values []LMS
+	for n, record := range records[1:] {
+		// The CDC files repeat the header row between the sexes
+		if okSex && strings.EqualFold(strings.TrimSpace(record[sexColumn]), "sex") {
+			continue
+		}
+		v := LMS{Sex: sex}
+		if okSex {
+			switch strings.TrimSpace(record[sexColumn]) {
+			case "1":
+				v.Sex = Male
+			case "2":
+				v.Sex = Female
+			default:
+				return nil, fmt.Errorf("row %d: sex must be 1 or 2", n+2)
+			}
+		}
+		for _, field := range []struct {
+			column int
+			value  *float64
+		}{{age, &v.AgeMonths}, {l, &v.L}, {m, &v.M}, {s, &v.S}} {
+			if *field.value, err = strconv.ParseFloat(strings.TrimSpace(record[field.column]), 64); err != nil {
+				return nil, fmt.Errorf("row %d: %w", n+2, ErrInvalidLMSTable)
+			}
+		}
+		if v.M <= 0 || v.S <= 0 {
+			return nil, fmt.Errorf("row %d: %w", n+2, ErrInvalidLMSTable)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// At returns the LMS values for a sex at an age in months, interpolated
+// between the ages in the table. It reports false when the table doesn't
+// cover the sex or age.
+func (t LMSTable) At(sex string, ageMonths float64) (LMS, bool) {
+	values := t[sex]
+	if len(values) == 0 || ageMonths < values[0].AgeMonths || ageMonths > values[len(values)-1].AgeMonths {
+		return LMS{}, false
+	}
+
+	i := sort.Search(len(values), func(i int) bool { return values[i].AgeMonths >= ageMonths })
+	if values[i].AgeMonths == ageMonths {
+		return values[i], true
+	}
+	lo, hi := values[i-1], values[i]
+	f := (ageMonths - lo.AgeMonths) / (hi.AgeMonths - lo.AgeMonths)
+	return LMS{
+		Sex:       sex,
+		AgeMonths: ageMonths,
+		L:         lo.L + f*(hi.L-lo.L),
+		M:         lo.M + f*(hi.M-lo.M),
+		S:         lo.S + f*(hi.S-lo.S),
+	}, true
+}
@@ -0,0 +1,44 @@
+package calculator
+
+// HealthyBMIMax is the highest BMI in the healthy range
+const HealthyBMIMax = 24.9
+
+// Sexes the ideal body weight formulas distinguish
+const (
+	Male   = "male"
+	Female = "female"
+)
+
+// InchesPerFiveFeet is the height the ideal body weight formulas start from
+const InchesPerFiveFeet = 60.0
+
+// IdealWeightFormula is an ideal body weight formula: a base weight in kg at
+// five feet tall, plus a weight for every inch above it, for each sex
+type IdealWeightFormula struct {
+	Name          string
+	MaleBase      float64
+	MalePerInch   float64
+	FemaleBase    float64
+	FemalePerInch float64
+}
+
+// IdealWeightFormulas are the common ideal body weight formulas
+var IdealWeightFormulas = []IdealWeightFormula{
+	{Name: "Devine", MaleBase: 50, MalePerInch: 2.3, FemaleBase: 45.5, FemalePerInch: 2.3},
+	{Name: "Robinson", MaleBase: 52, MalePerInch: 1.9, FemaleBase: 49, FemalePerInch: 1.7},
+	{Name: "Miller", MaleBase: 56.2, MalePerInch: 1.41, FemaleBase: 53.1, FemalePerInch: 1.36},
+	{Name: "Hamwi", MaleBase: 48, MalePerInch: 2.7, FemaleBase: 45.5, FemalePerInch: 2.2},
+}
+
+// IdealWeight returns the ideal body weight in kg for a height in the given
+// unit ("in" or "cm") and sex. Heights under five feet subtract from the base.
+func (f IdealWeightFormula) IdealWeight(height float64, heightUnit, sex string) float64 {
+	if heightUnit == "cm" {
+		height /= CmPerInch
+	}
+	inches := height - InchesPerFiveFeet
+	if sex == Female {
+		return f.FemaleBase + f.FemalePerInch*inches
+	}
+	return f.MaleBase + f.MalePerInch*inches
+}
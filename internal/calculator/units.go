@@ -0,0 +1,75 @@
+package calculator
+
+import "math"
+
+// Weight conversion factors
+const (
+	KgPerLb   = 0.45359237
+	LbsPerKg  = 1 / KgPerLb
+	LbsPerSt  = 14.0
+	KgPerSt   = LbsPerSt * KgPerLb
+	CmPerInch = 2.54
+)
+
+// ToKg converts a weight in the given unit ("kg", "lbs" or "st") to kilograms
+func ToKg(weight float64, unit string) float64 {
+	switch unit {
+	case "lbs":
+		return weight * KgPerLb
+	case "st":
+		return weight * KgPerSt
+	default:
+		return weight
+	}
+}
+
+// FromKg converts a weight in kilograms to the given unit ("kg", "lbs" or "st")
+func FromKg(kg float64, unit string) float64 {
+	switch unit {
+	case "lbs":
+		return kg * LbsPerKg
+	case "st":
+		return kg / KgPerSt
+	default:
+		return kg
+	}
+}
+
+// ConvertWeight converts a weight between units
+func ConvertWeight(weight float64, from, to string) float64 {
+	if from == to {
+		return weight
+	}
+	return FromKg(ToKg(weight, from), to)
+}
+
+// SplitStones splits a weight in stones into whole stones and remaining pounds
+func SplitStones(stones float64) (int, float64) {
+	whole := math.Floor(stones)
+	return int(whole), (stones - whole) * LbsPerSt
+}
+
+// RoundToIncrement rounds a value to the nearest multiple of increment, e.g.
+// 70.27 to 70.25 with an increment of 0.05. A zero increment leaves the value unchanged.
+func RoundToIncrement(value, increment float64) float64 {
+	if increment <= 0 {
+		return value
+	}
+	rounded := math.Round(value/increment) * increment
+
+	// Strip floating point noise such as 70.25000000000001
+	scale := math.Pow(10, float64(IncrementDecimals(increment)))
+	return math.Round(rounded*scale) / scale
+}
+
+// IncrementDecimals returns the number of decimals needed to show multiples
+// of an increment, e.g. 1 for 0.2 and 2 for 0.05
+func IncrementDecimals(increment float64) int {
+	for decimals := 0; decimals < 6; decimals++ {
+		scale := math.Pow(10, float64(decimals))
+		if math.Abs(increment*scale-math.Round(increment*scale)) < 1e-9 {
+			return decimals
+		}
+	}
+	return 6
+}
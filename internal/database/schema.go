@@ -1,5 +1,7 @@
 package database
 
+import "fmt"
+
 const schema = `
 CREATE TABLE IF NOT EXISTS weights (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -16,13 +18,115 @@ CREATE TABLE IF NOT EXISTS settings (
     value TEXT NOT NULL,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 );
+
+CREATE TABLE IF NOT EXISTS operations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    command TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    before TEXT NOT NULL,
+    after TEXT NOT NULL,
+    undone INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS achievements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    key TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    date TEXT NOT NULL,
+    weight_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS goals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    parent_id INTEGER NOT NULL DEFAULT 0,
+    target REAL NOT NULL,
+    start_date TEXT NOT NULL,
+    start_weight REAL NOT NULL DEFAULT 0,
+    deadline TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'active',
+    end_date TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS growth_references (
+    sex TEXT NOT NULL,
+    age_months REAL NOT NULL,
+    l REAL NOT NULL,
+    m REAL NOT NULL,
+    s REAL NOT NULL,
+    PRIMARY KEY (sex, age_months)
+);
 `
 
+// column describes a column added to an existing table after the initial schema
+type column struct {
+	table      string
+	name       string
+	definition string
+}
+
+// addedColumns lists columns introduced after the initial release. They are
+// added to databases created by older versions when the schema is initialized.
+var addedColumns = []column{
+	{"weights", "time", "TEXT NOT NULL DEFAULT ''"},
+	{"weights", "note", "TEXT NOT NULL DEFAULT ''"},
+	{"weights", "deleted_at", "DATETIME"},
+	{"goals", "range_low", "REAL NOT NULL DEFAULT 0"},
+	{"goals", "range_high", "REAL NOT NULL DEFAULT 0"},
+}
+
 // InitializeSchema creates all tables
 func InitializeSchema(db *DB) error {
 	if _, err := db.Exec(schema); err != nil {
 		return err
 	}
 
+	for _, c := range addedColumns {
+		if err := addColumnIfMissing(db, c); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// addColumnIfMissing adds a column to a table unless it already exists
+func addColumnIfMissing(db *DB, c column) error {
+	exists, err := hasColumn(db, c.table, c.name)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition))
+	return err
+}
+
+// hasColumn reports whether a table has a column with the given name
+func hasColumn(db *DB, table, name string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			colName   string
+			colType   string
+			notNull   int
+			dfltValue any
+			pk        int
+		)
+		if err := rows.Scan(&cid, &colName, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if colName == name {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
@@ -0,0 +1,57 @@
+package display
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// MilestoneTitle describes a milestone, e.g. "Lost 10.00 lbs" or "7-day streak"
+func MilestoneTitle(m analytics.Milestone, settings *models.Settings) string {
+	switch m.Kind {
+	case analytics.MilestoneLost:
+		return "Lost " + FormatWeightFor(m.Amount, settings)
+	case analytics.MilestoneGained:
+		return "Gained " + FormatWeightFor(m.Amount, settings)
+	case analytics.MilestoneBMI:
+		return fmt.Sprintf("Reached the %s BMI category", m.Category)
+	case analytics.MilestoneHalfway:
+		return "Halfway to your goal of " + FormatWeightFor(m.Amount, settings)
+	case analytics.MilestoneGoal:
+		return "Reached your goal of " + FormatWeightFor(m.Amount, settings)
+	case analytics.MilestoneDayStreak:
+		return fmt.Sprintf("%d-day streak", int(m.Amount))
+	case analytics.MilestoneWeekStreak:
+		return fmt.Sprintf("%d-week streak", int(m.Amount))
+	}
+	return m.Key
+}
+
+// FormatCelebration announces an achievement reached by a new entry
+func FormatCelebration(title string) string {
+	return CelebrationStyle.Render(fmt.Sprintf("Achievement unlocked: %s!", title))
+}
+
+// RenderAchievementsTable creates a table of recorded achievements with the
+// current streaks of days and weeks with entries
+func RenderAchievementsTable(achievements []models.Achievement, dayStreak, weekStreak int) string {
+	streaks := InfoStyle.Render(fmt.Sprintf("Current streaks: %s and %s in a row with an entry",
+		plural(dayStreak, "day"), plural(weekStreak, "week")))
+	if len(achievements) == 0 {
+		return streaks + "\n\nNo achievements yet. Keep logging with: thicc add <weight> [date]"
+	}
+
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(TableBorderStyle).
+		Headers("Date", "Achievement")
+
+	for _, a := range achievements {
+		t.Row(FormatDate(a.Date), a.Title)
+	}
+
+	return streaks + "\n\n" + t.Render()
+}
@@ -0,0 +1,69 @@
+package display
+
+// ASCII chart glyphs
+const (
+	asciiPoint     = '*' // a column with a single weight (or several equal ones)
+	asciiRange     = '|' // the min-max range of a column with several weights
+	asciiLine      = '.' // the line connecting consecutive columns
+	asciiGoal      = '-' // the goal weight line
+	asciiMilestone = '_' // milestone lines
+	asciiGoalRange = ':' // the band of a goal range
+	asciiBMICutoff = '=' // where a BMI category starts
+)
+
+// asciiRenderer draws one point per character cell using only ASCII, for
+// terminals that can't render Unicode
+type asciiRenderer struct{}
+
+// Plot draws each column's weights, as a point or a min-max range, and
+// connects consecutive columns unless the time between them is a gap
+func (asciiRenderer) Plot(data ChartData, width, height int) [][]rune {
+	grid := newPlotGrid(width, height)
+	drawGoalLines(grid, data, asciiGoal, asciiMilestone, asciiGoalRange, asciiBMICutoff)
+	columns := bucketColumns(data, width)
+
+	// Connecting lines first, so points and ranges are drawn over them
+	prevX, prevY := -1, -1
+	for x, col := range columns {
+		if col == nil {
+			continue
+		}
+		if prevX >= 0 && !col.gapBefore {
+			drawLine(prevX, prevY, x, data.Row(col.first, height), func(x, y int) {
+				grid[y][x] = asciiLine
+			})
+		}
+		prevX, prevY = x, data.Row(col.last, height)
+	}
+
+	for x, col := range columns {
+		if col == nil {
+			continue
+		}
+		top, bottom := data.Row(col.max, height), data.Row(col.min, height)
+		if top == bottom {
+			grid[top][x] = asciiPoint
+			continue
+		}
+		for y := top; y <= bottom; y++ {
+			grid[y][x] = asciiRange
+		}
+	}
+
+	return grid
+}
+
+// Legend explains the range glyph when a column has several different weights
+func (asciiRenderer) Legend(data ChartData, width int) []string {
+	for _, col := range bucketColumns(data, width) {
+		if col != nil && col.min != col.max {
+			return []string{string(asciiRange) + " range of several entries"}
+		}
+	}
+	return nil
+}
+
+// Unicode reports that the ASCII renderer doesn't need Unicode
+func (asciiRenderer) Unicode() bool {
+	return false
+}
@@ -0,0 +1,63 @@
+package display
+
+import "math"
+
+// blockEighths holds the lower block elements from one to eight eighths high
+var blockEighths = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// blockRenderer draws the top of a bar chart with eighth-block characters,
+// for eight levels per character cell
+type blockRenderer struct{}
+
+// Plot draws each column at its average weight, interpolating across columns
+// without entries except where the time between entries is a gap
+func (blockRenderer) Plot(data ChartData, width, height int) [][]rune {
+	grid := newPlotGrid(width, height)
+	drawGoalLines(grid, data, goalGlyph, milestoneGlyph, goalRangeGlyph, bmiCutoffGlyph)
+
+	columns := bucketColumns(data, width)
+	levels := make([]float64, width)
+	filled := make([]bool, width)
+	prevX := -1
+	for x, col := range columns {
+		if col == nil {
+			continue
+		}
+		levels[x], filled[x] = data.Level(col.mean()), true
+		if prevX >= 0 && !col.gapBefore {
+			for ix := prevX + 1; ix < x; ix++ {
+				t := float64(ix-prevX) / float64(x-prevX)
+				levels[ix], filled[ix] = levels[prevX]+(levels[x]-levels[prevX])*t, true
+			}
+		}
+		prevX = x
+	}
+
+	// Draw the top of each column's bar only, so the goal line stays visible
+	for x := range levels {
+		if !filled[x] {
+			continue
+		}
+		// Height in eighths of a cell, at least one so low weights still show
+		eighths := max(int(math.Round(levels[x]*float64(height*8))), 1)
+		row := height - 1 - (eighths-1)/8
+		grid[row][x] = blockEighths[(eighths-1)%8]
+	}
+
+	return grid
+}
+
+// Legend explains that columns with several entries show their average
+func (blockRenderer) Legend(data ChartData, width int) []string {
+	for _, col := range bucketColumns(data, width) {
+		if col != nil && col.count > 1 {
+			return []string{string(blockEighths[7]) + " average of several entries"}
+		}
+	}
+	return nil
+}
+
+// Unicode reports that block elements need Unicode
+func (blockRenderer) Unicode() bool {
+	return true
+}
@@ -0,0 +1,76 @@
+package display
+
+// brailleBlank is the empty braille pattern; the dots are bits added to it
+const brailleBlank = 0x2800
+
+// brailleDots holds the bit of each dot in a braille cell, by row and column
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// brailleRenderer draws with braille patterns, which have 2×4 dots per
+// character cell, for eight times the resolution of one point per cell
+type brailleRenderer struct{}
+
+// Plot draws the weights on a dot canvas, as points or min-max ranges
+// connected by lines except across gaps, and the goal and milestone lines in
+// empty cells
+func (brailleRenderer) Plot(data ChartData, width, height int) [][]rune {
+	dotsWide, dotsHigh := width*2, height*4
+	dots := make([][]bool, dotsHigh)
+	for y := range dots {
+		dots[y] = make([]bool, dotsWide)
+	}
+	set := func(x, y int) {
+		dots[y][x] = true
+	}
+
+	columns := bucketColumns(data, dotsWide)
+	prevX, prevY := -1, -1
+	for x, col := range columns {
+		if col == nil {
+			continue
+		}
+		if prevX >= 0 && !col.gapBefore {
+			drawLine(prevX, prevY, x, data.Row(col.first, dotsHigh), set)
+		}
+		for y := data.Row(col.max, dotsHigh); y <= data.Row(col.min, dotsHigh); y++ {
+			set(x, y)
+		}
+		prevX, prevY = x, data.Row(col.last, dotsHigh)
+	}
+
+	// Draw the goal and milestone lines first so the weights are drawn over it
+	grid := newPlotGrid(width, height)
+	drawGoalLines(grid, data, goalGlyph, milestoneGlyph, goalRangeGlyph, bmiCutoffGlyph)
+	for row := range grid {
+		for col := range grid[row] {
+			cell := rune(0)
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					if dots[row*4+dy][col*2+dx] {
+						cell |= brailleDots[dy][dx]
+					}
+				}
+			}
+			if cell != 0 {
+				grid[row][col] = brailleBlank + cell
+			}
+		}
+	}
+
+	return grid
+}
+
+// Legend returns nothing since ranges read as vertical runs of dots
+func (brailleRenderer) Legend(data ChartData, width int) []string {
+	return nil
+}
+
+// Unicode reports that braille patterns need Unicode
+func (brailleRenderer) Unicode() bool {
+	return true
+}
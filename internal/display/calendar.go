@@ -0,0 +1,191 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+const (
+	// calendarLabelWidth is the width of the weekday labels before the calendar
+	calendarLabelWidth = 4
+
+	// calendarCellWidth is the width of a day in the calendar: its mark and a space
+	calendarCellWidth = 2
+)
+
+// calendarWeekdays labels every other row of the calendar, like GitHub's
+// contribution graph. Weeks start on Monday.
+var calendarWeekdays = []string{"Mon", "", "Wed", "", "Fri", "", "Sun"}
+
+// calendarLevels are the daily changes, as a fraction of the weight before
+// them, at which a day's shading gets heavier
+var calendarLevels = []float64{0.0025, 0.0075}
+
+// RenderCalendar creates a heatmap of a year's days, one column per week, like
+// GitHub's contribution graph. Days with entries are shaded by their change in
+// weight, and the current and longest streaks of days with entries are shown
+// below it with a legend. Days are oldest first; today is YYYY-MM-DD. The
+// year is split into halves or quarters to fit narrow terminals.
+func RenderCalendar(year int, days []analytics.Day, settings *models.Settings, today string, opts Options) string {
+	unicode := opts.chart().Unicode()
+
+	byDate := make(map[string]analytics.Day, len(days))
+	var yearDays []analytics.Day
+	prefix := fmt.Sprintf("%04d-", year)
+	for _, d := range days {
+		byDate[d.Date] = d
+		if strings.HasPrefix(d.Date, prefix) {
+			yearDays = append(yearDays, d)
+		}
+	}
+
+	// Use the fewest blocks of months that fit the width
+	blocks := 1
+	for _, n := range []int{1, 2, 4} {
+		blocks = n
+		widest := 0
+		for b := 0; b < n; b++ {
+			first, last := calendarBlockMonths(b, n)
+			widest = max(widest, calendarLabelWidth+calendarWeeks(year, first, last)*calendarCellWidth)
+		}
+		if opts.Width <= 0 || widest <= opts.Width {
+			break
+		}
+	}
+
+	var output strings.Builder
+	output.WriteString(HeaderStyle.Render(fmt.Sprintf("Weigh-ins in %d", year)))
+	output.WriteString("\n")
+	for b := 0; b < blocks; b++ {
+		if b > 0 {
+			output.WriteString("\n\n")
+		}
+		first, last := calendarBlockMonths(b, blocks)
+		output.WriteString(renderCalendarBlock(year, first, last, byDate, settings, today, unicode))
+	}
+
+	// Streaks count days before the year too, so only show the current one for this year
+	current, _ := analytics.Streaks(days, today)
+	_, longest := analytics.Streaks(yearDays, today)
+	stats := fmt.Sprintf("%d days with entries", len(yearDays))
+	if strings.HasPrefix(today, prefix) {
+		stats += fmt.Sprintf(" | Current streak: %s", plural(current, "day"))
+	}
+	stats += fmt.Sprintf(" | Longest streak: %s", plural(longest, "day"))
+
+	output.WriteString("\n\n")
+	output.WriteString(InfoStyle.Render(stats))
+	output.WriteString("\n")
+	output.WriteString(calendarLegend(unicode))
+	return output.String()
+}
+
+// renderCalendarBlock draws the weeks of a year from the first month to the
+// last, with month labels above them and weekday labels beside them
+func renderCalendarBlock(year int, first, last time.Month, byDate map[string]analytics.Day, settings *models.Settings, today string, unicode bool) string {
+	start := analytics.WeekStart(time.Date(year, first, 1, 0, 0, 0, 0, time.UTC))
+	weeks := calendarWeeks(year, first, last)
+
+	// Month labels go above the week their first day is in
+	header := []rune(strings.Repeat(" ", calendarLabelWidth+weeks*calendarCellWidth+3))
+	for m := first; m <= last; m++ {
+		week := int(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC).Sub(start).Hours()/24) / 7
+		copy(header[calendarLabelWidth+week*calendarCellWidth:], []rune(m.String()[:3]))
+	}
+
+	var output strings.Builder
+	output.WriteString(strings.TrimRight(string(header), " "))
+	for weekday, label := range calendarWeekdays {
+		output.WriteString("\n")
+		output.WriteString(fmt.Sprintf("%-*s", calendarLabelWidth, label))
+		var row strings.Builder
+		for week := 0; week < weeks; week++ {
+			date := start.AddDate(0, 0, week*7+weekday)
+			day := date.Format("2006-01-02")
+			if date.Year() != year || date.Month() < first || date.Month() > last || day > today {
+				row.WriteString(strings.Repeat(" ", calendarCellWidth))
+				continue
+			}
+			row.WriteString(calendarCell(byDate, day, settings, unicode))
+			row.WriteString(" ")
+		}
+		output.WriteString(strings.TrimRight(row.String(), " "))
+	}
+	return output.String()
+}
+
+// calendarCell returns a day's mark, shaded by its change in weight
+func calendarCell(byDate map[string]analytics.Day, date string, settings *models.Settings, unicode bool) string {
+	empty, mark := "·", "■"
+	if !unicode {
+		empty, mark = ".", "#"
+	}
+
+	d, ok := byDate[date]
+	if !ok {
+		return CalendarEmptyStyle.Render(empty)
+	}
+	if d.First || d.Change == 0 {
+		return CalendarSteadyStyle.Render(mark)
+	}
+
+	previous := d.Weight - d.Change
+	level := 0
+	for _, threshold := range calendarLevels {
+		if previous > 0 && math.Abs(d.Change)/previous >= threshold {
+			level++
+		}
+	}
+
+	// Moving towards the goal means the change has the sign of the way from
+	// the previous weight to the goal
+	if (settings.GoalWeight-previous)*d.Change > 0 {
+		return CalendarTowardGoalStyles[level].Render(mark)
+	}
+	return CalendarAwayFromGoalStyles[level].Render(mark)
+}
+
+// calendarLegend explains the calendar's shading
+func calendarLegend(unicode bool) string {
+	empty, mark := "·", "■"
+	if !unicode {
+		empty, mark = ".", "#"
+	}
+	shades := func(styles []lipgloss.Style) string {
+		var s strings.Builder
+		for _, style := range styles {
+			s.WriteString(style.Render(mark))
+		}
+		return s.String()
+	}
+	return fmt.Sprintf("%s no entry  %s no change  %s towards goal  %s away from goal  (small to large change)",
+		CalendarEmptyStyle.Render(empty), CalendarSteadyStyle.Render(mark),
+		shades(CalendarTowardGoalStyles), shades(CalendarAwayFromGoalStyles))
+}
+
+// calendarBlockMonths returns the first and last months of block b when a
+// year is split into the given number of blocks
+func calendarBlockMonths(b, blocks int) (first, last time.Month) {
+	return time.Month(b*12/blocks + 1), time.Month((b + 1) * 12 / blocks)
+}
+
+// calendarWeeks returns the number of week columns the months of a year take
+func calendarWeeks(year int, first, last time.Month) int {
+	start := analytics.WeekStart(time.Date(year, first, 1, 0, 0, 0, 0, time.UTC))
+	end := time.Date(year, last+1, 0, 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours()/24)/7 + 1
+}
+
+// plural formats a count of a unit, e.g. "1 day" or "3 days"
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,372 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+const (
+	// gapMinInterval is the shortest time between two entries drawn as a gap
+	gapMinInterval = 7 * 24 * time.Hour
+
+	// gapMedianFactor makes an interval a gap when it is this many times the
+	// median interval between entries, so weekly weigh-ins aren't all gaps
+	gapMedianFactor = 3
+
+	// TrendWindow is the span of the trailing average drawn as the trend
+	TrendWindow = 7 * 24 * time.Hour
+)
+
+// ChartPoint is a weight entry placed in time
+type ChartPoint struct {
+	At     time.Time
+	Weight float64
+	// GapBefore is set when the time since the previous point is long enough
+	// that the two shouldn't be connected
+	GapBefore bool
+	Note      string // the entry's note, marked as an event on image charts
+}
+
+// ChartData is the weight series a chart renderer draws
+type ChartData struct {
+	Points     []ChartPoint // oldest first
+	Goal       float64
+	Milestones []float64   // targets on the way to the goal, drawn as lines of their own
+	GoalLow    float64     // bottom of a goal range, drawn as a band instead of the goal line
+	GoalHigh   float64     // top of a goal range; both are zero without one
+	Min        float64     // bottom of the weight axis, including the goal, milestones and some padding
+	Max        float64     // top of the weight axis
+	BMICutoffs []BMICutoff // where BMI categories start within the weight axis, when drawn
+}
+
+// BMICutoff is the weight at which a BMI category starts, drawn as a line
+// between the categories' bands
+type BMICutoff struct {
+	Weight float64
+	BMI    float64
+}
+
+// ChartRenderer draws the plot area of the weight graph. The graph's labels,
+// axes and border are drawn around it.
+type ChartRenderer interface {
+	// Plot draws the weights, goal and milestone lines into a grid of width × height cells
+	Plot(data ChartData, width, height int) [][]rune
+
+	// Legend explains the glyphs a plot of the given width uses, if any need explaining
+	Legend(data ChartData, width int) []string
+
+	// Unicode reports whether the plot needs a terminal that renders Unicode.
+	// The graph's frame is drawn in ASCII otherwise.
+	Unicode() bool
+}
+
+// chartRenderers maps the names accepted by --chart to renderers
+var chartRenderers = map[string]ChartRenderer{
+	"braille": brailleRenderer{},
+	"block":   blockRenderer{},
+	"ascii":   asciiRenderer{},
+}
+
+// ChartNames lists the chart renderer names, the default first
+var ChartNames = []string{"braille", "block", "ascii"}
+
+// ChartRendererByName returns the chart renderer with the given name
+func ChartRendererByName(name string) (ChartRenderer, error) {
+	renderer, ok := chartRenderers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown chart %q: use %s", name, strings.Join(ChartNames, ", "))
+	}
+	return renderer, nil
+}
+
+// DefaultChartRenderer returns the braille renderer, or the ASCII renderer
+// when the terminal can't render Unicode
+func DefaultChartRenderer() ChartRenderer {
+	if SupportsUnicode() {
+		return brailleRenderer{}
+	}
+	return asciiRenderer{}
+}
+
+// SupportsUnicode reports whether the terminal's locale uses UTF-8, going by
+// the first of LC_ALL, LC_CTYPE and LANG that is set. Windows terminals are
+// assumed to render Unicode.
+func SupportsUnicode() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return false
+}
+
+// PrepareChart places weight entries, stored newest first, in time and works
+// out the weight axis and the gaps between entries. Entries with a time of day
+// are placed within their day. Milestones are drawn as lines besides the goal's.
+func PrepareChart(weights []models.Weight, goal float64, milestones ...float64) ChartData {
+	wr := calculateWeightRange(weights, goal, milestones...)
+	data := ChartData{Goal: goal, Milestones: milestones, Min: wr.min, Max: wr.max}
+
+	for _, w := range reverseWeights(weights) {
+		at, err := time.Parse("2006-01-02", w.Date)
+		if err != nil {
+			continue
+		}
+		if clock, err := time.Parse("15:04", w.Time); err == nil {
+			at = at.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+		}
+		data.Points = append(data.Points, ChartPoint{At: at, Weight: w.Weight, Note: w.Note})
+	}
+
+	threshold := gapThreshold(data.Points)
+	for i := 1; i < len(data.Points); i++ {
+		data.Points[i].GapBefore = data.Points[i].At.Sub(data.Points[i-1].At) > threshold
+	}
+
+	return data
+}
+
+// PrepareChartFor prepares a chart of weights, stored newest first, with the
+// goal, milestones and goal range in the settings
+func PrepareChartFor(weights []models.Weight, settings *models.Settings) ChartData {
+	if !settings.HasGoalRange() {
+		return PrepareChart(weights, settings.GoalWeight, settings.Milestones...)
+	}
+
+	// The weight axis takes in the whole range
+	data := PrepareChart(weights, settings.GoalWeight, settings.Milestones...)
+	wr := calculateWeightRange(weights, settings.GoalLow, append([]float64{settings.GoalHigh}, settings.Milestones...)...)
+	data.GoalLow, data.GoalHigh, data.Min, data.Max = settings.GoalLow, settings.GoalHigh, wr.min, wr.max
+	return data
+}
+
+// WithBMICutoffs returns the chart with lines where the categories of the
+// user's BMI standard start, for those within the weight axis. Without a
+// height there are none.
+func (d ChartData) WithBMICutoffs(settings *models.Settings) ChartData {
+	if settings.Height <= 0 {
+		return d
+	}
+	for _, category := range settings.BMICutoffs().Categories {
+		weight := calculator.WeightForBMI(category.Min, settings.Height, settings.WeightUnit, settings.HeightUnit)
+		if weight > d.Min && weight < d.Max {
+			d.BMICutoffs = append(d.BMICutoffs, BMICutoff{Weight: weight, BMI: category.Min})
+		}
+	}
+	return d
+}
+
+// HasGoalRange reports whether the chart shows a goal range instead of a goal line
+func (d ChartData) HasGoalRange() bool {
+	return d.GoalHigh > d.GoalLow
+}
+
+// Start returns the time of the oldest point
+func (d ChartData) Start() time.Time {
+	if len(d.Points) == 0 {
+		return time.Time{}
+	}
+	return d.Points[0].At
+}
+
+// End returns the time of the newest point
+func (d ChartData) End() time.Time {
+	if len(d.Points) == 0 {
+		return time.Time{}
+	}
+	return d.Points[len(d.Points)-1].At
+}
+
+// Column maps a time onto one of the given number of columns, proportionally
+// to the time elapsed since the oldest point
+func (d ChartData) Column(t time.Time, columns int) int {
+	span := d.End().Sub(d.Start())
+	if span <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(t.Sub(d.Start())) / float64(span) * float64(columns-1)))
+}
+
+// TimeAt returns the time at one of the given number of columns
+func (d ChartData) TimeAt(column, columns int) time.Time {
+	if columns <= 1 {
+		return d.Start()
+	}
+	span := d.End().Sub(d.Start())
+	return d.Start().Add(time.Duration(float64(span) * float64(column) / float64(columns-1)))
+}
+
+// Trend returns the trailing average of the weights over TrendWindow at each
+// point, smoothing out day-to-day swings. Averages don't reach back across gaps.
+func (d ChartData) Trend() []ChartPoint {
+	trend := make([]ChartPoint, len(d.Points))
+	start, sum := 0, 0.0
+	for i, p := range d.Points {
+		if p.GapBefore {
+			start, sum = i, 0
+		}
+		sum += p.Weight
+		for p.At.Sub(d.Points[start].At) > TrendWindow {
+			sum -= d.Points[start].Weight
+			start++
+		}
+		trend[i] = ChartPoint{At: p.At, Weight: sum / float64(i-start+1), GapBefore: p.GapBefore}
+	}
+	return trend
+}
+
+// Level returns the height of a weight on the weight axis, from 0 at the bottom to 1 at the top
+func (d ChartData) Level(weight float64) float64 {
+	if d.Max == d.Min {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (weight-d.Min)/(d.Max-d.Min)))
+}
+
+// Row maps a weight onto one of the given number of rows, counted from the top
+func (d ChartData) Row(weight float64, rows int) int {
+	return rows - 1 - int(math.Round(d.Level(weight)*float64(rows-1)))
+}
+
+// chartColumn collects the weights that fall into one column of a plot
+type chartColumn struct {
+	count     int
+	sum       float64
+	min, max  float64
+	first     float64 // oldest weight in the column
+	last      float64 // newest weight in the column
+	gapBefore bool    // the column's first entry is a gap after the previous entry
+}
+
+// mean returns the average weight in the column
+func (c *chartColumn) mean() float64 {
+	return c.sum / float64(c.count)
+}
+
+// bucketColumns groups the points into the given number of columns.
+// Columns without entries are nil.
+func bucketColumns(data ChartData, columns int) []*chartColumn {
+	buckets := make([]*chartColumn, columns)
+	for _, p := range data.Points {
+		x := data.Column(p.At, columns)
+		col := buckets[x]
+		if col == nil {
+			col = &chartColumn{min: p.Weight, max: p.Weight, first: p.Weight, gapBefore: p.GapBefore}
+			buckets[x] = col
+		}
+		col.count++
+		col.sum += p.Weight
+		col.min = math.Min(col.min, p.Weight)
+		col.max = math.Max(col.max, p.Weight)
+		col.last = p.Weight
+	}
+	return buckets
+}
+
+// gapThreshold returns the interval between entries above which it is drawn as a gap
+func gapThreshold(points []ChartPoint) time.Duration {
+	var intervals []time.Duration
+	for i := 1; i < len(points); i++ {
+		if d := points[i].At.Sub(points[i-1].At); d > 0 {
+			intervals = append(intervals, d)
+		}
+	}
+	if len(intervals) == 0 {
+		return gapMinInterval
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	threshold := intervals[len(intervals)/2] * gapMedianFactor
+	if threshold < gapMinInterval {
+		threshold = gapMinInterval
+	}
+	return threshold
+}
+
+// newPlotGrid returns an empty grid of width × height cells
+func newPlotGrid(width, height int) [][]rune {
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", width))
+	}
+	return grid
+}
+
+// drawGoalLines draws the BMI cut-off lines, the milestone lines and then the
+// goal line, so the goal's wins where they share a row. A goal range is drawn
+// as a band of rows.
+func drawGoalLines(grid [][]rune, data ChartData, goal, milestone, band, cutoff rune) {
+	for _, c := range data.BMICutoffs {
+		drawGoalLine(grid, data.Row(c.Weight, len(grid)), cutoff)
+	}
+	for _, m := range data.Milestones {
+		drawGoalLine(grid, data.Row(m, len(grid)), milestone)
+	}
+	if !data.HasGoalRange() {
+		drawGoalLine(grid, data.Row(data.Goal, len(grid)), goal)
+		return
+	}
+	for row := data.Row(data.GoalHigh, len(grid)); row <= data.Row(data.GoalLow, len(grid)); row++ {
+		drawGoalLine(grid, row, band)
+	}
+}
+
+// drawGoalLine draws a horizontal line across a grid row
+func drawGoalLine(grid [][]rune, row int, glyph rune) {
+	if row < 0 || row >= len(grid) {
+		return
+	}
+	for x := range grid[row] {
+		grid[row][x] = glyph
+	}
+}
+
+// drawLine calls plot for each point on the line between two points, using
+// Bresenham's algorithm. The end point is left out.
+func drawLine(x0, y0, x1, y1 int, plot func(x, y int)) {
+	dx := abs(x1 - x0)
+	dy := abs(y1 - y0)
+	sx := -1
+	if x0 < x1 {
+		sx = 1
+	}
+	sy := -1
+	if y0 < y1 {
+		sy = 1
+	}
+	err := dx - dy
+
+	for x0 != x1 || y0 != y1 {
+		plot(x0, y0)
+
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x0 += sx
+		}
+		if e2 < dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// abs returns the absolute value of an integer
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -13,9 +13,22 @@ const (
 	// GraphHeight is the height of the ASCII graph in characters
 	GraphHeight = 20
 
+	// GraphMinWidth is the narrowest graph drawn beside the table; narrower
+	// terminals get the graph below the table
+	GraphMinWidth = 30
+
+	// GraphMinHeight is the lowest graph drawn when sizing to the terminal
+	GraphMinHeight = 8
+
+	// TableMinRows is the fewest table rows shown when sizing to the terminal
+	TableMinRows = 5
+
 	// GoalHeaderWidth is the width for centering the goal header
 	GoalHeaderWidth = 80
 
+	// NoteMaxWidth is the maximum number of note characters shown in the table
+	NoteMaxWidth = 20
+
 	// GoalLabelMinWidth is the minimum width for goal label padding
 	GoalLabelMinWidth = 8
 )
@@ -1,10 +1,119 @@
 package display
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
 
-// FormatWeight formats a weight value with proper precision and unit
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// FormatWeight formats a weight value with the default precision and unit.
+// Weights in stones are shown as stones and pounds, e.g. "11 st 4.5 lb".
 func FormatWeight(weight float64, unit string) string {
-	return fmt.Sprintf("%.2f %s", weight, unit)
+	return FormatWeightPrecision(weight, unit, models.DefaultPrecision(unit))
+}
+
+// FormatWeightPrecision formats a weight value with the given number of
+// decimals and unit. For stones the decimals apply to the pounds.
+func FormatWeightPrecision(weight float64, unit string, decimals int) string {
+	if unit == "st" {
+		return formatStones(weight, decimals, " st ", " lb")
+	}
+	return fmt.Sprintf("%.*f %s", decimals, weight, unit)
+}
+
+// FormatWeightFor formats a weight in the user's unit and precision
+func FormatWeightFor(weight float64, settings *models.Settings) string {
+	return FormatWeightPrecision(weight, settings.WeightUnit, settings.WeightDecimals())
+}
+
+// FormatWeightShort formats a weight compactly for labels, e.g. "154.3 lbs" or "11st 4lb"
+func FormatWeightShort(weight float64, unit string) string {
+	if unit == "st" {
+		return FormatWeightShortPrecision(weight, unit, 0)
+	}
+	return FormatWeightShortPrecision(weight, unit, 1)
+}
+
+// FormatWeightShortPrecision formats a weight compactly for labels with the
+// given number of decimals, e.g. "154.30 lbs" or "11st 4.5lb"
+func FormatWeightShortPrecision(weight float64, unit string, decimals int) string {
+	if unit == "st" {
+		return formatStones(weight, decimals, "st ", "lb")
+	}
+	return fmt.Sprintf("%.*f %s", decimals, weight, unit)
+}
+
+// formatStones formats a weight in stones as whole stones and pounds with the
+// given number of decimals, carrying pounds that round up to a full stone
+func formatStones(stones float64, decimals int, stoneSuffix, poundSuffix string) string {
+	sign := ""
+	if stones < 0 {
+		sign = "-"
+		stones = -stones
+	}
+
+	whole, pounds := calculator.SplitStones(stones)
+	scale := math.Pow(10, float64(decimals))
+	pounds = math.Round(pounds*scale) / scale
+	if pounds >= calculator.LbsPerSt {
+		whole++
+		pounds -= calculator.LbsPerSt
+	}
+
+	// Leave out zero stones for small differences, e.g. "1.5 lb"
+	if whole == 0 {
+		return fmt.Sprintf("%s%.*f%s", sign, decimals, pounds, poundSuffix)
+	}
+	return fmt.Sprintf("%s%d%s%.*f%s", sign, whole, stoneSuffix, decimals, pounds, poundSuffix)
+}
+
+// FormatWeightChange formats a change in weight with its sign, e.g. "-1.20 kg"
+func FormatWeightChange(change float64, settings *models.Settings) string {
+	switch {
+	case change > 0:
+		return "+" + FormatWeightFor(change, settings)
+	case change < 0:
+		return "-" + FormatWeightFor(-change, settings)
+	}
+	return FormatWeightFor(0, settings)
+}
+
+// FormatGoal formats the goal weight, or the goal range, e.g. "143.00 lbs - 147.00 lbs"
+func FormatGoal(settings *models.Settings) string {
+	if settings.HasGoalRange() {
+		return FormatWeightFor(settings.GoalLow, settings) + " - " + FormatWeightFor(settings.GoalHigh, settings)
+	}
+	return FormatWeightFor(settings.GoalWeight, settings)
+}
+
+// FormatGoalDifference describes how far a weight is from the goal weight,
+// e.g. "12.50 lbs to lose", or whether it is within the goal range, e.g.
+// "in range" or "above by 1.20 lbs"
+func FormatGoalDifference(weight float64, settings *models.Settings) string {
+	if settings.HasGoalRange() {
+		switch {
+		case weight > settings.GoalHigh:
+			return "above by " + FormatWeightFor(weight-settings.GoalHigh, settings)
+		case weight < settings.GoalLow:
+			return "below by " + FormatWeightFor(settings.GoalLow-weight, settings)
+		}
+		return "in range"
+	}
+
+	goalDiff := weight - settings.GoalWeight
+	if goalDiff > 0 {
+		// Current weight is above goal - need to lose
+		return fmt.Sprintf("%s to lose", FormatWeightFor(goalDiff, settings))
+	} else if goalDiff < 0 {
+		// Current weight is below goal - need to gain
+		return fmt.Sprintf("%s to gain", FormatWeightFor(math.Abs(goalDiff), settings))
+	}
+	return "at goal!"
 }
 
 // FormatBMI formats a BMI value with proper precision
@@ -12,7 +121,79 @@ func FormatBMI(bmi float64) string {
 	return fmt.Sprintf("%.1f", bmi)
 }
 
+// FormatBMIDetails formats a BMI on a date with its category in the user's
+// BMI standard and its BMI Prime, e.g. "24.9 (Normal, Prime 1.00)". For
+// children it shows the BMI-for-age percentile and category instead, e.g.
+// "17.2 (62nd percentile, Healthy weight)".
+func FormatBMIDetails(bmi float64, date string, settings *models.Settings) string {
+	if child, ok := analytics.ChildBMI(bmi, date, settings); ok {
+		if !child.Known {
+			return FormatBMI(bmi) + " (no BMI-for-age reference for this age)"
+		}
+		return fmt.Sprintf("%s (%s percentile, %s)", FormatBMI(bmi), ordinal(int(child.Percentile)), child.Category.Name)
+	}
+	standard := settings.BMICutoffs()
+	return fmt.Sprintf("%s (%s, Prime %.2f)", FormatBMI(bmi), standard.Category(bmi).Name, standard.Prime(bmi))
+}
+
+// RenderBMI formats a BMI on a date colored by its category in the user's
+// BMI standard, or its BMI-for-age category for children
+func RenderBMI(bmi float64, date string, settings *models.Settings) string {
+	category := settings.BMICutoffs().Category(bmi)
+	if child, ok := analytics.ChildBMI(bmi, date, settings); ok {
+		category = child.Category
+	}
+	style, ok := BMICategoryStyles[category.Name]
+	if !ok {
+		return FormatBMI(bmi)
+	}
+	return style.Render(FormatBMI(bmi))
+}
+
+// ordinal formats a number as an ordinal, e.g. 1st, 22nd or 85th
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
 // FormatDate returns the date as-is (already in YYYY-MM-DD format)
 func FormatDate(date string) string {
 	return date
 }
+
+// FormatDateTime returns the date followed by the time of day when one is recorded
+func FormatDateTime(date, timeOfDay string) string {
+	if timeOfDay == "" {
+		return FormatDate(date)
+	}
+	return FormatDate(date) + " " + timeOfDay
+}
+
+// FormatNote shortens a note to NoteMaxWidth characters for table display
+func FormatNote(note string) string {
+	if utf8.RuneCountInString(note) <= NoteMaxWidth {
+		return note
+	}
+	runes := []rune(note)
+	return string(runes[:NoteMaxWidth-1]) + "…"
+}
+
+// FormatTimestamp formats a stored UTC timestamp in local time, without seconds.
+// Timestamps that can't be parsed are returned as-is.
+func FormatTimestamp(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,147 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// RenderActiveGoal describes the active goal and lists it with its milestones,
+// showing how far the latest of the weights, stored newest first, is from
+// each. Milestones are in the order they are reached. For a range goal it
+// also shows how many days with entries since it was set were within the range.
+func RenderActiveGoal(goal models.Goal, milestones []models.Goal, weights []models.Weight, settings *models.Settings) string {
+	var output strings.Builder
+	output.WriteString(HeaderStyle.Render("Goal: " + FormatGoalTarget(goal, settings)))
+	output.WriteString("\n")
+	output.WriteString(InfoStyle.Render(describeGoal(goal, settings)))
+	if goal.IsRange() {
+		in, total := analytics.DaysInRange(analytics.Days(weights), goal.StartDate, goal.RangeLow, goal.RangeHigh)
+		if total > 0 {
+			output.WriteString("\n")
+			output.WriteString(InfoStyle.Render(fmt.Sprintf("In range on %d of %s since it was set (%d%%)", in, plural(total, "day"), in*100/total)))
+		}
+	}
+	output.WriteString("\n\n")
+
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(TableBorderStyle).
+		Headers("", "Target", "Progress", "To go", "Status")
+
+	for _, g := range append(milestones, goal) {
+		kind := "Goal"
+		if g.IsMilestone() {
+			kind = "Milestone"
+		}
+		progress, toGo := "-", "-"
+		if len(weights) > 0 && g.StartWeight != 0 && g.Status == models.GoalActive {
+			latest := weights[0].Weight
+			target := g.TargetFrom(latest)
+			progress = fmt.Sprintf("%.0f%%", analytics.GoalProgress(g.StartWeight, latest, target)*100)
+			toGo = FormatWeightFor(math.Abs(latest-target), settings)
+		}
+		t.Row(kind, FormatGoalTarget(g, settings), progress, toGo, formatGoalStatus(g))
+	}
+
+	output.WriteString(t.Render())
+	return output.String()
+}
+
+// RenderGoalHistory creates a table of goals, newest first, with how many of
+// their milestones were reached
+func RenderGoalHistory(goals []models.Goal, milestones map[int][]models.Goal, settings *models.Settings) string {
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(TableBorderStyle).
+		Headers("ID", "Set", "Target", "Start Weight", "Deadline", "Milestones", "Status")
+
+	for _, g := range goals {
+		start, deadline, reached := "-", "-", 0
+		if g.StartWeight != 0 {
+			start = FormatWeightFor(g.StartWeight, settings)
+		}
+		if g.Deadline != "" {
+			deadline = FormatDate(g.Deadline)
+		}
+		for _, m := range milestones[g.ID] {
+			if m.Status == models.GoalAchieved {
+				reached++
+			}
+		}
+		t.Row(
+			fmt.Sprintf("%d", g.ID),
+			FormatDate(g.StartDate),
+			FormatGoalTarget(g, settings),
+			start,
+			deadline,
+			fmt.Sprintf("%d/%d", reached, len(milestones[g.ID])),
+			formatGoalStatus(g),
+		)
+	}
+
+	return t.Render()
+}
+
+// RenderGoalSuggestions creates a table of suggested goals with the BMI at
+// each, how far the latest weight is from it and the command to adopt it.
+// Without entries the latest weight is zero and nothing is shown to go.
+func RenderGoalSuggestions(suggestions []analytics.GoalSuggestion, latest float64, settings *models.Settings) string {
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(TableBorderStyle).
+		Headers("Suggestion", "Weight", "BMI", "To go", "Adopt with")
+
+	for _, s := range suggestions {
+		weight := FormatWeightFor(s.Low, settings)
+		bmi := FormatBMI(calculator.CalculateBMI(s.Low, settings.Height, settings.WeightUnit, settings.HeightUnit))
+		if s.IsRange() {
+			weight += " - " + FormatWeightFor(s.High, settings)
+			bmi += " - " + FormatBMI(calculator.CalculateBMI(s.High, settings.Height, settings.WeightUnit, settings.HeightUnit))
+		}
+		toGo := "-"
+		if latest != 0 {
+			toGo = FormatWeightFor(math.Abs(latest-math.Min(math.Max(latest, s.Low), s.High)), settings)
+		}
+		t.Row(s.Name, weight, bmi, toGo, "thicc goal suggest "+s.Key)
+	}
+
+	return t.Render()
+}
+
+// FormatGoalTarget formats a goal's target, or its range, e.g. "143.00 lbs - 147.00 lbs"
+func FormatGoalTarget(g models.Goal, settings *models.Settings) string {
+	if g.IsRange() {
+		return FormatWeightFor(g.RangeLow, settings) + " - " + FormatWeightFor(g.RangeHigh, settings)
+	}
+	return FormatWeightFor(g.Target, settings)
+}
+
+// describeGoal says when a goal was set, from what weight, and its deadline
+func describeGoal(g models.Goal, settings *models.Settings) string {
+	text := "Set on " + FormatDate(g.StartDate)
+	if g.StartWeight != 0 {
+		text += " at " + FormatWeightFor(g.StartWeight, settings)
+	}
+	if g.Deadline != "" {
+		text += ", to reach by " + FormatDate(g.Deadline)
+	}
+	return text
+}
+
+// formatGoalStatus shows a goal's status with the date it ended, e.g. "Achieved Jul 04, 2026"
+func formatGoalStatus(g models.Goal) string {
+	switch g.Status {
+	case models.GoalAchieved:
+		return "Achieved " + FormatDate(g.EndDate)
+	case models.GoalAbandoned:
+		return "Abandoned " + FormatDate(g.EndDate)
+	}
+	return "Active"
+}
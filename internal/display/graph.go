@@ -0,0 +1,268 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// Glyphs of the goal, milestone and BMI cut-off lines and goal range band in Unicode plots
+const (
+	goalGlyph      = '─'
+	milestoneGlyph = '┈'
+	goalRangeGlyph = '░'
+	bmiCutoffGlyph = '·'
+)
+
+const (
+	// graphFrameWidth is the width the y-axis, padding and border add to the
+	// weight labels and plot
+	graphFrameWidth = 5
+
+	// graphFrameHeight is the height the x-axis, date labels, legend and border add to the plot
+	graphFrameHeight = 5
+)
+
+// graphFrame holds the glyphs of the axes and border drawn around a plot
+type graphFrame struct {
+	axis      rune // y-axis
+	labelTick rune // y-axis next to a weight label
+	corner    rune // where the axes meet
+	baseline  rune // x-axis
+	tick      rune // x-axis date tick
+	gap       rune // x-axis stretch without entries
+	goalRange rune // the band of a goal range in the plot
+	bmiCutoff rune // lines where BMI categories start in the plot
+	border    lipgloss.Border
+}
+
+var (
+	unicodeFrame = graphFrame{'│', '┤', '└', '─', '┬', '╌', goalRangeGlyph, bmiCutoffGlyph, lipgloss.NormalBorder()}
+	asciiFrame   = graphFrame{'|', '+', '+', '-', '+', '~', asciiGoalRange, asciiBMICutoff, lipgloss.ASCIIBorder()}
+)
+
+// weightRange holds the min and max weight values for graph scaling
+type weightRange struct {
+	min float64
+	max float64
+}
+
+// createLineGraph creates a line graph of weight over time with a plot of the
+// given size, drawn by the renderer, optionally with lines where BMI categories start
+func createLineGraph(weights []models.Weight, settings *models.Settings, width, height int, renderer ChartRenderer, bmiCutoffs bool) string {
+	data := PrepareChartFor(weights, settings)
+	if bmiCutoffs {
+		data = data.WithBMICutoffs(settings)
+	}
+	if len(data.Points) == 0 {
+		return ""
+	}
+
+	plot := renderer.Plot(data, width, height)
+	return renderGraphWithLabels(plot, data, frameFor(renderer), renderer.Legend(data, width), settings)
+}
+
+// frameFor returns the frame glyphs to draw around a renderer's plot
+func frameFor(renderer ChartRenderer) graphFrame {
+	if renderer.Unicode() {
+		return unicodeFrame
+	}
+	return asciiFrame
+}
+
+// calculateWeightRange determines the min and max weights including goal
+// weight, milestones and padding
+func calculateWeightRange(weights []models.Weight, goalWeight float64, milestones ...float64) weightRange {
+	minWeight := math.MaxFloat64
+	maxWeight := -math.MaxFloat64
+
+	for _, w := range weights {
+		if w.Weight < minWeight {
+			minWeight = w.Weight
+		}
+		if w.Weight > maxWeight {
+			maxWeight = w.Weight
+		}
+	}
+
+	// Include goal weight and milestones in range calculation
+	for _, target := range append([]float64{goalWeight}, milestones...) {
+		minWeight = math.Min(minWeight, target)
+		maxWeight = math.Max(maxWeight, target)
+	}
+
+	// Add some padding to the range
+	padding := (maxWeight - minWeight) * 0.1
+	if padding == 0 {
+		padding = 1
+	}
+	minWeight -= padding
+	maxWeight += padding
+
+	return weightRange{min: minWeight, max: maxWeight}
+}
+
+// reverseWeights returns a reversed copy of the weights slice (oldest to newest)
+func reverseWeights(weights []models.Weight) []models.Weight {
+	reversed := make([]models.Weight, len(weights))
+	copy(reversed, weights)
+	for i := 0; i < len(reversed)/2; i++ {
+		reversed[i], reversed[len(reversed)-1-i] = reversed[len(reversed)-1-i], reversed[i]
+	}
+	return reversed
+}
+
+// renderGraphWithLabels renders the plot with weight labels, a time axis with
+// date ticks, a legend and styling
+func renderGraphWithLabels(plot [][]rune, data ChartData, frame graphFrame, legend []string, settings *models.Settings) string {
+	height := len(plot)
+	width := len(plot[0])
+
+	// Weight labels on the top, bottom, BMI cut-off, milestone and goal rows,
+	// or the top and bottom rows of a goal range
+	labels := make([]string, height)
+	labels[0] = formatGraphValue(data.Max, settings)
+	labels[height-1] = formatGraphValue(data.Min, settings)
+	for _, c := range data.BMICutoffs {
+		labels[data.Row(c.Weight, height)] = "BMI " + FormatBMI(c.BMI)
+	}
+	for _, m := range data.Milestones {
+		labels[data.Row(m, height)] = milestoneLabel(m, settings)
+	}
+	if data.HasGoalRange() {
+		labels[data.Row(data.GoalLow, height)] = "Goal: " + formatGraphValue(data.GoalLow, settings)
+		labels[data.Row(data.GoalHigh, height)] = "Goal: " + formatGraphValue(data.GoalHigh, settings)
+	} else {
+		labels[data.Row(data.Goal, height)] = "Goal: " + formatGraphValue(data.Goal, settings)
+	}
+
+	labelWidth := graphLabelWidth(weightRange{min: data.Min, max: data.Max}, settings)
+
+	var graphLines strings.Builder
+	for i, row := range plot {
+		tick := frame.axis
+		if labels[i] != "" {
+			tick = frame.labelTick
+		}
+		graphLines.WriteString(padLeft(labels[i], labelWidth) + string(tick) + string(row) + "\n")
+	}
+
+	axisLine, tickLabels, hasGap := renderTimeAxis(data, frame, width)
+	graphLines.WriteString(strings.Repeat(" ", labelWidth) + string(frame.corner) + axisLine + "\n")
+	graphLines.WriteString(strings.Repeat(" ", labelWidth+1) + tickLabels)
+
+	if hasGap {
+		legend = append(legend, string(frame.gap)+" no entries")
+	}
+	if data.HasGoalRange() {
+		legend = append(legend, string(frame.goalRange)+" goal range")
+	}
+	if len(data.BMICutoffs) > 0 {
+		legend = append(legend, string(frame.bmiCutoff)+" BMI categories")
+	}
+	if len(legend) > 0 {
+		graphLines.WriteString("\n" + strings.Repeat(" ", labelWidth+1) + strings.Join(legend, "  "))
+	}
+
+	graphStyle := lipgloss.NewStyle().
+		Border(frame.border).
+		BorderForeground(lipgloss.Color("8")).
+		Padding(0, 1)
+
+	return graphStyle.Render(graphLines.String())
+}
+
+// graphLabelWidth returns the width of the graph's weight labels
+func graphLabelWidth(wr weightRange, settings *models.Settings) int {
+	width := GoalLabelMinWidth
+	labels := []string{
+		formatGraphValue(wr.max, settings),
+		formatGraphValue(wr.min, settings),
+		"Goal: " + formatGraphValue(settings.GoalWeight, settings),
+		"Goal: " + formatGraphValue(settings.GoalHigh, settings),
+	}
+	for _, m := range settings.Milestones {
+		labels = append(labels, milestoneLabel(m, settings))
+	}
+	for _, label := range labels {
+		width = max(width, utf8.RuneCountInString(label))
+	}
+	return width
+}
+
+// milestoneLabel labels a milestone line on the graph
+func milestoneLabel(weight float64, settings *models.Settings) string {
+	return "Milestone: " + formatGraphValue(weight, settings)
+}
+
+// renderTimeAxis renders the x-axis line, with gaps and date ticks, and the
+// line of date labels under it. It also reports whether the axis shows a gap.
+func renderTimeAxis(data ChartData, frame graphFrame, width int) (string, string, bool) {
+	line := []rune(strings.Repeat(string(frame.baseline), width))
+
+	// Mark the columns between two entries separated by a gap
+	hasGap := false
+	for i := 1; i < len(data.Points); i++ {
+		if !data.Points[i].GapBefore {
+			continue
+		}
+		hasGap = true
+		for x := data.Column(data.Points[i-1].At, width) + 1; x < data.Column(data.Points[i].At, width); x++ {
+			line[x] = frame.gap
+		}
+	}
+
+	// Spread date ticks evenly, as many as fit without their labels touching
+	span := data.End().Sub(data.Start())
+	layout := "Jan 02"
+	if span > 180*24*time.Hour {
+		layout = "Jan 2006"
+	}
+	labelWidth := len(layout)
+	if width < labelWidth {
+		return string(line), "", hasGap
+	}
+	ticks := max(2, (width+2)/(labelWidth+2))
+	ticks = min(ticks, int(span.Hours()/24)+1)
+
+	labelLine := []rune(strings.Repeat(" ", width))
+	nextFree := 0
+	for i := 0; i < ticks; i++ {
+		x := 0
+		if ticks > 1 {
+			x = int(math.Round(float64(i) * float64(width-1) / float64(ticks-1)))
+		}
+		label := data.TimeAt(x, width).Format(layout)
+		start := min(max(x-labelWidth/2, 0), width-labelWidth)
+		if start < nextFree {
+			continue
+		}
+		line[x] = frame.tick
+		copy(labelLine[start:], []rune(label))
+		nextFree = start + labelWidth + 1
+	}
+
+	return string(line), strings.TrimRight(string(labelLine), " "), hasGap
+}
+
+// padLeft right-aligns text in a field of the given width
+func padLeft(text string, width int) string {
+	if n := utf8.RuneCountInString(text); n < width {
+		return strings.Repeat(" ", width-n) + text
+	}
+	return text
+}
+
+// formatGraphValue formats a weight for a graph label in the user's precision
+// without the unit, except for stones where the unit separates stones from pounds
+func formatGraphValue(weight float64, settings *models.Settings) string {
+	if settings.WeightUnit == "st" {
+		return formatStones(weight, settings.WeightDecimals(), "st ", "lb")
+	}
+	return fmt.Sprintf("%.*f", settings.WeightDecimals(), weight)
+}
@@ -0,0 +1,64 @@
+package display
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// RenderGrowthTable creates a table of entries, newest first, with the age and
+// BMI-for-age percentile, z-score and category of each made before age 20.
+// Entries without a reference for the age show dashes.
+func RenderGrowthTable(weights []models.Weight, settings *models.Settings) string {
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(TableBorderStyle).
+		Headers("ID", "Date", "Age", "Weight", "BMI", "Percentile", "Z-score", "Category")
+
+	for _, w := range weights {
+		child, ok := analytics.ChildBMI(w.BMI, w.Date, settings)
+		if !ok {
+			continue
+		}
+		percentile, z, category := "-", "-", "-"
+		if child.Known {
+			percentile = ordinal(int(child.Percentile))
+			z = fmt.Sprintf("%+.2f", child.ZScore)
+			category = child.Category.Name
+		}
+		t.Row(
+			fmt.Sprintf("%d", w.ID),
+			FormatDateTime(w.Date, w.Time),
+			formatAge(settings.BirthDate, w.Date),
+			FormatWeightFor(w.Weight, settings),
+			RenderBMI(w.BMI, w.Date, settings),
+			percentile,
+			z,
+			category,
+		)
+	}
+
+	return t.Render()
+}
+
+// formatAge formats the age on a YYYY-MM-DD date in whole years and months
+// since a birth date, e.g. "9y 4m"
+func formatAge(birthDate, date string) string {
+	birth, err := time.Parse("2006-01-02", birthDate)
+	if err != nil {
+		return "-"
+	}
+	on, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "-"
+	}
+	months := (on.Year()-birth.Year())*12 + int(on.Month()-birth.Month())
+	if on.Day() < birth.Day() {
+		months--
+	}
+	return fmt.Sprintf("%dy %dm", months/12, months%12)
+}
@@ -0,0 +1,72 @@
+package display
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// RenderOperationsTable creates a table of journaled operations for the history command
+func RenderOperationsTable(ops []models.Operation) string {
+	if len(ops) == 0 {
+		return "No changes recorded yet."
+	}
+
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(TableBorderStyle).
+		Headers("#", "When", "Command", "Change", "Status")
+
+	for _, op := range ops {
+		status := "done"
+		if op.Undone {
+			status = "undone"
+		}
+		t.Row(
+			fmt.Sprintf("%d", op.ID),
+			FormatTimestamp(op.CreatedAt),
+			op.Command,
+			op.Summary,
+			status,
+		)
+	}
+
+	return t.Render()
+}
+
+// RenderTrashTable creates a table of trashed weight entries
+func RenderTrashTable(weights []models.Weight, settings *models.Settings) string {
+	if len(weights) == 0 {
+		return "The trash is empty."
+	}
+
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(TableBorderStyle).
+		Headers("ID", "Date", "Weight", "BMI", "Deleted")
+
+	for _, w := range weights {
+		t.Row(
+			fmt.Sprintf("%d", w.ID),
+			FormatDateTime(w.Date, w.Time),
+			FormatWeightFor(w.Weight, settings),
+			FormatBMI(w.BMI),
+			FormatTimestamp(w.DeletedAt),
+		)
+	}
+
+	return t.Render()
+}
+
+// RenderConfigTable creates a table of preferences from key, value and description rows
+func RenderConfigTable(rows [][]string) string {
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(TableBorderStyle).
+		Headers("Key", "Value", "Description").
+		Rows(rows...)
+
+	return t.Render()
+}
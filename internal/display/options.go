@@ -0,0 +1,75 @@
+package display
+
+import "github.com/tryonlinux/thicc/internal/models"
+
+// Options control how the weights table and graph are rendered
+type Options struct {
+	// Width and Height are the space available to render in, in terminal
+	// cells. A zero width or height uses the fixed default sizes.
+	Width  int
+	Height int
+
+	// Chart draws the graph's plot. Nil uses the braille renderer.
+	Chart ChartRenderer
+
+	// HideGraph leaves out the graph, e.g. when it is drawn as an image instead
+	HideGraph bool
+
+	// BMICutoffs draws lines on the graph where the BMI categories start
+	BMICutoffs bool
+
+	// Today is the YYYY-MM-DD date the goal's deadline is counted from.
+	// Empty uses the current date.
+	Today string
+}
+
+// graphSize returns the plot size of the graph drawn next to or below a
+// table of the given width, and whether it goes below the table. Label width
+// is the width of the graph's weight labels.
+func (o Options) graphSize(tableWidth, labelWidth, bodyHeight int) (width, height int, stacked bool) {
+	width, height = GraphWidth, GraphHeight
+
+	if o.Width > 0 {
+		frame := labelWidth + graphFrameWidth
+		width = o.Width - tableWidth - len(tableGraphGap) - frame
+		if width < GraphMinWidth {
+			// Not enough room beside the table, so use the full width below it
+			stacked = true
+			width = max(o.Width-frame, 1)
+		}
+	}
+
+	if o.Height > 0 {
+		height = max(bodyHeight-graphFrameHeight, GraphMinHeight)
+		if stacked {
+			// The table takes its own rows, so keep the graph to the default size
+			height = min(height, GraphHeight)
+		}
+	}
+
+	return width, height, stacked
+}
+
+// tableRows returns the number of entries shown in the table
+func (o Options) tableRows(bodyHeight int) int {
+	if o.Height <= 0 {
+		return TableMaxRows
+	}
+	return max(bodyHeight-tableFrameHeight, TableMinRows)
+}
+
+// today returns the date the goal's deadline is counted from
+func (o Options) today() string {
+	if o.Today == "" {
+		return models.GetTodayDate()
+	}
+	return o.Today
+}
+
+// chart returns the chart renderer to draw the graph with
+func (o Options) chart() ChartRenderer {
+	if o.Chart == nil {
+		return brailleRenderer{}
+	}
+	return o.Chart
+}
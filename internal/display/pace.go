@@ -0,0 +1,51 @@
+package display
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// FormatGoalPace describes the change per week needed to reach the goal by
+// its deadline and whether the trend is on track, e.g. "By 2026-12-31
+// (10 weeks left): -1.50 lbs/week needed | Trend: -1.80 lbs/week, on track".
+// It returns "" when the goal has no deadline.
+func FormatGoalPace(pace analytics.GoalPace, settings *models.Settings) string {
+	switch {
+	case pace.Deadline == "":
+		return ""
+	case pace.WeeksLeft <= 0:
+		return fmt.Sprintf("The deadline of %s has passed", FormatDate(pace.Deadline))
+	case pace.Required == 0:
+		return fmt.Sprintf("Goal reached ahead of the deadline of %s", FormatDate(pace.Deadline))
+	}
+
+	status := "behind"
+	if pace.OnTrack {
+		status = "on track"
+	}
+	return fmt.Sprintf("By %s (%s left): %s/week needed | Trend: %s/week, %s",
+		FormatDate(pace.Deadline),
+		plural(int(math.Round(pace.WeeksLeft)), "week"),
+		FormatWeightChange(pace.Required, settings),
+		FormatWeightChange(pace.Trend, settings),
+		status)
+}
+
+// GoalWarnings returns warnings about a pace faster than is safe and a goal
+// weight below a healthy BMI
+func GoalWarnings(pace analytics.GoalPace, settings *models.Settings) []string {
+	var warnings []string
+	if pace.TooFast {
+		warnings = append(warnings, fmt.Sprintf("Warning: %s per week is more than %.0f%% of your body weight, faster than is safe",
+			FormatWeightFor(math.Abs(pace.Required), settings), analytics.SafeWeeklyChange*100))
+	}
+	if pace.Underweight {
+		warnings = append(warnings, fmt.Sprintf("Warning: a goal of %s is a BMI of %s, below the healthy minimum of %.1f",
+			FormatWeightFor(settings.GoalFrom(0), settings), FormatBMI(pace.GoalBMI), calculator.HealthyBMIMin))
+	}
+	return warnings
+}
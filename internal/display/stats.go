@@ -0,0 +1,137 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+const (
+	// histogramBarMaxWidth is the longest a histogram bar gets, in cells
+	histogramBarMaxWidth = 40
+
+	// histogramBarMinWidth is the shortest the longest bar gets on narrow terminals
+	histogramBarMinWidth = 5
+)
+
+// RenderStats creates tables of the distribution of weights, their changes
+// from day to day and their average on each weekday, and a histogram of weights
+func RenderStats(stats analytics.Stats, settings *models.Settings, opts Options) string {
+	if stats.Count == 0 {
+		return "No weights tracked. Add one with: thicc add <weight> [date]"
+	}
+
+	border := lipgloss.NormalBorder()
+	if !opts.chart().Unicode() {
+		border = lipgloss.ASCIIBorder()
+	}
+	newTable := func(headers ...string) *table.Table {
+		return table.New().Border(border).BorderStyle(TableBorderStyle).Headers(headers...)
+	}
+
+	heading := fmt.Sprintf("%d entries from %s to %s", stats.Count, stats.From, stats.To)
+
+	distribution := newTable("Distribution", "Weight")
+	distribution.Row("Mean", FormatWeightFor(stats.Mean, settings))
+	distribution.Row("Median", FormatWeightFor(stats.Median, settings))
+	distribution.Row("Standard deviation", FormatWeightFor(stats.StdDev, settings))
+	distribution.Row("Min", FormatWeightFor(stats.Min, settings))
+	for i, p := range analytics.StatsPercentiles {
+		distribution.Row(ordinal(int(p))+" percentile", FormatWeightFor(stats.Percentiles[i], settings))
+	}
+	distribution.Row("Max", FormatWeightFor(stats.Max, settings))
+
+	daily := newTable("Day to day", "Change", "When")
+	fluctuation, gain, loss := "-", dayChange(stats.LargestGain, settings), dayChange(stats.LargestLoss, settings)
+	if stats.DailyFluctuation > 0 {
+		fluctuation = FormatWeightFor(stats.DailyFluctuation, settings)
+	}
+	daily.Row("Average fluctuation", fluctuation, "")
+	daily.Row("Largest gain", gain, stats.LargestGain.Date)
+	daily.Row("Largest loss", loss, stats.LargestLoss.Date)
+	daily.Row("Longest losing streak", streakChange(stats.LosingStreak, settings), streakDays(stats.LosingStreak, "loss", "losses"))
+	daily.Row("Longest gaining streak", streakChange(stats.GainingStreak, settings), streakDays(stats.GainingStreak, "gain", "gains"))
+
+	weekdays := newTable("Weekday", "Days", "Average", "vs mean")
+	for _, w := range stats.Weekdays {
+		weekdays.Row(w.Weekday.String(), fmt.Sprintf("%d", w.Days), FormatWeightFor(w.Mean, settings), FormatWeightChange(w.Mean-stats.Mean, settings))
+	}
+
+	sections := []string{
+		InfoStyle.Render(heading),
+		lipgloss.JoinHorizontal(lipgloss.Top, distribution.Render(), "  ", weekdays.Render()),
+		daily.Render(),
+		renderHistogram(stats.Histogram, settings, opts),
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// dayChange formats the change of a day, or "-" without one
+func dayChange(change analytics.DayChange, settings *models.Settings) string {
+	if change.Date == "" {
+		return "-"
+	}
+	return FormatWeightChange(change.Change, settings)
+}
+
+// streakChange formats the total change over a streak, or "-" without one
+func streakChange(streak analytics.Streak, settings *models.Settings) string {
+	if streak.Days == 0 {
+		return "-"
+	}
+	return FormatWeightChange(streak.Change, settings)
+}
+
+// streakDays describes how many changes the same way a streak ran for and when
+func streakDays(streak analytics.Streak, one, many string) string {
+	switch streak.Days {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("1 %s, %s to %s", one, streak.From, streak.To)
+	}
+	return fmt.Sprintf("%d %s in a row, %s to %s", streak.Days, many, streak.From, streak.To)
+}
+
+// renderHistogram draws a horizontal bar for the number of entries in each
+// bin of weights, heaviest bin at the top
+func renderHistogram(bins []analytics.Bin, settings *models.Settings, opts Options) string {
+	bar := "█"
+	if !opts.chart().Unicode() {
+		bar = "#"
+	}
+
+	labels := make([]string, len(bins))
+	labelWidth, largest, countWidth := 0, 0, 0
+	for i, b := range bins {
+		labels[i] = formatGraphValue(b.Low, settings) + " - " + formatGraphValue(b.High, settings)
+		labelWidth = max(labelWidth, lipgloss.Width(labels[i]))
+		largest = max(largest, b.Count)
+		countWidth = max(countWidth, len(fmt.Sprint(b.Count)))
+	}
+
+	// Fit the bars between the label and the count
+	width := histogramBarMaxWidth
+	if opts.Width > 0 {
+		width = min(width, max(opts.Width-labelWidth-countWidth-3, histogramBarMinWidth))
+	}
+
+	var output strings.Builder
+	output.WriteString(InfoStyle.Render("Entries by weight (" + settings.WeightUnit + ")"))
+	for i := len(bins) - 1; i >= 0; i-- {
+		length := 0
+		if largest > 0 {
+			length = int(math.Round(float64(bins[i].Count) / float64(largest) * float64(width)))
+		}
+		if length == 0 && bins[i].Count > 0 {
+			length = 1
+		}
+		fmt.Fprintf(&output, "\n%*s %s%s %d", labelWidth, labels[i], strings.Repeat(bar, length), strings.Repeat(" ", width-length), bins[i].Count)
+	}
+	return output.String()
+}
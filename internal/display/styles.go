@@ -19,7 +19,58 @@ var (
 			Foreground(lipgloss.Color("86")).
 			Bold(true)
 
+	// WarningStyle for warnings about the goal
+	WarningStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("9"))
+
 	// TableBorderStyle for table borders
 	TableBorderStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("8"))
 )
+
+// BMICategoryStyles color BMIs by the name of their adult or BMI-for-age
+// category, from blue for underweight through green to red for the highest
+// obesity class
+var BMICategoryStyles = map[string]lipgloss.Style{
+	"Underweight":     lipgloss.NewStyle().Foreground(lipgloss.Color("75")),
+	"Normal":          lipgloss.NewStyle().Foreground(lipgloss.Color("77")),
+	"Healthy weight":  lipgloss.NewStyle().Foreground(lipgloss.Color("77")),
+	"Overweight":      lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	"Obesity":         lipgloss.NewStyle().Foreground(lipgloss.Color("202")),
+	"Obese class I":   lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
+	"Obese class II":  lipgloss.NewStyle().Foreground(lipgloss.Color("202")),
+	"Obese class III": lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+}
+
+// Calendar heatmap styles. Days with entries are shaded by the size of their
+// change in weight, from light to heavy, in green towards the goal and red
+// away from it.
+var (
+	// CalendarEmptyStyle for days without an entry
+	CalendarEmptyStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("238"))
+
+	// CalendarSteadyStyle for days without a change to show, like the first entry
+	CalendarSteadyStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("250"))
+
+	// CalendarTowardGoalStyles for changes towards the goal, light to heavy
+	CalendarTowardGoalStyles = []lipgloss.Style{
+		lipgloss.NewStyle().Foreground(lipgloss.Color("22")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("28")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("46")),
+	}
+
+	// CalendarAwayFromGoalStyles for changes away from the goal, light to heavy
+	CalendarAwayFromGoalStyles = []lipgloss.Style{
+		lipgloss.NewStyle().Foreground(lipgloss.Color("52")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("124")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+	}
+)
+
+// CelebrationStyle for achievements reached by a new entry
+var CelebrationStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("220"))
@@ -0,0 +1,161 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+const (
+	// changeBarMaxWidth is the longest a bar of the change chart gets on
+	// either side of its axis, in cells
+	changeBarMaxWidth = 25
+
+	// changeBarMinWidth is the shortest the longest bar gets on narrow terminals
+	changeBarMinWidth = 5
+)
+
+var (
+	// towardGoalStyle colors changes that move towards the goal weight
+	towardGoalStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+
+	// awayFromGoalStyle colors changes that move away from the goal weight
+	awayFromGoalStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// FormatPeriod formats the calendar period starting on start, e.g.
+// "2024-01-15" for a week, "January 2024" for a month or "2024" for a year
+func FormatPeriod(start time.Time, period analytics.Period) string {
+	switch period {
+	case analytics.Week:
+		return start.Format("2006-01-02")
+	case analytics.Year:
+		return start.Format("2006")
+	}
+	return start.Format("January 2006")
+}
+
+// periodHeader returns the table header for the period column
+func periodHeader(period analytics.Period) string {
+	switch period {
+	case analytics.Week:
+		return "Week of"
+	case analytics.Year:
+		return "Year"
+	}
+	return "Month"
+}
+
+// RenderSummary creates a table of weights summarized by calendar period,
+// oldest first, and a bar chart of the change in average weight from one
+// period to the next
+func RenderSummary(periods []analytics.PeriodSummary, period analytics.Period, settings *models.Settings, opts Options) string {
+	if len(periods) == 0 {
+		return "No weights tracked. Add one with: thicc add <weight> [date]"
+	}
+
+	border := lipgloss.NormalBorder()
+	if !opts.chart().Unicode() {
+		border = lipgloss.ASCIIBorder()
+	}
+
+	t := table.New().
+		Border(border).
+		BorderStyle(TableBorderStyle).
+		Headers(periodHeader(period), "Entries", "Average", "Min", "Max", "Change", "Per week")
+
+	for _, p := range periods {
+		// A rate needs entries on at least two days
+		rate := "-"
+		if p.From != p.To {
+			rate = FormatWeightChange(p.RatePerWeek, settings)
+		}
+		t.Row(
+			FormatPeriod(p.Start, period),
+			fmt.Sprintf("%d", p.Count),
+			FormatWeightFor(p.Mean, settings),
+			FormatWeightFor(p.Min, settings),
+			FormatWeightFor(p.Max, settings),
+			FormatWeightChange(p.Change(), settings),
+			rate,
+		)
+	}
+
+	output := t.Render()
+	if bars := renderChangeBars(periods, period, settings, opts); bars != "" {
+		output += "\n\n" + bars
+	}
+	return output
+}
+
+// renderChangeBars draws a horizontal bar for the change in each period's
+// average weight from the period before: losses to the left of the axis and
+// gains to the right, green when moving towards the goal and red when moving
+// away from it. It returns an empty string with fewer than two periods.
+func renderChangeBars(periods []analytics.PeriodSummary, period analytics.Period, settings *models.Settings, opts Options) string {
+	changes := analytics.MeanChanges(periods)
+	if len(changes) == 0 {
+		return ""
+	}
+
+	bar, axis := "█", "│"
+	if !opts.chart().Unicode() {
+		bar, axis = "#", "|"
+	}
+
+	labels := make([]string, len(changes))
+	values := make([]string, len(changes))
+	labelWidth, largest := 0, 0.0
+	for i, change := range changes {
+		labels[i] = FormatPeriod(periods[i+1].Start, period)
+		values[i] = FormatWeightChange(change, settings)
+		labelWidth = max(labelWidth, lipgloss.Width(labels[i]))
+		largest = math.Max(largest, math.Abs(change))
+	}
+
+	// Fit the bars on both sides of the axis, with the label before them and the value after
+	half := changeBarMaxWidth
+	if opts.Width > 0 {
+		valueWidth := 0
+		for _, v := range values {
+			valueWidth = max(valueWidth, lipgloss.Width(v))
+		}
+		half = min(half, max((opts.Width-labelWidth-valueWidth-4)/2, changeBarMinWidth))
+	}
+
+	var output strings.Builder
+	output.WriteString(InfoStyle.Render("Change in average from the previous " + string(period)))
+	for i, change := range changes {
+		length := 0
+		if largest > 0 {
+			length = int(math.Round(math.Abs(change) / largest * float64(half)))
+		}
+		if length == 0 && change != 0 {
+			length = 1
+		}
+
+		// Moving towards the goal means the change has the sign of the way
+		// from the previous average to the goal
+		style := awayFromGoalStyle
+		if (settings.GoalWeight-periods[i].Mean)*change > 0 {
+			style = towardGoalStyle
+		}
+
+		left, right := strings.Repeat(" ", half), strings.Repeat(" ", half)
+		if change < 0 {
+			left = strings.Repeat(" ", half-length) + style.Render(strings.Repeat(bar, length))
+		} else if change > 0 {
+			right = style.Render(strings.Repeat(bar, length)) + strings.Repeat(" ", half-length)
+		}
+
+		fmt.Fprintf(&output, "\n%-*s %s%s%s %s", labelWidth, labels[i], left, TableBorderStyle.Render(axis), right, values[i])
+	}
+
+	return output.String()
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/tryonlinux/thicc/internal/analytics"
 	"github.com/tryonlinux/thicc/internal/models"
 )
 
@@ -20,15 +21,38 @@ const asciiArt = `
     Weight Tracker
 `
 
+// plainTitle replaces the ASCII art, which is drawn with block characters,
+// on terminals that can't render Unicode
+const plainTitle = "\nTHICC - Weight Tracker\n"
+
+// tableGraphGap separates the table from the graph beside it
+const tableGraphGap = "  "
+
+// tableFrameHeight is the number of lines the table's borders and header take
+const tableFrameHeight = 4
+
 // RenderWeightsTable creates a formatted table of weights with a line graph
 func RenderWeightsTable(weights []models.Weight, settings *models.Settings, limit int) string {
+	return RenderWeightsTableOptions(weights, settings, limit, Options{})
+}
+
+// RenderWeightsTableOptions creates a formatted table of weights with a line
+// graph sized to fit the given width and height. The graph goes below the
+// table when there is no room to put it beside the table.
+func RenderWeightsTableOptions(weights []models.Weight, settings *models.Settings, limit int, opts Options) string {
+	// Terminals that can't render Unicode get an ASCII title, table and graph
+	title, border := asciiArt, lipgloss.NormalBorder()
+	if !opts.chart().Unicode() {
+		title, border = plainTitle, lipgloss.ASCIIBorder()
+	}
+
 	if len(weights) == 0 {
-		return TitleStyle.Render(asciiArt) + "\n\nNo weights tracked. Add one with: thicc add <weight> [date]"
+		return TitleStyle.Render(title) + "\n\nNo weights tracked. Add one with: thicc add <weight> [date]"
 	}
 
 	// Start with ASCII art
 	var output strings.Builder
-	output.WriteString(TitleStyle.Render(asciiArt))
+	output.WriteString(TitleStyle.Render(title))
 	output.WriteString("\n")
 
 	// Calculate stats
@@ -56,331 +80,157 @@ func RenderWeightsTable(weights []models.Weight, settings *models.Settings, limi
 	var deltaStr string
 	if delta < 0 {
 		// Lost weight
-		deltaStr = fmt.Sprintf("Lost %s", FormatWeight(math.Abs(delta), settings.WeightUnit))
+		deltaStr = fmt.Sprintf("Lost %s", FormatWeightFor(math.Abs(delta), settings))
 	} else if delta > 0 {
 		// Gained weight
-		deltaStr = fmt.Sprintf("Gained %s", FormatWeight(delta, settings.WeightUnit))
+		deltaStr = fmt.Sprintf("Gained %s", FormatWeightFor(delta, settings))
 	} else {
 		deltaStr = "No change"
 	}
 
 	// Build stats header (goes with ASCII art header)
 	var header strings.Builder
-	header.WriteString(HeaderStyle.Render(fmt.Sprintf("Latest: %s | BMI: %s | Avg: %s | %s",
-		FormatWeight(latestWeight, settings.WeightUnit),
-		FormatBMI(latestBMI),
-		FormatWeight(avgWeight, settings.WeightUnit),
-		deltaStr)))
+	header.WriteString(renderWrapped(HeaderStyle, fmt.Sprintf("Latest: %s | BMI: %s | Avg: %s | %s",
+		FormatWeightFor(latestWeight, settings),
+		FormatBMIDetails(latestBMI, weights[0].Date, settings),
+		FormatWeightFor(avgWeight, settings),
+		deltaStr), opts.Width))
 	header.WriteString("\n")
-	header.WriteString(InfoStyle.Render(fmt.Sprintf("Min: %s | Max: %s | Entries: %d",
-		FormatWeight(minWeight, settings.WeightUnit),
-		FormatWeight(maxWeight, settings.WeightUnit),
-		len(weights))))
+	header.WriteString(renderWrapped(InfoStyle, fmt.Sprintf("Min: %s | Max: %s | Entries: %d",
+		FormatWeightFor(minWeight, settings),
+		FormatWeightFor(maxWeight, settings),
+		len(weights)), opts.Width))
 	header.WriteString("\n\n\n")
 
-	// Calculate goal difference
-	goalDiff := latestWeight - settings.GoalWeight
-	var goalDiffStr string
-	if goalDiff > 0 {
-		// Current weight is above goal - need to lose
-		goalDiffStr = fmt.Sprintf("%.1f %s to lose", goalDiff, settings.WeightUnit)
-	} else if goalDiff < 0 {
-		// Current weight is below goal - need to gain
-		goalDiffStr = fmt.Sprintf("%.1f %s to gain", math.Abs(goalDiff), settings.WeightUnit)
+	// The pace needed to meet the goal's deadline, with any warnings about
+	// it, goes under the goal line
+	var paceLines []string
+	if pace, err := analytics.Pace(weights, settings, settings.Deadline, opts.today()); err == nil {
+		if status := FormatGoalPace(pace, settings); status != "" {
+			paceLines = append(paceLines, InfoStyle.Render(status))
+		}
+		for _, warning := range GoalWarnings(pace, settings) {
+			paceLines = append(paceLines, WarningStyle.Render(warning))
+		}
+	}
+
+	// Lines left for the table and graph below the headers, goal line and
+	// pace, keeping one for the shell prompt. Drop the ASCII art when it doesn't fit.
+	bodyHeight := opts.Height - lipgloss.Height(output.String()+header.String()) - 3 - len(paceLines)
+	if opts.Height > 0 && bodyHeight < GraphMinHeight+graphFrameHeight {
+		bodyHeight += lipgloss.Height(output.String()) - 1
+		output.Reset()
+	}
+
+	// Size the table and graph to the available space
+	rows := opts.tableRows(bodyHeight)
+	weightTable := createWeightTable(truncateWeights(weights, rows), settings, border)
+
+	// Combine table and graph, side by side or stacked on narrow terminals
+	var combined string
+	if opts.HideGraph {
+		combined = weightTable
 	} else {
-		goalDiffStr = "at goal!"
+		data := PrepareChartFor(weights, settings)
+		labelWidth := graphLabelWidth(weightRange{min: data.Min, max: data.Max}, settings)
+		graphWidth, graphHeight, stacked := opts.graphSize(lipgloss.Width(weightTable), labelWidth, bodyHeight)
+		weightGraph := createLineGraph(weights, settings, graphWidth, graphHeight, opts.chart(), opts.BMICutoffs)
+
+		if stacked {
+			weightTable = createWeightTable(truncateWeights(weights, min(rows, TableMaxRows)), settings, border)
+			combined = lipgloss.JoinVertical(lipgloss.Left, weightTable, "", weightGraph)
+		} else {
+			combined = lipgloss.JoinHorizontal(lipgloss.Top, weightTable, tableGraphGap, weightGraph)
+		}
 	}
 
 	// Build goal weight section (goes with table/graph below)
 	goalHeader := fmt.Sprintf("Goal Weight: %s (%s)",
-		FormatWeight(settings.GoalWeight, settings.WeightUnit),
-		goalDiffStr)
+		FormatWeightFor(settings.GoalWeight, settings),
+		FormatGoalDifference(latestWeight, settings))
+	if settings.HasGoalRange() {
+		goalHeader = fmt.Sprintf("Goal Range: %s (%s)", FormatGoal(settings), FormatGoalDifference(latestWeight, settings))
+		in, total := analytics.DaysInRange(analytics.Days(weights), settings.GoalStart, settings.GoalLow, settings.GoalHigh)
+		if total > 0 {
+			goalHeader += fmt.Sprintf(" | In range on %d of %s (%d%%)", in, plural(total, "day"), in*100/total)
+		}
+	}
+	goalHeaderWidth := GoalHeaderWidth
+	if opts.Width > 0 {
+		goalHeaderWidth = min(max(lipgloss.Width(combined), lipgloss.Width(goalHeader)), opts.Width)
+	}
 	centeredGoalStyle := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("11")).
 		Align(lipgloss.Center).
-		Width(GoalHeaderWidth)
+		Width(goalHeaderWidth)
 	header.WriteString(centeredGoalStyle.Render(goalHeader))
-	header.WriteString("\n\n")
-
-	// Truncate to TableMaxRows for table display
-	displayWeights := weights
-	if len(weights) > TableMaxRows {
-		displayWeights = weights[:TableMaxRows]
+	header.WriteString("\n")
+	for _, line := range paceLines {
+		header.WriteString(lipgloss.PlaceHorizontal(goalHeaderWidth, lipgloss.Center, line))
+		header.WriteString("\n")
 	}
-
-	// Create table and graph side by side
-	weightTable := createWeightTable(displayWeights, settings)
-	weightGraph := createLineGraph(weights, settings)
-
-	// Combine table and graph
-	combined := lipgloss.JoinHorizontal(lipgloss.Top, weightTable, "  ", weightGraph)
+	header.WriteString("\n")
 
 	return output.String() + header.String() + combined
 }
 
-// createWeightTable creates the weight table
-func createWeightTable(weights []models.Weight, settings *models.Settings) string {
-	t := table.New().
-		Border(lipgloss.NormalBorder()).
-		BorderStyle(TableBorderStyle).
-		Headers("ID", "Date", "Weight", "BMI")
-
-	for _, w := range weights {
-		t.Row(
-			fmt.Sprintf("%d", w.ID),
-			FormatDate(w.Date),
-			FormatWeight(w.Weight, settings.WeightUnit),
-			FormatBMI(w.BMI),
-		)
+// renderWrapped renders text in a style, wrapping it at word boundaries when
+// it is wider than a non-zero width
+func renderWrapped(style lipgloss.Style, text string, width int) string {
+	if width > 0 && lipgloss.Width(text) > width {
+		style = style.Width(width)
 	}
-
-	return t.Render()
+	return style.Render(text)
 }
 
-// weightRange holds the min and max weight values for graph scaling
-type weightRange struct {
-	min float64
-	max float64
-}
-
-// calculateWeightRange determines the min and max weights including goal weight and padding
-func calculateWeightRange(weights []models.Weight, goalWeight float64) weightRange {
-	minWeight := math.MaxFloat64
-	maxWeight := -math.MaxFloat64
-
-	for _, w := range weights {
-		if w.Weight < minWeight {
-			minWeight = w.Weight
-		}
-		if w.Weight > maxWeight {
-			maxWeight = w.Weight
-		}
-	}
-
-	// Include goal weight in range calculation
-	if goalWeight < minWeight {
-		minWeight = goalWeight
-	}
-	if goalWeight > maxWeight {
-		maxWeight = goalWeight
+// truncateWeights returns at most the first n weights
+func truncateWeights(weights []models.Weight, n int) []models.Weight {
+	if len(weights) > n {
+		return weights[:n]
 	}
-
-	// Add some padding to the range
-	padding := (maxWeight - minWeight) * 0.1
-	if padding == 0 {
-		padding = 1
-	}
-	minWeight -= padding
-	maxWeight += padding
-
-	return weightRange{min: minWeight, max: maxWeight}
+	return weights
 }
 
-// createGraphGrid initializes an empty graph grid
-func createGraphGrid(width, height int) [][]rune {
-	graph := make([][]rune, height)
-	for i := range graph {
-		graph[i] = make([]rune, width)
-		for j := range graph[i] {
-			graph[i][j] = ' '
+// createWeightTable creates the weight table with the given border
+func createWeightTable(weights []models.Weight, settings *models.Settings, border lipgloss.Border) string {
+	// Only show the note column when at least one entry has a note
+	showNotes := false
+	for _, w := range weights {
+		if w.Note != "" {
+			showNotes = true
+			break
 		}
 	}
-	return graph
-}
-
-// reverseWeights returns a reversed copy of the weights slice (oldest to newest)
-func reverseWeights(weights []models.Weight) []models.Weight {
-	reversed := make([]models.Weight, len(weights))
-	copy(reversed, weights)
-	for i := 0; i < len(reversed)/2; i++ {
-		reversed[i], reversed[len(reversed)-1-i] = reversed[len(reversed)-1-i], reversed[i]
-	}
-	return reversed
-}
-
-// normalizeToGraphY converts a weight value to a Y coordinate on the graph
-func normalizeToGraphY(weight float64, wr weightRange, height int) int {
-	normalized := (weight - wr.min) / (wr.max - wr.min)
-	y := height - 1 - int(normalized*float64(height-1))
-
-	// Ensure y is within bounds
-	if y < 0 {
-		y = 0
-	}
-	if y >= height {
-		y = height - 1
-	}
-	return y
-}
 
-// plotDataPoints plots weight data points and connects them with lines
-func plotDataPoints(graph [][]rune, weights []models.Weight, wr weightRange, width, height int) {
-	// Sample weights if we have more than width
-	step := 1
-	if len(weights) > width {
-		step = len(weights) / width
+	headers := []string{"ID", "Date", "Weight", "BMI"}
+	if showNotes {
+		headers = append(headers, "Note")
 	}
 
-	prevX, prevY := -1, -1
-	for i := 0; i < len(weights); i += step {
-		w := weights[i]
-		x := (i / step) % width
-		y := normalizeToGraphY(w.Weight, wr, height)
-
-		// Draw line from previous point
-		if prevX >= 0 {
-			drawLine(graph, prevX, prevY, x, y)
-		}
-
-		// Mark the point with smallest dot
-		if x < width && y < height && y >= 0 {
-			graph[y][x] = '·'
-		}
-
-		prevX, prevY = x, y
-	}
-}
+	t := table.New().
+		Border(border).
+		BorderStyle(TableBorderStyle).
+		Headers(headers...)
 
-// drawGoalLine draws a horizontal line representing the goal weight
-func drawGoalLine(graph [][]rune, goalWeight float64, wr weightRange, width, height int) int {
-	goalY := normalizeToGraphY(goalWeight, wr, height)
-	if goalY >= 0 && goalY < height {
-		for x := 0; x < width; x++ {
-			// Don't overwrite weight data points
-			if graph[goalY][x] != '·' {
-				graph[goalY][x] = '─'
-			}
+	for _, w := range weights {
+		row := []string{
+			fmt.Sprintf("%d", w.ID),
+			FormatDateTime(w.Date, w.Time),
+			FormatWeightFor(w.Weight, settings),
+			RenderBMI(w.BMI, w.Date, settings),
 		}
-	}
-	return goalY
-}
-
-// renderGraphWithLabels renders the graph grid with axis labels and styling
-func renderGraphWithLabels(graph [][]rune, weights []models.Weight, settings *models.Settings, wr weightRange, goalY int) string {
-	width := len(graph[0])
-	height := len(graph)
-
-	var graphOutput strings.Builder
-	graphStyle := lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("8")).
-		Padding(0, 1)
-
-	var graphLines strings.Builder
-
-	// Add max weight label
-	graphLines.WriteString(fmt.Sprintf("%.1f %s ┤\n", wr.max, settings.WeightUnit))
-
-	// Add graph lines with goal weight label
-	for i := 0; i < height; i++ {
-		if i == goalY {
-			// Add goal weight label on the goal line
-			goalLabel := fmt.Sprintf("Goal: %.1f", settings.GoalWeight)
-			graphLines.WriteString(goalLabel)
-			if len(goalLabel) < GoalLabelMinWidth {
-				graphLines.WriteString(strings.Repeat(" ", GoalLabelMinWidth-len(goalLabel)))
-			}
-			graphLines.WriteString("┤")
-		} else if i == 0 || i == height-1 {
-			graphLines.WriteString("        │")
-		} else {
-			graphLines.WriteString("        │")
+		if showNotes {
+			row = append(row, FormatNote(w.Note))
 		}
-		graphLines.WriteString(string(graph[i]))
-		graphLines.WriteString("\n")
-	}
-
-	// Add min weight label and x-axis
-	graphLines.WriteString(fmt.Sprintf("%.1f %s ┤", wr.min, settings.WeightUnit))
-	graphLines.WriteString(strings.Repeat("─", width))
-	graphLines.WriteString("\n")
-
-	// Add x-axis labels (date range)
-	if len(weights) > 0 {
-		oldestDate := weights[0].Date
-		newestDate := weights[len(weights)-1].Date
-		graphLines.WriteString(fmt.Sprintf("        %s%s%s\n",
-			oldestDate,
-			strings.Repeat(" ", width-len(oldestDate)-len(newestDate)),
-			newestDate))
+		t.Row(row...)
 	}
 
-	graphOutput.WriteString(graphStyle.Render(graphLines.String()))
-	return graphOutput.String()
-}
-
-// createLineGraph creates a simple ASCII line graph
-func createLineGraph(weights []models.Weight, settings *models.Settings) string {
-	if len(weights) == 0 {
-		return ""
-	}
-
-	// Graph dimensions
-	width := GraphWidth
-	height := GraphHeight
-
-	// Calculate weight range for scaling
-	wr := calculateWeightRange(weights, settings.GoalWeight)
-
-	// Create empty graph grid
-	graph := createGraphGrid(width, height)
-
-	// Reverse weights to show oldest to newest (left to right)
-	reversedWeights := reverseWeights(weights)
-
-	// Plot weight data points and connect them
-	plotDataPoints(graph, reversedWeights, wr, width, height)
-
-	// Draw horizontal goal weight line
-	goalY := drawGoalLine(graph, settings.GoalWeight, wr, width, height)
-
-	// Render graph with labels and styling
-	return renderGraphWithLabels(graph, reversedWeights, settings, wr, goalY)
-}
-
-// drawLine draws a line between two points using Bresenham's algorithm
-func drawLine(graph [][]rune, x0, y0, x1, y1 int) {
-	dx := abs(x1 - x0)
-	dy := abs(y1 - y0)
-	sx := -1
-	if x0 < x1 {
-		sx = 1
-	}
-	sy := -1
-	if y0 < y1 {
-		sy = 1
-	}
-	err := dx - dy
-
-	for {
-		// Draw very light connecting line (don't overwrite data points)
-		if (x0 != x1 || y0 != y1) && x0 >= 0 && x0 < len(graph[0]) && y0 >= 0 && y0 < len(graph) {
-			if graph[y0][x0] != '·' {
-				graph[y0][x0] = '∙'
-			}
-		}
-
-		if x0 == x1 && y0 == y1 {
-			break
-		}
-
-		e2 := 2 * err
-		if e2 > -dy {
-			err -= dy
-			x0 += sx
-		}
-		if e2 < dx {
-			err += dx
-			y0 += sy
-		}
-	}
+	return t.Render()
 }
 
-// abs returns the absolute value of an integer
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
+// RenderEntriesTable creates a table of weight entries without stats or graph,
+// e.g. to preview the entries a command is about to change
+func RenderEntriesTable(weights []models.Weight, settings *models.Settings) string {
+	return createWeightTable(weights, settings, lipgloss.NormalBorder())
 }
@@ -0,0 +1,126 @@
+package editor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Edit writes content to a temporary file, opens it in the user's editor and
+// returns the edited content once the editor exits.
+// The editor is taken from $VISUAL, then $EDITOR, falling back to a platform default.
+func Edit(content, pattern string) (string, error) {
+	tmpFile, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		tmpFile.Close()
+		return "", err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return "", err
+	}
+
+	// The editor variable may include arguments (e.g. "code --wait")
+	fields := strings.Fields(editorCommand())
+	cmd := exec.Command(fields[0], append(fields[1:], tmpFile.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running editor %q: %w", fields[0], err)
+	}
+
+	edited, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return string(edited), nil
+}
+
+// editorCommand returns the command used to launch the user's editor
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if value := strings.TrimSpace(os.Getenv(env)); value != "" {
+			return value
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// ParseKeyValues parses a simple TOML-style document of `key = value` lines.
+// Blank lines and lines starting with # are ignored. Double-quoted values are
+// unquoted; other values are returned as-is with surrounding whitespace removed.
+func ParseKeyValues(doc string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(doc))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNum)
+		}
+		if _, exists := values[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNum, key)
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid string for %q", lineNum, key)
+			}
+			value = unquoted
+		}
+
+		values[key] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// ChangedValues parses an edited document of the fields in original and
+// returns only the fields whose text the user changed, so unchanged fields
+// are kept exactly as they were. Fields not in original are an error.
+func ChangedValues(doc string, original map[string]string) (map[string]string, error) {
+	values, err := ParseKeyValues(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]string)
+	for key, value := range values {
+		originalValue, ok := original[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+		if value != originalValue {
+			changed[key] = value
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,351 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+const (
+	// chartPadding is the space around the plot area and its labels, in pixels
+	chartPadding = 8
+
+	// yTickTarget is roughly how many weight labels the y-axis gets
+	yTickTarget = 5
+
+	// xTickSpacing is the least space between date labels, in pixels
+	xTickSpacing = 24
+
+	// pointSpacing is the least average space between entries, in pixels,
+	// at which each entry is marked with a dot
+	pointSpacing = 6
+
+	// bandOpacity is the opacity of the BMI band shading
+	bandOpacity = 0x1a
+
+	// goalRangeOpacity is the opacity of the goal range shading
+	goalRangeOpacity = 0x40
+
+	// eventLabelMaxWidth is the most characters of a note shown above the plot
+	eventLabelMaxWidth = 20
+)
+
+// RenderChart draws the weights, their trend, the goal and milestone lines, BMI category
+// bands and entries with notes as an anti-aliased image of the given size in pixels
+func RenderChart(data display.ChartData, settings *models.Settings, width, height int) *image.RGBA {
+	c := newCanvas(width, height, DarkTheme.Background)
+	drawChart(c, data, settings, float64(width), float64(height), DarkTheme)
+	return c.img
+}
+
+// RenderChartSVG draws the same chart as RenderChart as an SVG document
+func RenderChartSVG(data display.ChartData, settings *models.Settings, width, height int) string {
+	doc := newSVGDocument(width, height, DarkTheme.Background)
+	drawChart(doc, data, settings, float64(width), float64(height), DarkTheme)
+	return doc.String()
+}
+
+// plotArea is the part of the chart the weights are drawn in, and maps
+// weights and times onto it
+type plotArea struct {
+	left, top, right, bottom float64
+	data                     display.ChartData
+}
+
+// width returns the width of the plot area
+func (p plotArea) width() float64 {
+	return p.right - p.left
+}
+
+// x returns the horizontal position of a time
+func (p plotArea) x(t time.Time) float64 {
+	span := p.data.End().Sub(p.data.Start())
+	if span <= 0 {
+		return (p.left + p.right) / 2
+	}
+	return p.left + float64(t.Sub(p.data.Start()))/float64(span)*p.width()
+}
+
+// y returns the vertical position of a weight
+func (p plotArea) y(weight float64) float64 {
+	return p.bottom - p.data.Level(weight)*(p.bottom-p.top)
+}
+
+// drawChart draws the chart: BMI bands, weight grid and labels, date axis,
+// goal and milestone lines, notes, and the weights with their trend
+func drawChart(s surface, data display.ChartData, settings *models.Settings, width, height float64, theme Theme) {
+	if len(data.Points) == 0 {
+		return
+	}
+
+	// Weight labels on the y-axis at round numbers
+	ticks, decimals := weightTicks(data.Min, data.Max)
+	labels := make([]string, len(ticks))
+	labelWidth := 0
+	for i, tick := range ticks {
+		labels[i] = formatTick(tick, decimals, settings)
+		labelWidth = max(labelWidth, textWidth(labels[i]))
+	}
+
+	// The plot area leaves room for the legend above, the weight labels to
+	// the left and the date labels below
+	plot := plotArea{
+		left:   float64(chartPadding + labelWidth + chartPadding),
+		top:    float64(chartPadding + textHeight + chartPadding),
+		right:  width - chartPadding,
+		bottom: height - chartPadding - textHeight - chartPadding,
+		data:   data,
+	}
+	if plot.width() < 2 || plot.bottom-plot.top < 2 {
+		return
+	}
+
+	drawBMIBands(s, plot, settings, theme)
+
+	// Grid lines and weight labels
+	for i, tick := range ticks {
+		y := math.Round(plot.y(tick))
+		s.rect(plot.left, y, plot.width(), 1, theme.Grid)
+		s.text(plot.left-chartPadding-float64(textWidth(labels[i])), y-textHeight/2, labels[i], theme.Label)
+	}
+
+	drawDateAxis(s, plot, width, theme)
+
+	// Milestone lines, more finely dashed, with their weights at the right end
+	for _, m := range data.Milestones {
+		y := plot.y(m)
+		s.dashed(point{plot.left, y}, point{plot.right, y}, 1, 3, 4, theme.Milestone)
+		label := "Milestone " + display.FormatWeightFor(m, settings)
+		s.text(plot.right-float64(textWidth(label)), y-textHeight-4, label, theme.Milestone)
+	}
+
+	// Goal line, dashed, with its weight at the right end, or a shaded band
+	// between dashed edges for a goal range
+	if data.HasGoalRange() {
+		top, bottom := plot.y(data.GoalHigh), plot.y(data.GoalLow)
+		s.rect(plot.left, top, plot.width(), bottom-top, color.NRGBA{theme.Goal.R, theme.Goal.G, theme.Goal.B, goalRangeOpacity})
+		s.dashed(point{plot.left, top}, point{plot.right, top}, 1, 6, 4, theme.Goal)
+		s.dashed(point{plot.left, bottom}, point{plot.right, bottom}, 1, 6, 4, theme.Goal)
+		goalLabel := "Goal " + display.FormatGoal(settings)
+		s.text(plot.right-float64(textWidth(goalLabel)), top-textHeight-4, goalLabel, theme.Goal)
+	} else {
+		goalY := plot.y(data.Goal)
+		s.dashed(point{plot.left, goalY}, point{plot.right, goalY}, 1.5, 6, 4, theme.Goal)
+		goalLabel := "Goal " + display.FormatWeightFor(data.Goal, settings)
+		s.text(plot.right-float64(textWidth(goalLabel)), goalY-textHeight-4, goalLabel, theme.Goal)
+	}
+
+	events := drawEventGuides(s, plot, theme)
+
+	// Weights, with a dot for each entry when they're far enough apart
+	showDots := float64(len(data.Points)*pointSpacing) <= plot.width()
+	for _, run := range splitAtGaps(data.Points) {
+		line := plotPoints(plot, run)
+		s.polyline(line, 1.5, theme.Weight)
+		if showDots || len(run) == 1 {
+			for _, pt := range line {
+				s.circle(pt, 2.5, theme.Weight)
+			}
+		}
+	}
+
+	// Trend over the weights
+	for _, run := range splitAtGaps(data.Trend()) {
+		s.polyline(plotPoints(plot, run), 2.5, theme.Trend)
+	}
+
+	// Event markers over the lines
+	for _, pt := range events {
+		s.polygon(diamond(pt, 4.5), theme.Event)
+	}
+
+	drawLegend(s, plot.left, chartPadding, data, len(events) > 0, theme)
+}
+
+// drawBMIBands shades the weight ranges of the categories of the user's BMI
+// standard, when the user's height is known
+func drawBMIBands(s surface, plot plotArea, settings *models.Settings, theme Theme) {
+	if settings.Height <= 0 {
+		return
+	}
+	for i, category := range settings.BMICutoffs().Categories {
+		low := calculator.WeightForBMI(category.Min, settings.Height, settings.WeightUnit, settings.HeightUnit)
+		high := calculator.WeightForBMI(category.Max, settings.Height, settings.WeightUnit, settings.HeightUnit)
+		low, high = math.Max(low, plot.data.Min), math.Min(high, plot.data.Max)
+		if high <= low {
+			continue
+		}
+
+		top, bottom := plot.y(high), plot.y(low)
+		band := theme.Bands[i%len(theme.Bands)]
+		s.rect(plot.left, top, plot.width(), bottom-top, color.NRGBA{band.R, band.G, band.B, bandOpacity})
+		if bottom-top >= textHeight+6 {
+			s.text(plot.left+4, top+3, category.Name, color.NRGBA{band.R, band.G, band.B, 0xb0})
+		}
+	}
+}
+
+// drawDateAxis draws the x-axis with date ticks and labels spread evenly below the plot
+func drawDateAxis(s surface, plot plotArea, width float64, theme Theme) {
+	start := plot.data.Start()
+	span := plot.data.End().Sub(start)
+	layout := "Jan 02"
+	if span > 180*24*time.Hour {
+		layout = "Jan 2006"
+	}
+
+	count := max(2, int(plot.width())/(textWidth(layout)+xTickSpacing)+1)
+	count = min(count, int(span.Hours()/24)+1)
+	for i := 0; i < count; i++ {
+		x, at := (plot.left+plot.right)/2, start
+		if count > 1 {
+			x = math.Round(plot.left + float64(i)*plot.width()/float64(count-1))
+			at = start.Add(time.Duration(float64(span) * float64(i) / float64(count-1)))
+		}
+		s.rect(x, plot.bottom, 1, chartPadding/2, theme.Label)
+		label := at.Format(layout)
+		labelX := math.Min(math.Max(x-float64(textWidth(label))/2, 0), width-float64(textWidth(label)))
+		s.text(labelX, plot.bottom+chartPadding, label, theme.Label)
+	}
+	s.rect(plot.left, plot.bottom, plot.width(), 1, theme.Label)
+}
+
+// drawEventGuides draws a dashed guide from the top of the plot down to each
+// entry with a note, with the note along the top where there is room. It
+// returns the entries' positions, to mark over the weight line.
+func drawEventGuides(s surface, plot plotArea, theme Theme) []point {
+	var events []point
+	nextFree := plot.left
+	for _, p := range plot.data.Points {
+		if p.Note == "" {
+			continue
+		}
+		pt := point{plot.x(p.At), plot.y(p.Weight)}
+		events = append(events, pt)
+		s.dashed(point{pt.x, plot.top}, pt, 1, 2, 3, theme.EventGuide)
+
+		label := truncateLabel(p.Note, eventLabelMaxWidth)
+		labelX := math.Min(pt.x+4, plot.right-float64(textWidth(label)))
+		if labelX >= nextFree {
+			s.text(labelX, plot.top+2, label, theme.Event)
+			nextFree = labelX + float64(textWidth(label)+charWidth)
+		}
+	}
+	return events
+}
+
+// legendEntry is a line of the chart named in the legend
+type legendEntry struct {
+	name  string
+	color color.Color
+}
+
+// drawLegend draws a swatch and name for each line of the chart
+func drawLegend(s surface, x, y float64, data display.ChartData, events bool, theme Theme) {
+	goal := "Goal"
+	if data.HasGoalRange() {
+		goal = "Goal range"
+	}
+	entries := []legendEntry{
+		{"Weight", theme.Weight},
+		{fmt.Sprintf("%d-day trend", int(display.TrendWindow.Hours()/24)), theme.Trend},
+		{goal, theme.Goal},
+	}
+	if len(data.Milestones) > 0 {
+		entries = append(entries, legendEntry{"Milestone", theme.Milestone})
+	}
+	middle := y + textHeight/2
+	for _, entry := range entries {
+		s.polyline([]point{{x, middle}, {x + 16, middle}}, 2.5, entry.color)
+		s.text(x+20, y, entry.name, theme.Label)
+		x += float64(20 + textWidth(entry.name) + 2*chartPadding)
+	}
+	if events {
+		s.polygon(diamond(point{x + 8, middle}, 4.5), theme.Event)
+		s.text(x+20, y, "Note", theme.Label)
+	}
+}
+
+// plotPoints returns the positions of chart points in the plot area
+func plotPoints(plot plotArea, points []display.ChartPoint) []point {
+	line := make([]point, len(points))
+	for i, p := range points {
+		line[i] = point{plot.x(p.At), plot.y(p.Weight)}
+	}
+	return line
+}
+
+// diamond returns the corners of a diamond marker centered on a point
+func diamond(center point, radius float64) []point {
+	return []point{
+		{center.x, center.y - radius},
+		{center.x + radius, center.y},
+		{center.x, center.y + radius},
+		{center.x - radius, center.y},
+	}
+}
+
+// splitAtGaps splits points into runs that are connected by lines
+func splitAtGaps(points []display.ChartPoint) [][]display.ChartPoint {
+	var runs [][]display.ChartPoint
+	start := 0
+	for i := 1; i <= len(points); i++ {
+		if i == len(points) || points[i].GapBefore {
+			runs = append(runs, points[start:i])
+			start = i
+		}
+	}
+	return runs
+}
+
+// weightTicks returns round numbers between min and max to label the weight
+// axis with, and the decimals needed to tell them apart
+func weightTicks(minWeight, maxWeight float64) ([]float64, int) {
+	if maxWeight <= minWeight {
+		return []float64{minWeight}, 0
+	}
+
+	// Step by 1, 2 or 5 times a power of ten
+	rough := (maxWeight - minWeight) / yTickTarget
+	magnitude := math.Pow(10, math.Floor(math.Log10(rough)))
+	step := magnitude
+	for _, factor := range []float64{2, 5, 10} {
+		if step >= rough {
+			break
+		}
+		step = magnitude * factor
+	}
+
+	decimals := max(0, int(-math.Floor(math.Log10(step))))
+	var ticks []float64
+	for tick := math.Ceil(minWeight/step) * step; tick <= maxWeight; tick += step {
+		ticks = append(ticks, tick)
+	}
+	return ticks, decimals
+}
+
+// formatTick formats a weight axis label with the user's precision, or the
+// decimals of the tick step when those are more, in stones and pounds for stones
+func formatTick(weight float64, decimals int, settings *models.Settings) string {
+	if settings.WeightUnit == "st" {
+		return display.FormatWeightShortPrecision(weight, settings.WeightUnit, settings.WeightDecimals())
+	}
+	return fmt.Sprintf("%.*f", max(decimals, settings.WeightDecimals()), weight)
+}
+
+// truncateLabel shortens text to at most the given number of characters,
+// ending in "..." when cut. The raster font only has Latin-1 characters,
+// so there is no ellipsis character.
+func truncateLabel(text string, maxWidth int) string {
+	runes := []rune(text)
+	if len(runes) <= maxWidth {
+		return text
+	}
+	return string(runes[:maxWidth-3]) + "..."
+}
@@ -0,0 +1,39 @@
+package graphics
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// ExportFormats lists the file formats charts can be exported to, the default first
+var ExportFormats = []string{"svg", "png"}
+
+// FormatForPath returns the export format matching a file's extension, or
+// an empty string when the extension isn't one
+func FormatForPath(path string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	for _, format := range ExportFormats {
+		if ext == format {
+			return format
+		}
+	}
+	return ""
+}
+
+// ExportChart writes the chart, at the given size in pixels, in an export format
+func ExportChart(w io.Writer, format string, data display.ChartData, settings *models.Settings, width, height int) error {
+	switch strings.ToLower(format) {
+	case "svg":
+		_, err := io.WriteString(w, RenderChartSVG(data, settings, width, height))
+		return err
+	case "png":
+		return png.Encode(w, RenderChart(data, settings, width, height))
+	}
+	return fmt.Errorf("unknown chart format %q: use %s", format, strings.Join(ExportFormats, ", "))
+}
@@ -0,0 +1,20 @@
+package graphics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// encodeITerm2 sends the image as an inline PNG file using iTerm2's
+// proprietary escape sequence, which WezTerm also understands
+func encodeITerm2(img image.Image, columns, rows int) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0:%s\a",
+		buf.Len(), columns, rows, base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
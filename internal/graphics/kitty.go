@@ -0,0 +1,41 @@
+package graphics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// kittyChunkSize is the most base64 data the Kitty protocol allows per escape sequence
+const kittyChunkSize = 4096
+
+// encodeKitty sends the image as PNG over the Kitty graphics protocol, split
+// into chunks. The terminal scales it to the given cells and is asked not to
+// reply, so nothing is left on the command line.
+func encodeKitty(img image.Image, columns, rows int) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("failed to encode chart: %w", err)
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var out strings.Builder
+	for start := 0; start < len(data); start += kittyChunkSize {
+		end := min(start+kittyChunkSize, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		if start == 0 {
+			fmt.Fprintf(&out, "\x1b_Ga=T,f=100,q=2,c=%d,r=%d,m=%d;", columns, rows, more)
+		} else {
+			fmt.Fprintf(&out, "\x1b_Gm=%d;", more)
+		}
+		out.WriteString(data[start:end])
+		out.WriteString("\x1b\\")
+	}
+	return out.String(), nil
+}
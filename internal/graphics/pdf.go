@@ -0,0 +1,108 @@
+package graphics
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/pdf"
+)
+
+// pdfFontSize is the size of Courier, which is 0.6 of its size wide, that
+// matches the width of the raster font's characters
+const pdfFontSize = charWidth / 0.6
+
+// bezierCircle is how far along the tangent the control points of a Bézier
+// curve approximating a quarter circle are, relative to the radius
+const bezierCircle = 0.5523
+
+// pdfContent is a surface that writes PDF content stream operators. It draws
+// in a box of the chart's size with the origin flipped to the top left, to
+// match the other surfaces. Translucent colors are blended with the
+// background, since PDF needs a graphics state for transparency.
+type pdfContent struct {
+	ops        strings.Builder
+	background color.RGBA
+}
+
+// RenderChartPDF draws the chart in a theme as PDF content in a width ×
+// height box, for a form in a pdf.Document
+func RenderChartPDF(data display.ChartData, settings *models.Settings, width, height int, theme Theme) string {
+	c := &pdfContent{background: theme.Background}
+	fmt.Fprintf(&c.ops, "1 0 0 -1 0 %d cm\n", height)
+	c.rect(0, 0, float64(width), float64(height), theme.Background)
+	drawChart(c, data, settings, float64(width), float64(height), theme)
+	return c.ops.String()
+}
+
+// opaque blends a translucent color with the background
+func (c *pdfContent) opaque(col color.Color) color.Color {
+	n := color.NRGBAModel.Convert(col).(color.NRGBA)
+	if n.A == 0xff {
+		return n
+	}
+	blend := func(fg, bg uint8) uint8 {
+		return uint8((int(fg)*int(n.A) + int(bg)*(0xff-int(n.A))) / 0xff)
+	}
+	return color.RGBA{blend(n.R, c.background.R), blend(n.G, c.background.G), blend(n.B, c.background.B), 0xff}
+}
+
+// rect fills a rectangle
+func (c *pdfContent) rect(x, y, width, height float64, col color.Color) {
+	fmt.Fprintf(&c.ops, "%s %s %s %s %s re f\n",
+		pdf.FillColor(c.opaque(col)), pdf.Number(x), pdf.Number(y), pdf.Number(width), pdf.Number(height))
+}
+
+// polyline strokes a line with round caps and joints
+func (c *pdfContent) polyline(points []point, width float64, col color.Color) {
+	fmt.Fprintf(&c.ops, "%s %s w 1 J 1 j ", pdf.StrokeColor(c.opaque(col)), pdf.Number(width))
+	c.path(points)
+	c.ops.WriteString("S\n")
+}
+
+// dashed strokes a dashed line
+func (c *pdfContent) dashed(a, b point, width, dash, space float64, col color.Color) {
+	fmt.Fprintf(&c.ops, "%s %s w 0 J [%s %s] 0 d ",
+		pdf.StrokeColor(c.opaque(col)), pdf.Number(width), pdf.Number(dash), pdf.Number(space))
+	c.path([]point{a, b})
+	c.ops.WriteString("S [] 0 d\n")
+}
+
+// circle fills a circle made of four Bézier curves
+func (c *pdfContent) circle(center point, radius float64, col color.Color) {
+	k := radius * bezierCircle
+	x, y, r := center.x, center.y, radius
+	n := pdf.Number
+	fmt.Fprintf(&c.ops, "%s %s %s m ", pdf.FillColor(c.opaque(col)), n(x+r), n(y))
+	fmt.Fprintf(&c.ops, "%s %s %s %s %s %s c ", n(x+r), n(y+k), n(x+k), n(y+r), n(x), n(y+r))
+	fmt.Fprintf(&c.ops, "%s %s %s %s %s %s c ", n(x-k), n(y+r), n(x-r), n(y+k), n(x-r), n(y))
+	fmt.Fprintf(&c.ops, "%s %s %s %s %s %s c ", n(x-r), n(y-k), n(x-k), n(y-r), n(x), n(y-r))
+	fmt.Fprintf(&c.ops, "%s %s %s %s %s %s c f\n", n(x+k), n(y-r), n(x+r), n(y-k), n(x+r), n(y))
+}
+
+// polygon fills a polygon
+func (c *pdfContent) polygon(points []point, col color.Color) {
+	c.ops.WriteString(pdf.FillColor(c.opaque(col)) + " ")
+	c.path(points)
+	c.ops.WriteString("h f\n")
+}
+
+// text draws text in Courier, flipped back upright, with its baseline below the given top
+func (c *pdfContent) text(x, y float64, text string, col color.Color) {
+	fmt.Fprintf(&c.ops, "BT %s /%s %s Tf 1 0 0 -1 %s %s Tm %s Tj ET\n",
+		pdf.FillColor(c.opaque(col)), pdf.Courier.ResourceName(), pdf.Number(pdfFontSize),
+		pdf.Number(x), pdf.Number(y+textHeight), pdf.EncodeText(text))
+}
+
+// path writes the operators that move through points
+func (c *pdfContent) path(points []point) {
+	for i, pt := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&c.ops, "%s %s %s ", pdf.Number(pt.x), pdf.Number(pt.y), op)
+	}
+}
@@ -0,0 +1,69 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"strings"
+)
+
+// Protocol is a way of showing images in a terminal
+type Protocol string
+
+// Graphics protocols
+const (
+	ProtocolNone   Protocol = "none"
+	ProtocolKitty  Protocol = "kitty"
+	ProtocolITerm2 Protocol = "iterm2"
+	ProtocolSixel  Protocol = "sixel"
+)
+
+// ProtocolNames lists the names accepted by --image
+var ProtocolNames = []string{"auto", "kitty", "iterm2", "sixel", "none"}
+
+// ParseProtocol returns the protocol with the given name. "auto" detects the
+// protocol the terminal supports.
+func ParseProtocol(name string) (Protocol, error) {
+	switch p := Protocol(strings.ToLower(name)); p {
+	case "auto":
+		return DetectProtocol(), nil
+	case ProtocolNone, ProtocolKitty, ProtocolITerm2, ProtocolSixel:
+		return p, nil
+	}
+	return ProtocolNone, fmt.Errorf("unknown image protocol %q: use %s", name, strings.Join(ProtocolNames, ", "))
+}
+
+// DetectProtocol guesses the graphics protocol the terminal supports from the
+// environment variables terminals set. Inside tmux and screen, which don't
+// pass images through unless configured to, no protocol is used.
+func DetectProtocol() Protocol {
+	termName := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(termName, "screen") || strings.HasPrefix(termName, "tmux"):
+		return ProtocolNone
+	case os.Getenv("KITTY_WINDOW_ID") != "" || termName == "xterm-kitty" || termName == "xterm-ghostty" || program == "ghostty":
+		return ProtocolKitty
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return ProtocolITerm2
+	case strings.HasPrefix(termName, "foot") || strings.HasPrefix(termName, "mlterm") || strings.Contains(termName, "sixel"):
+		return ProtocolSixel
+	}
+	return ProtocolNone
+}
+
+// Encode returns the escape sequence that shows an image in a terminal using
+// the protocol, scaled to the given number of columns and rows where the
+// protocol supports it
+func Encode(img image.Image, protocol Protocol, columns, rows int) (string, error) {
+	switch protocol {
+	case ProtocolKitty:
+		return encodeKitty(img, columns, rows)
+	case ProtocolITerm2:
+		return encodeITerm2(img, columns, rows)
+	case ProtocolSixel:
+		return encodeSixel(img), nil
+	}
+	return "", fmt.Errorf("no image protocol to show the chart with")
+}
@@ -0,0 +1,165 @@
+package graphics
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// circleSegments is the number of sides of the polygon drawn for a circle
+const circleSegments = 16
+
+// canvas is a surface that rasterises anti-aliased shapes and text onto an image
+type canvas struct {
+	img *image.RGBA
+}
+
+// newCanvas returns a canvas of the given size filled with a background color
+func newCanvas(width, height int, background color.Color) *canvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	return &canvas{img: img}
+}
+
+// path collects the outlines of a shape. Every outline is wound the same way
+// so overlapping ones, like the segments of a line and the circles at its
+// joints, add up instead of cancelling out.
+type path struct {
+	r      *vector.Rasterizer
+	origin point // the canvas position of the rasterizer's top left corner
+}
+
+// fillShape rasterises the outlines added by build in a color. Only the
+// given bounds are rasterised, so small shapes are cheap to draw.
+func (c *canvas) fillShape(bounds image.Rectangle, col color.Color, build func(p *path)) {
+	bounds = bounds.Intersect(c.img.Bounds())
+	if bounds.Empty() {
+		return
+	}
+	p := &path{
+		r:      vector.NewRasterizer(bounds.Dx(), bounds.Dy()),
+		origin: point{float64(bounds.Min.X), float64(bounds.Min.Y)},
+	}
+	build(p)
+	p.r.Draw(c.img, bounds, image.NewUniform(col), image.Point{})
+}
+
+// shapeBounds returns the pixels covered by points, widened by a margin
+func shapeBounds(points []point, margin float64) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, pt := range points {
+		minX, maxX = math.Min(minX, pt.x), math.Max(maxX, pt.x)
+		minY, maxY = math.Min(minY, pt.y), math.Max(maxY, pt.y)
+	}
+	return image.Rect(
+		int(math.Floor(minX-margin)), int(math.Floor(minY-margin)),
+		int(math.Ceil(maxX+margin)), int(math.Ceil(maxY+margin)),
+	)
+}
+
+// polygon adds a closed outline through the given points
+func (p *path) polygon(points ...point) {
+	p.r.MoveTo(float32(points[0].x-p.origin.x), float32(points[0].y-p.origin.y))
+	for _, pt := range points[1:] {
+		p.r.LineTo(float32(pt.x-p.origin.x), float32(pt.y-p.origin.y))
+	}
+	p.r.ClosePath()
+}
+
+// segment adds a straight line of the given width between two points
+func (p *path) segment(a, b point, width float64) {
+	dx, dy := b.x-a.x, b.y-a.y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	// Offset perpendicular to the line by half its width
+	nx, ny := -dy/length*width/2, dx/length*width/2
+	p.polygon(
+		point{a.x + nx, a.y + ny},
+		point{b.x + nx, b.y + ny},
+		point{b.x - nx, b.y - ny},
+		point{a.x - nx, a.y - ny},
+	)
+}
+
+// circle adds a filled circle, wound the same way as segments
+func (p *path) circle(center point, radius float64) {
+	points := make([]point, circleSegments)
+	for i := range points {
+		angle := -2 * math.Pi * float64(i) / circleSegments
+		points[i] = point{center.x + radius*math.Cos(angle), center.y + radius*math.Sin(angle)}
+	}
+	p.polygon(points...)
+}
+
+// rect fills a rectangle. Pixel-aligned rectangles are drawn without
+// anti-aliasing, for crisp grid lines.
+func (c *canvas) rect(x, y, width, height float64, col color.Color) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+width)), int(math.Round(y+height)))
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+// polyline draws a line of the given width through the points, with rounded joints
+func (c *canvas) polyline(points []point, width float64, col color.Color) {
+	c.fillShape(shapeBounds(points, width), col, func(p *path) {
+		for i, pt := range points {
+			if i > 0 {
+				p.segment(points[i-1], pt, width)
+			}
+			if len(points) > 1 {
+				p.circle(pt, width/2)
+			}
+		}
+	})
+}
+
+// dashed draws a dashed line between two points
+func (c *canvas) dashed(a, b point, width, dash, space float64, col color.Color) {
+	length := math.Hypot(b.x-a.x, b.y-a.y)
+	if length == 0 {
+		return
+	}
+	ux, uy := (b.x-a.x)/length, (b.y-a.y)/length
+	c.fillShape(shapeBounds([]point{a, b}, width), col, func(p *path) {
+		for start := 0.0; start < length; start += dash + space {
+			end := math.Min(start+dash, length)
+			p.segment(point{a.x + ux*start, a.y + uy*start}, point{a.x + ux*end, a.y + uy*end}, width)
+		}
+	})
+}
+
+// circle draws a filled circle
+func (c *canvas) circle(center point, radius float64, col color.Color) {
+	c.fillShape(shapeBounds([]point{center}, radius+1), col, func(p *path) {
+		p.circle(center, radius)
+	})
+}
+
+// polygon draws a filled polygon
+func (c *canvas) polygon(points []point, col color.Color) {
+	c.fillShape(shapeBounds(points, 1), col, func(p *path) {
+		p.polygon(points...)
+	})
+}
+
+// textFace is the bitmap font labels are drawn in
+var textFace = basicfont.Face7x13
+
+// text draws text with its top left corner at the given position
+func (c *canvas) text(x, y float64, text string, col color.Color) {
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(col),
+		Face: textFace,
+		Dot:  fixed.P(int(math.Round(x)), int(math.Round(y))+textHeight),
+	}
+	d.DrawString(text)
+}
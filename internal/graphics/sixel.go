@@ -0,0 +1,117 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+	"strings"
+)
+
+// sixelMaxColors is the number of color registers sixel terminals commonly have
+const sixelMaxColors = 256
+
+// encodeSixel encodes the image as sixels: bands six pixels high, drawn one
+// palette color at a time. Sixel images are shown at their size in pixels.
+func encodeSixel(img image.Image) string {
+	bounds := img.Bounds()
+	colors := sixelPalette(img)
+	paletted := image.NewPaletted(bounds, colors)
+	draw.Draw(paletted, bounds, img, bounds.Min, draw.Src)
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "\x1bPq\"1;1;%d;%d", width, height)
+	for i, c := range colors {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	sixels := make([]byte, width)
+	for top := 0; top < height; top += 6 {
+		// The colors used in this band
+		used := make([]bool, len(colors))
+		for y := top; y < min(top+6, height); y++ {
+			for x := 0; x < width; x++ {
+				used[paletted.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+y)] = true
+			}
+		}
+
+		for index := range colors {
+			if !used[index] {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				bits := byte(0)
+				for dy := 0; dy < 6 && top+dy < height; dy++ {
+					if int(paletted.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+top+dy)) == index {
+						bits |= 1 << dy
+					}
+				}
+				sixels[x] = '?' + bits
+			}
+			fmt.Fprintf(&out, "#%d", index)
+			writeSixelRuns(&out, sixels)
+			// Return to the start of the band for the next color
+			out.WriteByte('$')
+		}
+		out.WriteByte('-')
+	}
+
+	out.WriteString("\x1b\\")
+	return out.String()
+}
+
+// sixelPalette returns the image's colors when there are few enough for the
+// terminal's color registers, or else its most common colors. The chart is
+// mostly flat colors, so the rarer ones are anti-aliased edges that can take
+// the nearest common color.
+func sixelPalette(img image.Image) color.Palette {
+	bounds := img.Bounds()
+	counts := make(map[color.RGBA]int)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			counts[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]++
+		}
+	}
+
+	colors := make([]color.RGBA, 0, len(counts))
+	for c := range counts {
+		colors = append(colors, c)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if counts[colors[i]] != counts[colors[j]] {
+			return counts[colors[i]] > counts[colors[j]]
+		}
+		// Break ties by color so the palette doesn't depend on map order
+		return rgbKey(colors[i]) < rgbKey(colors[j])
+	})
+
+	p := make(color.Palette, 0, sixelMaxColors)
+	for _, c := range colors[:min(len(colors), sixelMaxColors)] {
+		p = append(p, c)
+	}
+	return p
+}
+
+// rgbKey packs a color's channels into one number for ordering
+func rgbKey(c color.RGBA) uint32 {
+	return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+}
+
+// writeSixelRuns writes a row of sixels, compressing runs of the same sixel
+func writeSixelRuns(out *strings.Builder, sixels []byte) {
+	for start := 0; start < len(sixels); {
+		end := start + 1
+		for end < len(sixels) && sixels[end] == sixels[start] {
+			end++
+		}
+		if n := end - start; n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, sixels[start])
+		} else {
+			out.Write(sixels[start:end])
+		}
+		start = end
+	}
+}
@@ -0,0 +1,42 @@
+package graphics
+
+import "image/color"
+
+// point is a position in pixels
+type point struct {
+	x, y float64
+}
+
+// surface is what a chart is drawn on, a raster image or an SVG document.
+// Positions are in pixels from the top left corner.
+type surface interface {
+	// rect fills a rectangle
+	rect(x, y, width, height float64, col color.Color)
+
+	// polyline draws a line of the given width through the points
+	polyline(points []point, width float64, col color.Color)
+
+	// dashed draws a dashed line between two points
+	dashed(a, b point, width, dash, space float64, col color.Color)
+
+	// circle draws a filled circle
+	circle(center point, radius float64, col color.Color)
+
+	// polygon draws a filled polygon
+	polygon(points []point, col color.Color)
+
+	// text draws a line of text with its top left corner at the given position
+	text(x, y float64, text string, col color.Color)
+}
+
+// Text is drawn in a 7×13 pixel monospace font, or a monospace font of the
+// same size in SVG
+const (
+	charWidth  = 7
+	textHeight = 11 // the height of capital letters, which labels are centered on
+)
+
+// textWidth returns the width of text in pixels
+func textWidth(text string) int {
+	return len([]rune(text)) * charWidth
+}
@@ -0,0 +1,97 @@
+package graphics
+
+import (
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// svgDocument is a surface that writes SVG elements
+type svgDocument struct {
+	body          strings.Builder
+	width, height int
+}
+
+// newSVGDocument returns an SVG document of the given size filled with a background color
+func newSVGDocument(width, height int, background color.Color) *svgDocument {
+	doc := &svgDocument{width: width, height: height}
+	doc.rect(0, 0, float64(width), float64(height), background)
+	return doc
+}
+
+// String returns the complete SVG document. It has no XML declaration so it
+// can also be embedded in HTML.
+func (d *svgDocument) String() string {
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="%d">
+%s</svg>
+`, d.width, d.height, d.width, d.height, svgFontSize, d.body.String())
+}
+
+// svgFontSize is the size of a monospace font with characters about as wide
+// as those of the raster font
+const svgFontSize = 12
+
+// rect writes a rectangle
+func (d *svgDocument) rect(x, y, width, height float64, col color.Color) {
+	fmt.Fprintf(&d.body, `<rect x="%s" y="%s" width="%s" height="%s"%s/>`+"\n",
+		svgNumber(x), svgNumber(y), svgNumber(width), svgNumber(height), svgPaint("fill", col))
+}
+
+// polyline writes a line with rounded joints
+func (d *svgDocument) polyline(points []point, width float64, col color.Color) {
+	fmt.Fprintf(&d.body, `<polyline points="%s" fill="none" stroke-width="%s" stroke-linejoin="round" stroke-linecap="round"%s/>`+"\n",
+		svgPoints(points), svgNumber(width), svgPaint("stroke", col))
+}
+
+// dashed writes a dashed line
+func (d *svgDocument) dashed(a, b point, width, dash, space float64, col color.Color) {
+	fmt.Fprintf(&d.body, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke-width="%s" stroke-dasharray="%s %s"%s/>`+"\n",
+		svgNumber(a.x), svgNumber(a.y), svgNumber(b.x), svgNumber(b.y),
+		svgNumber(width), svgNumber(dash), svgNumber(space), svgPaint("stroke", col))
+}
+
+// circle writes a filled circle
+func (d *svgDocument) circle(center point, radius float64, col color.Color) {
+	fmt.Fprintf(&d.body, `<circle cx="%s" cy="%s" r="%s"%s/>`+"\n",
+		svgNumber(center.x), svgNumber(center.y), svgNumber(radius), svgPaint("fill", col))
+}
+
+// polygon writes a filled polygon
+func (d *svgDocument) polygon(points []point, col color.Color) {
+	fmt.Fprintf(&d.body, `<polygon points="%s"%s/>`+"\n", svgPoints(points), svgPaint("fill", col))
+}
+
+// text writes text, placing its baseline below the given top
+func (d *svgDocument) text(x, y float64, text string, col color.Color) {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	fmt.Fprintf(&d.body, `<text x="%s" y="%s"%s>%s</text>`+"\n",
+		svgNumber(x), svgNumber(y+textHeight), svgPaint("fill", col), escaped.String())
+}
+
+// svgNumber formats a coordinate with at most two decimals
+func svgNumber(v float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.2f", v), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// svgPoints formats points for a polyline or polygon
+func svgPoints(points []point) string {
+	coords := make([]string, len(points))
+	for i, pt := range points {
+		coords[i] = svgNumber(pt.x) + "," + svgNumber(pt.y)
+	}
+	return strings.Join(coords, " ")
+}
+
+// svgPaint returns the attributes that paint a fill or stroke in a color,
+// with its opacity when it is translucent
+func svgPaint(attr string, col color.Color) string {
+	c := color.NRGBAModel.Convert(col).(color.NRGBA)
+	paint := fmt.Sprintf(` %s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A != 0xff {
+		paint += fmt.Sprintf(` %s-opacity="%s"`, attr, svgNumber(float64(c.A)/0xff))
+	}
+	return paint
+}
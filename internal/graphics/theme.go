@@ -0,0 +1,60 @@
+package graphics
+
+import "image/color"
+
+// Theme holds the colors a chart is drawn in
+type Theme struct {
+	Background color.RGBA
+	Grid       color.RGBA
+	Label      color.RGBA
+	Weight     color.RGBA
+	Trend      color.RGBA
+	Goal       color.RGBA
+	Milestone  color.RGBA    // lines of the milestones on the way to the goal
+	Event      color.RGBA    // markers and labels of entries with notes
+	EventGuide color.RGBA    // lines from the top of the plot down to the markers
+	Bands      []color.NRGBA // shades of the weight ranges of the BMI categories, in order
+}
+
+// DarkTheme matches the terminal palette the table is styled with, for
+// charts shown in the terminal or on screen
+var DarkTheme = Theme{
+	Background: color.RGBA{0x1c, 0x1c, 0x1c, 0xff},
+	Grid:       color.RGBA{0x3a, 0x3a, 0x3a, 0xff},
+	Label:      color.RGBA{0xa8, 0xa8, 0xa8, 0xff},
+	Weight:     color.RGBA{0x5f, 0xd7, 0xff, 0xff},
+	Trend:      color.RGBA{0xff, 0x5f, 0xd7, 0xff},
+	Goal:       color.RGBA{0xff, 0xd7, 0x5f, 0xff},
+	Milestone:  color.RGBA{0xd7, 0xaf, 0x87, 0xff},
+	Event:      color.RGBA{0xe4, 0xe4, 0xe4, 0xff},
+	EventGuide: color.RGBA{0x6c, 0x6c, 0x6c, 0xff},
+	Bands: []color.NRGBA{
+		{0x5f, 0x87, 0xff, 0xff},
+		{0x5f, 0xd7, 0x5f, 0xff},
+		{0xff, 0xaf, 0x5f, 0xff},
+		{0xff, 0x87, 0x5f, 0xff},
+		{0xff, 0x5f, 0x5f, 0xff},
+		{0xd7, 0x00, 0x5f, 0xff},
+	},
+}
+
+// LightTheme is for charts that are printed
+var LightTheme = Theme{
+	Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+	Grid:       color.RGBA{0xdd, 0xdd, 0xdd, 0xff},
+	Label:      color.RGBA{0x55, 0x55, 0x55, 0xff},
+	Weight:     color.RGBA{0x00, 0x6f, 0xb8, 0xff},
+	Trend:      color.RGBA{0xc0, 0x1c, 0x6b, 0xff},
+	Goal:       color.RGBA{0xc0, 0x78, 0x00, 0xff},
+	Milestone:  color.RGBA{0x87, 0x6a, 0x4a, 0xff},
+	Event:      color.RGBA{0x30, 0x30, 0x30, 0xff},
+	EventGuide: color.RGBA{0xa8, 0xa8, 0xa8, 0xff},
+	Bands: []color.NRGBA{
+		{0x00, 0x5f, 0xff, 0xff},
+		{0x00, 0xaf, 0x00, 0xff},
+		{0xff, 0x87, 0x00, 0xff},
+		{0xff, 0x5f, 0x00, 0xff},
+		{0xff, 0x00, 0x00, 0xff},
+		{0xaf, 0x00, 0x00, 0xff},
+	},
+}
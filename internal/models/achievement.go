@@ -0,0 +1,68 @@
+package models
+
+import (
+	"github.com/tryonlinux/thicc/internal/database"
+)
+
+// Achievement is a milestone recorded when an entry reached it
+type Achievement struct {
+	ID        int
+	Key       string // identifies the milestone, e.g. "lost-2"
+	Title     string // describes the milestone in the units used when it was reached
+	Date      string // date of the entry that reached it
+	WeightID  int    // the entry that reached it
+	CreatedAt string
+}
+
+// RecordAchievement stores an achievement unless one with the same key is
+// already recorded, and reports whether it was new
+func RecordAchievement(db *database.DB, a Achievement) (bool, error) {
+	result, err := db.Exec(
+		"INSERT OR IGNORE INTO achievements (key, title, date, weight_id) VALUES (?, ?, ?, ?)",
+		a.Key, a.Title, a.Date, a.WeightID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// GetAchievements retrieves all recorded achievements, newest first
+func GetAchievements(db *database.DB) ([]Achievement, error) {
+	rows, err := db.Query("SELECT id, key, title, date, weight_id, COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), '') FROM achievements ORDER BY date DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var achievements []Achievement
+	for rows.Next() {
+		var a Achievement
+		if err := rows.Scan(&a.ID, &a.Key, &a.Title, &a.Date, &a.WeightID, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		achievements = append(achievements, a)
+	}
+
+	return achievements, rows.Err()
+}
+
+// PruneAchievements deletes the achievements of entries that were deleted or
+// moved to the trash, so achievements only ever point at current entries
+func PruneAchievements(db *database.DB) error {
+	_, err := db.Exec("DELETE FROM achievements WHERE weight_id NOT IN (SELECT id FROM weights WHERE " + notTrashed + ")")
+	return err
+}
+
+// DeleteAchievements deletes achievements by ID, such as ones the entries no
+// longer reach after a change
+func DeleteAchievements(db *database.DB, ids ...int) error {
+	for _, id := range ids {
+		if _, err := db.Exec("DELETE FROM achievements WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,414 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/tryonlinux/thicc/internal/database"
+)
+
+// Goal statuses
+const (
+	GoalActive    = "active"
+	GoalAchieved  = "achieved"
+	GoalAbandoned = "abandoned"
+)
+
+// ErrNoActiveGoal is returned when there is no active goal to change
+var ErrNoActiveGoal = errors.New("no active goal")
+
+// Goal is a target weight set with the goal command, or an intermediate
+// milestone on the way to one
+type Goal struct {
+	ID          int
+	ParentID    int     // the goal a milestone belongs to; zero for goals
+	Target      float64 // target weight in the user's unit; the middle of the range for a range goal
+	RangeLow    float64 // bottom of a maintenance range; zero for a single target
+	RangeHigh   float64 // top of a maintenance range
+	StartDate   string  // YYYY-MM-DD the goal was set
+	StartWeight float64 // latest weight when the goal was set; zero before the first entry
+	Deadline    string  // optional YYYY-MM-DD to reach the target by
+	Status      string  // GoalActive, GoalAchieved or GoalAbandoned
+	EndDate     string  // date the goal was achieved or abandoned
+	CreatedAt   string
+}
+
+// IsMilestone reports whether the goal is a milestone of another goal
+func (g Goal) IsMilestone() bool {
+	return g.ParentID != 0
+}
+
+// IsRange reports whether the goal is to stay within a maintenance range
+func (g Goal) IsRange() bool {
+	return g.RangeHigh > g.RangeLow
+}
+
+// TargetFrom returns the weight to aim for from a weight: the target, or the
+// nearest weight within a range goal's range
+func (g Goal) TargetFrom(weight float64) float64 {
+	if g.IsRange() {
+		return math.Min(math.Max(weight, g.RangeLow), g.RangeHigh)
+	}
+	return g.Target
+}
+
+// Reached reports whether a weight reaches the target, coming from the
+// starting weight, or is within a range goal's range. Without a starting
+// weight nothing reaches it.
+func (g Goal) Reached(weight float64) bool {
+	switch {
+	case g.StartWeight == 0:
+		return false
+	case g.IsRange():
+		return weight == g.TargetFrom(weight)
+	case g.StartWeight >= g.Target:
+		return weight <= g.Target
+	}
+	return weight >= g.Target
+}
+
+// goalColumns is the column list used by every goal query, in scan order
+const goalColumns = "id, parent_id, target, range_low, range_high, start_date, start_weight, deadline, status, end_date, COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), '')"
+
+// activeGoalQuery selects the active goal, without milestones
+const activeGoalQuery = "SELECT " + goalColumns + " FROM goals WHERE parent_id = 0 AND status = ? ORDER BY id DESC LIMIT 1"
+
+// InsertGoal stores a new goal or milestone and returns its ID
+func InsertGoal(db *database.DB, g Goal) (int, error) {
+	return insertGoal(db, g)
+}
+
+// insertGoal is InsertGoal on the database or within a transaction
+func insertGoal(db querier, g Goal) (int, error) {
+	if g.Status == "" {
+		g.Status = GoalActive
+	}
+	result, err := db.Exec(
+		"INSERT INTO goals (parent_id, target, range_low, range_high, start_date, start_weight, deadline, status, end_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		g.ParentID, g.Target, g.RangeLow, g.RangeHigh, g.StartDate, g.StartWeight, g.Deadline, g.Status, g.EndDate,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// GetGoals retrieves every goal, without milestones, newest first
+func GetGoals(db *database.DB) ([]Goal, error) {
+	return queryGoals(db, "SELECT "+goalColumns+" FROM goals WHERE parent_id = 0 ORDER BY start_date DESC, id DESC")
+}
+
+// GetActiveGoal retrieves the active goal, or nil when there is none
+func GetActiveGoal(db *database.DB) (*Goal, error) {
+	goals, err := queryGoals(db, activeGoalQuery, GoalActive)
+	if err != nil || len(goals) == 0 {
+		return nil, err
+	}
+	return &goals[0], nil
+}
+
+// GetMilestones retrieves the milestones of a goal, in the order they are reached
+func GetMilestones(db *database.DB, goalID int) ([]Goal, error) {
+	milestones, err := queryGoals(db, "SELECT "+goalColumns+" FROM goals WHERE parent_id = ? ORDER BY target", goalID)
+	if err != nil || len(milestones) == 0 {
+		return nil, err
+	}
+
+	// Milestones on the way down are reached heaviest first
+	if milestones[0].StartWeight >= milestones[0].Target {
+		for i, j := 0, len(milestones)-1; i < j; i, j = i+1, j-1 {
+			milestones[i], milestones[j] = milestones[j], milestones[i]
+		}
+	}
+	return milestones, nil
+}
+
+// CaptureGoals returns full copies of the goals and milestones with the given
+// IDs, for the journal. IDs that do not exist are skipped.
+func CaptureGoals(db *database.DB, ids ...int) ([]Goal, error) {
+	return captureGoals(db, ids...)
+}
+
+// captureGoals is CaptureGoals on the database or within a transaction
+func captureGoals(db querier, ids ...int) ([]Goal, error) {
+	var goals []Goal
+	for _, id := range ids {
+		g, err := queryGoals(db, "SELECT "+goalColumns+" FROM goals WHERE id = ?", id)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, g...)
+	}
+	return goals, nil
+}
+
+// CaptureAllGoals returns full copies of every goal and milestone
+func CaptureAllGoals(db *database.DB) ([]Goal, error) {
+	return captureAllGoals(db)
+}
+
+// captureAllGoals is CaptureAllGoals on the database or within a transaction
+func captureAllGoals(db querier) ([]Goal, error) {
+	return queryGoals(db, "SELECT "+goalColumns+" FROM goals ORDER BY id")
+}
+
+// captureActiveGoals returns full copies of the active goals and milestones,
+// the ones a new entry can change
+func captureActiveGoals(db querier) ([]Goal, error) {
+	return queryGoals(db, "SELECT "+goalColumns+" FROM goals WHERE status = ? ORDER BY id", GoalActive)
+}
+
+// EndGoal marks a goal and its active milestones achieved or abandoned on a
+// date. Milestones still active when a goal ends are abandoned with it.
+func EndGoal(db *database.DB, id int, status, date string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := endGoal(tx, id, status, date); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// endGoal ends a goal and its active milestones within a transaction
+func endGoal(tx *sql.Tx, id int, status, date string) error {
+	if _, err := tx.Exec("UPDATE goals SET status = ?, end_date = ? WHERE id = ?", status, date, id); err != nil {
+		return err
+	}
+	_, err := tx.Exec("UPDATE goals SET status = ?, end_date = ? WHERE parent_id = ? AND status = ?", GoalAbandoned, date, id, GoalActive)
+	return err
+}
+
+// SeedGoal records the goal_weight setting as the active goal when no goals
+// have been recorded yet, such as for databases from before goals were
+// tracked. It starts on the date of the oldest entry, at its weight.
+func SeedGoal(db *database.DB, settings *Settings) error {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM goals").Scan(&count); err != nil || count > 0 {
+		return err
+	}
+
+	g := Goal{Target: settings.GoalWeight, StartDate: GetTodayDate()}
+	err := db.QueryRow("SELECT date, weight FROM weights WHERE deleted_at IS NULL ORDER BY date ASC, time ASC, id ASC LIMIT 1").
+		Scan(&g.StartDate, &g.StartWeight)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	_, err = InsertGoal(db, g)
+	return err
+}
+
+// UpdateGoalProgress records an entry's progress on the active goals and
+// milestones that started on or before its date. Goals set before the first
+// entry start from it. It returns the goals and milestones the entry reached,
+// which are marked achieved. Range goals stay active, since maintaining the
+// range goes on after reaching it.
+func UpdateGoalProgress(db *database.DB, w Weight) ([]Goal, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	reached, err := updateGoalProgress(tx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	return reached, tx.Commit()
+}
+
+// updateGoalProgress records an entry's progress on goals within a transaction
+func updateGoalProgress(db *sql.Tx, w Weight) ([]Goal, error) {
+	active, err := queryGoals(db, "SELECT "+goalColumns+" FROM goals WHERE status = ? AND start_date <= ? ORDER BY parent_id DESC, id", GoalActive, w.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var reached []Goal
+	for _, g := range active {
+		if g.StartWeight == 0 {
+			if _, err := db.Exec("UPDATE goals SET start_weight = ? WHERE id = ?", w.Weight, g.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if g.IsRange() || !g.Reached(w.Weight) {
+			continue
+		}
+		if err := endGoal(db, g.ID, GoalAchieved, w.Date); err != nil {
+			return nil, err
+		}
+		g.Status, g.EndDate = GoalAchieved, w.Date
+		reached = append(reached, g)
+	}
+
+	return reached, nil
+}
+
+// SetGoalWeight stores the goal_weight setting, the goal drawn on the graph
+func SetGoalWeight(db *database.DB, weight float64) error {
+	return setGoalWeight(db, weight)
+}
+
+// setGoalWeight is SetGoalWeight on the database or within a transaction
+func setGoalWeight(db querier, weight float64) error {
+	return setSetting(db, "goal_weight", strconv.FormatFloat(weight, 'f', 2, 64))
+}
+
+// SetGoal replaces the active goal, which is abandoned, with a new goal and
+// milestones at the given targets, which start with it, and stores its target
+// as the goal_weight setting. The change is journaled with the given summary,
+// all in a single transaction. It returns the ID of the new goal.
+func SetGoal(db *database.DB, goal Goal, milestones []float64, summary string) (int, error) {
+	var id int
+	err := journal(db, "goal", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		before, err := goalSnapshot(tx)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+
+		active, err := queryGoals(tx, activeGoalQuery, GoalActive)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		for _, g := range active {
+			if err := endGoal(tx, g.ID, GoalAbandoned, goal.StartDate); err != nil {
+				return "", Snapshot{}, Snapshot{}, err
+			}
+		}
+		if id, err = insertGoal(tx, goal); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		for _, target := range milestones {
+			milestone := goal
+			milestone.ParentID, milestone.Target, milestone.Deadline = id, target, ""
+			milestone.RangeLow, milestone.RangeHigh = 0, 0
+			if _, err := insertGoal(tx, milestone); err != nil {
+				return "", Snapshot{}, Snapshot{}, err
+			}
+		}
+		if err := setGoalWeight(tx, goal.Target); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+
+		after, err := goalSnapshot(tx)
+		return summary, before, after, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// AbandonGoal marks a goal and its active milestones abandoned on a date and
+// journals it with the given summary, in a single transaction
+func AbandonGoal(db *database.DB, id int, date, summary string) error {
+	return journal(db, "goal", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		before, err := goalSnapshot(tx)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if err := endGoal(tx, id, GoalAbandoned, date); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		after, err := goalSnapshot(tx)
+		return summary, before, after, err
+	})
+}
+
+// AddMilestone stores a milestone and journals it with the given summary, in
+// a single transaction
+func AddMilestone(db *database.DB, milestone Goal, summary string) error {
+	return journal(db, "goal", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		id, err := insertGoal(tx, milestone)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		added, err := captureGoals(tx, id)
+		return summary, Snapshot{}, Snapshot{Goals: added}, err
+	})
+}
+
+// goalSnapshot captures the goal_weight setting and every goal, for the journal
+func goalSnapshot(tx *sql.Tx) (Snapshot, error) {
+	var snapshot Snapshot
+	var err error
+	if snapshot.Settings, err = captureSettings(tx, "goal_weight"); err != nil {
+		return snapshot, err
+	}
+	snapshot.Goals, err = captureAllGoals(tx)
+	return snapshot, err
+}
+
+// activeMilestoneTargets returns the targets of a goal's milestones that
+// haven't been reached yet
+func activeMilestoneTargets(db *database.DB, goalID int) ([]float64, error) {
+	rows, err := db.Query("SELECT target FROM goals WHERE status = ? AND parent_id = ? ORDER BY target", GoalActive, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []float64
+	for rows.Next() {
+		var target float64
+		if err := rows.Scan(&target); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, rows.Err()
+}
+
+// loadActiveGoal fills in the settings that come from the active goal: its
+// start date, deadline, range and the targets of its milestones not reached yet
+func loadActiveGoal(db *database.DB, settings *Settings) error {
+	goal, err := GetActiveGoal(db)
+	if err != nil || goal == nil {
+		return err
+	}
+	settings.GoalStart, settings.Deadline = goal.StartDate, goal.Deadline
+	settings.GoalLow, settings.GoalHigh = goal.RangeLow, goal.RangeHigh
+	settings.Milestones, err = activeMilestoneTargets(db, goal.ID)
+	return err
+}
+
+// goalIDs returns the IDs of goals
+func goalIDs(goals []Goal) []int {
+	ids := make([]int, len(goals))
+	for i, g := range goals {
+		ids[i] = g.ID
+	}
+	return ids
+}
+
+// queryGoals runs a query selecting goal rows
+func queryGoals(db querier, query string, args ...any) ([]Goal, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []Goal
+	for rows.Next() {
+		var g Goal
+		if err := rows.Scan(&g.ID, &g.ParentID, &g.Target, &g.RangeLow, &g.RangeHigh, &g.StartDate, &g.StartWeight, &g.Deadline, &g.Status, &g.EndDate, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		goals = append(goals, g)
+	}
+
+	return goals, rows.Err()
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/database"
+)
+
+// ImportGrowthReference replaces the BMI-for-age growth reference of the
+// sexes in values, in one transaction. The reference is kept apart from the
+// user's data, so reset leaves it in place.
+func ImportGrowthReference(db *database.DB, values []calculator.LMS) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	cleared := make(map[string]bool)
+	for _, v := range values {
+		if !cleared[v.Sex] {
+			if _, err := tx.Exec("DELETE FROM growth_references WHERE sex = ?", v.Sex); err != nil {
+				return err
+			}
+			cleared[v.Sex] = true
+		}
+		if _, err := tx.Exec("INSERT OR REPLACE INTO growth_references (sex, age_months, l, m, s) VALUES (?, ?, ?, ?, ?)",
+			v.Sex, v.AgeMonths, v.L, v.M, v.S); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetGrowthReference retrieves the BMI-for-age growth reference: the imported
+// one for the sexes it covers, and the CDC's shipped with thicc for the others
+func GetGrowthReference(db *database.DB) (calculator.LMSTable, error) {
+	rows, err := db.Query("SELECT sex, age_months, l, m, s FROM growth_references")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []calculator.LMS
+	for rows.Next() {
+		var v calculator.LMS
+		if err := rows.Scan(&v.Sex, &v.AgeMonths, &v.L, &v.M, &v.S); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	table := calculator.NewLMSTable(values)
+	for sex, values := range calculator.NewLMSTable(calculator.CDCGrowthReference()) {
+		if len(table[sex]) == 0 {
+			table[sex] = values
+		}
+	}
+	return table, nil
+}
@@ -0,0 +1,338 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/tryonlinux/thicc/internal/database"
+)
+
+// Journal errors
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// Snapshot is an image of the rows touched by an operation.
+// Weights holds full copies of the affected weight entries, Settings the
+// affected setting keys and Goals the affected goals and milestones. A row present in one image but missing from the other
+// was created or deleted by the operation.
+type Snapshot struct {
+	Weights  []Weight          `json:"weights,omitempty"`
+	Settings map[string]string `json:"settings,omitempty"`
+	Goals    []Goal            `json:"goals,omitempty"`
+}
+
+// Operation is a journaled change that can be undone and redone
+type Operation struct {
+	ID        int
+	Command   string
+	Summary   string
+	Before    Snapshot
+	After     Snapshot
+	Undone    bool
+	CreatedAt string
+}
+
+// CaptureWeights returns full copies of the weight entries with the given IDs,
+// including trashed entries. IDs that do not exist are skipped.
+func CaptureWeights(db *database.DB, ids ...int) ([]Weight, error) {
+	return captureWeights(db, ids...)
+}
+
+// captureWeights is CaptureWeights on the database or within a transaction
+func captureWeights(db querier, ids ...int) ([]Weight, error) {
+	var weights []Weight
+	for _, id := range ids {
+		w, err := getWeight(db, "SELECT "+weightColumns+" FROM weights WHERE id = ?", id)
+		if err == ErrWeightNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		weights = append(weights, *w)
+	}
+	return weights, nil
+}
+
+// CaptureAllWeights returns full copies of every weight entry, including trashed entries
+func CaptureAllWeights(db *database.DB) ([]Weight, error) {
+	return captureAllWeights(db)
+}
+
+// captureAllWeights is CaptureAllWeights on the database or within a transaction
+func captureAllWeights(db querier) ([]Weight, error) {
+	return queryWeights(db, "SELECT "+weightColumns+" FROM weights ORDER BY id")
+}
+
+// CaptureSettings returns the current values of the given setting keys.
+// Keys that are not set are left out. With no keys, every setting is returned.
+func CaptureSettings(db *database.DB, keys ...string) (map[string]string, error) {
+	return captureSettings(db, keys...)
+}
+
+// captureSettings is CaptureSettings on the database or within a transaction
+func captureSettings(db querier, keys ...string) (map[string]string, error) {
+	query := "SELECT key, value FROM settings"
+	var args []any
+	if len(keys) > 0 {
+		query += " WHERE key IN (?" + strings.Repeat(", ?", len(keys)-1) + ")"
+		for _, key := range keys {
+			args = append(args, key)
+		}
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+
+	return values, rows.Err()
+}
+
+// RecordOperation adds an operation to the journal. Recording a new operation
+// discards any undone operations, so they can no longer be redone.
+func RecordOperation(db *database.DB, command, summary string, before, after Snapshot) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordOperation(tx, command, summary, before, after); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// recordOperation adds an operation to the journal within a transaction,
+// discarding any undone operations
+func recordOperation(tx *sql.Tx, command, summary string, before, after Snapshot) error {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM operations WHERE undone = 1"); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO operations (command, summary, before, after) VALUES (?, ?, ?, ?)",
+		command, summary, string(beforeJSON), string(afterJSON),
+	)
+	return err
+}
+
+// journal makes a change and records it as an operation in one transaction,
+// so a change is never kept without the journal entry that undoes it. The
+// change returns its summary and the images of the rows it touched.
+func journal(db *database.DB, command string, change func(tx *sql.Tx) (string, Snapshot, Snapshot, error)) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	summary, before, after, err := change(tx)
+	if err != nil {
+		return err
+	}
+	if err := recordOperation(tx, command, summary, before, after); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UndoOperation reverts the most recent operation that has not been undone
+func UndoOperation(db *database.DB) (*Operation, error) {
+	op, err := getOperation(db, "SELECT "+operationColumns+" FROM operations WHERE undone = 0 ORDER BY id DESC LIMIT 1")
+	if err == sql.ErrNoRows {
+		return nil, ErrNothingToUndo
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := switchSnapshot(db, op.ID, op.After, op.Before, true); err != nil {
+		return nil, err
+	}
+	op.Undone = true
+
+	return op, nil
+}
+
+// RedoOperation reapplies the oldest undone operation, which is the one undone most recently
+func RedoOperation(db *database.DB) (*Operation, error) {
+	op, err := getOperation(db, "SELECT "+operationColumns+" FROM operations WHERE undone = 1 ORDER BY id ASC LIMIT 1")
+	if err == sql.ErrNoRows {
+		return nil, ErrNothingToRedo
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := switchSnapshot(db, op.ID, op.Before, op.After, false); err != nil {
+		return nil, err
+	}
+	op.Undone = false
+
+	return op, nil
+}
+
+// GetOperations retrieves the last N journaled operations, newest first
+func GetOperations(db *database.DB, limit int) ([]Operation, error) {
+	rows, err := db.Query("SELECT "+operationColumns+" FROM operations ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ops []Operation
+	for rows.Next() {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, *op)
+	}
+
+	return ops, rows.Err()
+}
+
+// PurgeOperations deletes the whole journal, including the data images it holds
+func PurgeOperations(db *database.DB) error {
+	_, err := db.Exec("DELETE FROM operations")
+	return err
+}
+
+// operationColumns is the column list used by every operation query, in scan order
+const operationColumns = "id, command, summary, before, after, undone, created_at"
+
+// querier runs statements on the database directly or within a transaction.
+// It is implemented by both *database.DB and *sql.Tx.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// getOperation runs a query selecting a single operation
+func getOperation(db *database.DB, query string) (*Operation, error) {
+	return scanOperation(db.QueryRow(query))
+}
+
+// scanOperation scans an operation row and decodes its snapshots
+func scanOperation(row rowScanner) (*Operation, error) {
+	var op Operation
+	var beforeJSON, afterJSON string
+	if err := row.Scan(&op.ID, &op.Command, &op.Summary, &beforeJSON, &afterJSON, &op.Undone, &op.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(beforeJSON), &op.Before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(afterJSON), &op.After); err != nil {
+		return nil, err
+	}
+
+	return &op, nil
+}
+
+// switchSnapshot replaces the rows in the from image with the rows in the to
+// image and marks the operation undone or not, all in one transaction
+func switchSnapshot(db *database.DB, opID int, from, to Snapshot, undone bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Remove weights that only exist in the from image
+	keepWeights := make(map[int]bool)
+	for _, w := range to.Weights {
+		keepWeights[w.ID] = true
+	}
+	for _, w := range from.Weights {
+		if !keepWeights[w.ID] {
+			if _, err := tx.Exec("DELETE FROM weights WHERE id = ?", w.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Restore weights from the to image, keeping their IDs, creation times and trash state
+	for _, w := range to.Weights {
+		_, err := tx.Exec(
+			"INSERT OR REPLACE INTO weights (id, date, time, weight, bmi, note, created_at, deleted_at) VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))",
+			w.ID, w.Date, w.Time, w.Weight, w.BMI, w.Note, w.CreatedAt, w.DeletedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Same for settings
+	for key := range from.Settings {
+		if _, ok := to.Settings[key]; !ok {
+			if _, err := tx.Exec("DELETE FROM settings WHERE key = ?", key); err != nil {
+				return err
+			}
+		}
+	}
+	for key, value := range to.Settings {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value); err != nil {
+			return err
+		}
+	}
+
+	// And goals
+	keepGoals := make(map[int]bool)
+	for _, g := range to.Goals {
+		keepGoals[g.ID] = true
+	}
+	for _, g := range from.Goals {
+		if !keepGoals[g.ID] {
+			if _, err := tx.Exec("DELETE FROM goals WHERE id = ?", g.ID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, g := range to.Goals {
+		_, err := tx.Exec(
+			"INSERT OR REPLACE INTO goals (id, parent_id, target, range_low, range_high, start_date, start_weight, deadline, status, end_date, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			g.ID, g.ParentID, g.Target, g.RangeLow, g.RangeHigh, g.StartDate, g.StartWeight, g.Deadline, g.Status, g.EndDate, g.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE operations SET undone = ? WHERE id = ?", undone, opID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -4,19 +4,171 @@ import (
 	"bufio"
 	"database/sql"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/tryonlinux/thicc/internal/calculator"
 	"github.com/tryonlinux/thicc/internal/database"
 )
 
 // Settings represents application settings
 type Settings struct {
-	WeightUnit string  // "lbs" or "kg"
-	HeightUnit string  // "in" or "cm"
-	Height     float64 // height in the specified unit
-	GoalWeight float64 // goal weight in the specified unit
+	WeightUnit  string              // "lbs", "kg" or "st"
+	HeightUnit  string              // "in" or "cm"
+	Height      float64             // height in the specified unit
+	GoalWeight  float64             // goal weight in the specified unit
+	DateOrder   string              // day/month order for numeric dates: "mdy" or "dmy"
+	Locale      string              // language code used to parse numbers, e.g. "en" or "de"
+	Precision   *int                // decimals to show weights with; nil picks them from the increment or unit
+	Increment   float64             // smallest step of the user's scale, e.g. 0.2; zero when not set
+	Milestones  []float64           // targets of the active goal's milestones not reached yet
+	Deadline    string              // date the active goal is to be reached by, if it has one
+	GoalStart   string              // date the active goal was set
+	GoalLow     float64             // bottom of the active goal's maintenance range; zero without one
+	GoalHigh    float64             // top of the active goal's maintenance range
+	Sex         string              // "male" or "female" for the ideal weight formulas and growth reference; empty when not set
+	BMIStandard string              // key of the BMI cut-offs to categorize BMIs by, e.g. "who"
+	BirthDate   string              // YYYY-MM-DD, for BMI-for-age percentiles of children; empty when not set
+	Growth      calculator.LMSTable // BMI-for-age growth reference, imported or the CDC's
+}
+
+// AgeMonths returns the age in months on a YYYY-MM-DD date, and false when
+// the birth date isn't set
+func (s *Settings) AgeMonths(date string) (float64, bool) {
+	if s.BirthDate == "" {
+		return 0, false
+	}
+	birth, err := time.Parse("2006-01-02", s.BirthDate)
+	if err != nil {
+		return 0, false
+	}
+	on, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0, false
+	}
+	return on.Sub(birth).Hours() / 24 / daysPerMonth, true
+}
+
+// daysPerMonth is the average length of a month, to measure ages in months
+const daysPerMonth = 365.25 / 12
+
+// BMICutoffs returns the BMI standard BMIs are categorized by
+func (s *Settings) BMICutoffs() calculator.BMIStandard {
+	return calculator.BMIStandardFor(s.BMIStandard)
+}
+
+// HasGoalRange reports whether the goal is to stay within a maintenance range
+func (s *Settings) HasGoalRange() bool {
+	return s.GoalHigh > s.GoalLow
+}
+
+// GoalFrom returns the goal weight to aim for from a weight: the goal weight,
+// or the nearest weight within the goal range
+func (s *Settings) GoalFrom(weight float64) float64 {
+	if s.HasGoalRange() {
+		return math.Min(math.Max(weight, s.GoalLow), s.GoalHigh)
+	}
+	return s.GoalWeight
+}
+
+// DefaultPrecision returns the decimals weights are shown with when neither a
+// precision nor an increment is set. For stones it applies to the pounds.
+func DefaultPrecision(unit string) int {
+	if unit == "st" {
+		return 1
+	}
+	return 2
+}
+
+// WeightDecimals returns the number of decimals weights are shown with
+func (s *Settings) WeightDecimals() int {
+	if s.Precision != nil {
+		return *s.Precision
+	}
+	if s.Increment > 0 {
+		return calculator.IncrementDecimals(s.Increment)
+	}
+	return DefaultPrecision(s.WeightUnit)
+}
+
+// RoundWeight rounds a weight typed by the user to the scale increment, or to
+// the precision when only that is set, so stored values match what the scale
+// shows. For stones the rounding applies to the pounds.
+func (s *Settings) RoundWeight(weight float64) float64 {
+	step := s.Increment
+	if step == 0 && s.Precision != nil {
+		step = math.Pow(10, -float64(*s.Precision))
+	}
+	if step == 0 {
+		return weight
+	}
+
+	if s.WeightUnit == "st" {
+		return calculator.RoundToIncrement(weight*calculator.LbsPerSt, step) / calculator.LbsPerSt
+	}
+	return calculator.RoundToIncrement(weight, step)
+}
+
+// Optional setting keys. Unlike the settings asked for during setup, these
+// can be changed with the config command and fall back to a default when unset.
+const (
+	DateOrderKey   = "date_order"
+	LocaleKey      = "locale"
+	PrecisionKey   = "precision"
+	IncrementKey   = "increment"
+	SexKey         = "sex"
+	BMIStandardKey = "bmi_standard"
+	BirthDateKey   = "birth_date"
+)
+
+// SettingDefaults holds the default value of each optional setting
+var SettingDefaults = map[string]string{
+	DateOrderKey:   "mdy",
+	LocaleKey:      "en",
+	PrecisionKey:   "auto",
+	IncrementKey:   "none",
+	SexKey:         "none",
+	BMIStandardKey: calculator.WHOStandard,
+	BirthDateKey:   "none",
+}
+
+// SetSetting stores a setting value
+func SetSetting(db *database.DB, key, value string) error {
+	return setSetting(db, key, value)
+}
+
+// SetSettingJournaled sets a setting value and journals the change as a config
+// operation, in one transaction, so it can be undone
+func SetSettingJournaled(db *database.DB, key, value, summary string) error {
+	return journal(db, "config", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		before, err := captureSettings(tx, key)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if err := setSetting(tx, key, value); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		return summary, Snapshot{Settings: before}, Snapshot{Settings: map[string]string{key: value}}, nil
+	})
+}
+
+// setSetting is SetSetting on the database or within a transaction
+func setSetting(db querier, key, value string) error {
+	_, err := db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value)
+	return err
+}
+
+// getOptionalSetting retrieves an optional setting, falling back to its default
+func getOptionalSetting(db *database.DB, key string) (string, error) {
+	var value string
+	err := db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return SettingDefaults[key], nil
+	}
+	return value, err
 }
 
 // GetSettings retrieves current application settings
@@ -56,12 +208,76 @@ func GetSettings(db *database.DB) (*Settings, error) {
 		return nil, err
 	}
 
-	return &Settings{
+	dateOrder, err := getOptionalSetting(db, DateOrderKey)
+	if err != nil {
+		return nil, err
+	}
+
+	locale, err := getOptionalSetting(db, LocaleKey)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &Settings{
 		WeightUnit: weightUnit,
 		HeightUnit: heightUnit,
 		Height:     height,
 		GoalWeight: goalWeight,
-	}, nil
+		DateOrder:  dateOrder,
+		Locale:     locale,
+	}
+
+	precision, err := getOptionalSetting(db, PrecisionKey)
+	if err != nil {
+		return nil, err
+	}
+	if precision != "auto" {
+		decimals, err := strconv.Atoi(precision)
+		if err != nil {
+			return nil, err
+		}
+		settings.Precision = &decimals
+	}
+
+	increment, err := getOptionalSetting(db, IncrementKey)
+	if err != nil {
+		return nil, err
+	}
+	if increment != "none" {
+		settings.Increment, err = strconv.ParseFloat(increment, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sex, err := getOptionalSetting(db, SexKey)
+	if err != nil {
+		return nil, err
+	}
+	if sex != "none" {
+		settings.Sex = sex
+	}
+
+	if settings.BMIStandard, err = getOptionalSetting(db, BMIStandardKey); err != nil {
+		return nil, err
+	}
+
+	birthDate, err := getOptionalSetting(db, BirthDateKey)
+	if err != nil {
+		return nil, err
+	}
+	if birthDate != "none" {
+		settings.BirthDate = birthDate
+		if settings.Growth, err = GetGrowthReference(db); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadActiveGoal(db, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
 }
 
 // SetupSettings prompts the user for initial settings
@@ -69,21 +285,22 @@ func SetupSettings(db *database.DB) (*Settings, error) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println("\n=== First Time Setup ===")
-	fmt.Println("Please configure your preferences.\n")
+	fmt.Println("Please configure your preferences.")
+	fmt.Println()
 
 	// Get weight unit
 	var weightUnit string
 	for {
-		fmt.Print("Weight unit (lbs/kg): ")
+		fmt.Print("Weight unit (lbs/kg/st): ")
 		input, err := reader.ReadString('\n')
 		if err != nil {
 			return nil, err
 		}
 		weightUnit = strings.TrimSpace(strings.ToLower(input))
-		if weightUnit == "lbs" || weightUnit == "kg" {
+		if weightUnit == "lbs" || weightUnit == "kg" || weightUnit == "st" {
 			break
 		}
-		fmt.Println("Invalid input. Please enter 'lbs' or 'kg'.")
+		fmt.Println("Invalid input. Please enter 'lbs', 'kg' or 'st'.")
 	}
 
 	// Get height unit
@@ -154,18 +371,109 @@ func SetupSettings(db *database.DB) (*Settings, error) {
 		return nil, err
 	}
 
-	fmt.Println("\nSettings saved successfully!\n")
+	fmt.Println("\nSettings saved successfully!")
+	fmt.Println()
 
 	return &Settings{
-		WeightUnit: weightUnit,
-		HeightUnit: heightUnit,
-		Height:     height,
-		GoalWeight: goalWeight,
+		WeightUnit:  weightUnit,
+		HeightUnit:  heightUnit,
+		Height:      height,
+		GoalWeight:  goalWeight,
+		DateOrder:   SettingDefaults[DateOrderKey],
+		Locale:      SettingDefaults[LocaleKey],
+		BMIStandard: SettingDefaults[BMIStandardKey],
 	}, nil
 }
 
-// ResetSettings clears all settings (used by reset command)
-func ResetSettings(db *database.DB) error {
-	_, err := db.Exec("DELETE FROM settings")
-	return err
+// ResetData deletes every weight entry, achievement, goal and setting and
+// returns the number of entries deleted. The reset is journaled with a copy of
+// everything so it can be undone, unless purge is set, which erases the
+// journal too. Either way it all happens in a single transaction.
+// Achievements aren't journaled: they're recorded again from the entries when
+// the reset is undone.
+func ResetData(db *database.DB, purge bool) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var before Snapshot
+	if before.Weights, err = captureAllWeights(tx); err != nil {
+		return 0, err
+	}
+	if before.Settings, err = captureSettings(tx); err != nil {
+		return 0, err
+	}
+	if before.Goals, err = captureAllGoals(tx); err != nil {
+		return 0, err
+	}
+
+	for _, table := range []string{"weights", "achievements", "goals", "settings"} {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return 0, err
+		}
+	}
+
+	if purge {
+		_, err = tx.Exec("DELETE FROM operations")
+	} else {
+		err = recordOperation(tx, "reset", fmt.Sprintf("Reset %d entries and settings", len(before.Weights)), before, Snapshot{})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return len(before.Weights), tx.Commit()
+}
+
+// ChangeWeightUnit converts every stored weight, the goal weight and the
+// targets and ranges of goals and milestones to a new unit and stores the new
+// unit, and returns the number of entries converted. The conversion is
+// journaled with a copy of everything it touched, in one transaction. BMI
+// values are unchanged since the weights themselves don't change.
+func ChangeWeightUnit(db *database.DB, from, to string) (int, error) {
+	var converted int
+	err := journal(db, "config", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		before, err := unitSnapshot(tx)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+
+		factor := calculator.ConvertWeight(1, from, to)
+		if _, err := tx.Exec("UPDATE weights SET weight = weight * ?", factor); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if _, err := tx.Exec("UPDATE settings SET value = CAST(ROUND(CAST(value AS REAL) * ?, 4) AS TEXT) WHERE key = 'goal_weight'", factor); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if _, err := tx.Exec("UPDATE goals SET target = target * ?, range_low = range_low * ?, range_high = range_high * ?, start_weight = start_weight * ?", factor, factor, factor, factor); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if err := setSetting(tx, "weight_unit", to); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+
+		after, err := unitSnapshot(tx)
+		converted = len(after.Weights)
+		return fmt.Sprintf("Converted weights from %s to %s", from, to), before, after, err
+	})
+	return converted, err
+}
+
+// unitSnapshot captures everything a change of weight unit touches: every
+// entry and goal, the unit and the goal weight
+func unitSnapshot(tx *sql.Tx) (Snapshot, error) {
+	var snapshot Snapshot
+	var err error
+	if snapshot.Weights, err = captureAllWeights(tx); err != nil {
+		return Snapshot{}, err
+	}
+	if snapshot.Settings, err = captureSettings(tx, "weight_unit", "goal_weight"); err != nil {
+		return Snapshot{}, err
+	}
+	if snapshot.Goals, err = captureAllGoals(tx); err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
 }
@@ -1,65 +1,375 @@
 package models
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tryonlinux/thicc/internal/database"
 )
 
+// Weight errors
+var (
+	ErrWeightNotFound = errors.New("weight entry not found")
+	ErrNotInTrash     = errors.New("weight entry not found in trash")
+)
+
+// weightColumns is the column list used by every weight query, in scan order.
+// deleted_at is returned as an RFC 3339 string, or empty when the entry is not trashed.
+const weightColumns = "id, date, time, weight, bmi, note, created_at, COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', deleted_at), '')"
+
+// notTrashed is the condition that excludes trashed entries from a query
+const notTrashed = "deleted_at IS NULL"
+
 // Weight represents a weight entry
 type Weight struct {
-	ID     int
-	Date   string
-	Weight float64
-	BMI    float64
+	ID        int
+	Date      string
+	Time      string // optional time of day in HH:MM format
+	Weight    float64
+	BMI       float64
+	Note      string
+	CreatedAt string
+	DeletedAt string // set when the entry is in the trash
+}
+
+// WeightUpdate holds the fields to change on a weight entry.
+// Nil fields are left untouched.
+type WeightUpdate struct {
+	Date   *string
+	Time   *string
+	Weight *float64
+	BMI    *float64
+	Note   *string
+}
+
+// IsEmpty reports whether the update changes no fields
+func (u WeightUpdate) IsEmpty() bool {
+	return u.Date == nil && u.Time == nil && u.Weight == nil && u.BMI == nil && u.Note == nil
 }
 
 // AddWeight adds a new weight entry
 func AddWeight(db *database.DB, date string, weight float64, bmi float64) error {
-	_, err := db.Exec(
-		"INSERT INTO weights (date, weight, bmi) VALUES (?, ?, ?)",
-		date, weight, bmi,
-	)
+	_, err := InsertWeight(db, Weight{Date: date, Weight: weight, BMI: bmi})
 	return err
 }
 
-// DeleteWeight deletes a weight entry by ID
-func DeleteWeight(db *database.DB, id int) error {
-	_, err := db.Exec("DELETE FROM weights WHERE id = ?", id)
-	return err
+// InsertWeight adds a new weight entry including its optional time and note,
+// and returns the ID of the new entry
+func InsertWeight(db *database.DB, w Weight) (int, error) {
+	return insertWeight(db, w)
 }
 
-// ModifyWeight updates a weight entry
-func ModifyWeight(db *database.DB, id int, weight float64, bmi float64) error {
-	_, err := db.Exec("UPDATE weights SET weight = ?, bmi = ? WHERE id = ?", weight, bmi, id)
-	return err
+// insertWeight is InsertWeight on the database or within a transaction
+func insertWeight(db querier, w Weight) (int, error) {
+	result, err := db.Exec(
+		"INSERT INTO weights (date, time, weight, bmi, note) VALUES (?, ?, ?, ?, ?)",
+		w.Date, w.Time, w.Weight, w.BMI, w.Note,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	return int(id), err
 }
 
-// GetWeights retrieves the last N weight entries
-func GetWeights(db *database.DB, limit int) ([]Weight, error) {
-	query := "SELECT id, date, weight, bmi FROM weights ORDER BY date DESC, id DESC LIMIT ?"
-	rows, err := db.Query(query, limit)
+// AddEntry adds a new weight entry, records its progress on the active goals
+// and milestones and journals both as an add, in a single transaction. It
+// returns the ID of the new entry and the goals and milestones it reached.
+func AddEntry(db *database.DB, w Weight) (int, []Goal, error) {
+	var id int
+	var reached []Goal
+	err := journal(db, "add", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		goalsBefore, err := captureActiveGoals(tx)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if id, err = insertWeight(tx, w); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		w.ID = id
+		if reached, err = updateGoalProgress(tx, w); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+
+		added, err := captureWeights(tx, id)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		goalsAfter, err := captureGoals(tx, goalIDs(goalsBefore)...)
+		return fmt.Sprintf("Added entry %d (%s)", id, w.Date), Snapshot{Goals: goalsBefore}, Snapshot{Weights: added, Goals: goalsAfter}, err
+	})
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
-	defer rows.Close()
+	return id, reached, nil
+}
 
-	var weights []Weight
-	for rows.Next() {
-		var w Weight
-		if err := rows.Scan(&w.ID, &w.Date, &w.Weight, &w.BMI); err != nil {
-			return nil, err
+// WeightSelection selects weight entries by ID list and/or date range.
+// Empty fields don't restrict the selection.
+type WeightSelection struct {
+	IDs          []int
+	From         string // first date to include (YYYY-MM-DD)
+	To           string // last date to include (YYYY-MM-DD)
+	Tag          string // select entries whose note has this #tag, lower case without the #
+	Limit        int    // keep only the newest entries; zero keeps all
+	IncludeTrash bool   // also select entries in the trash, e.g. for exports
+}
+
+// IsEmpty reports whether the selection has no criteria
+func (s WeightSelection) IsEmpty() bool {
+	return len(s.IDs) == 0 && s.From == "" && s.To == "" && s.Tag == ""
+}
+
+// TrashWeights moves several weight entries to the trash and journals it as
+// a delete with the given summary, in a single transaction
+func TrashWeights(db *database.DB, ids []int, summary string) error {
+	return journal(db, "delete", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		before, err := captureWeights(tx, ids...)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if err := deleteWeights(tx, ids); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		after, err := captureWeights(tx, ids...)
+		return summary, Snapshot{Weights: before}, Snapshot{Weights: after}, err
+	})
+}
+
+// deleteWeights moves weight entries to the trash within a transaction
+func deleteWeights(tx *sql.Tx, ids []int) error {
+	for _, id := range ids {
+		result, err := tx.Exec("UPDATE weights SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND "+notTrashed, id)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result, ErrWeightNotFound); err != nil {
+			return err
 		}
-		weights = append(weights, w)
 	}
+	return nil
+}
 
-	return weights, rows.Err()
+// RestoreWeight moves a weight entry out of the trash and journals the
+// restore, in one transaction
+func RestoreWeight(db *database.DB, id int) error {
+	return journal(db, "trash restore", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		before, err := captureWeights(tx, id)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		result, err := tx.Exec("UPDATE weights SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if err := requireAffected(result, ErrNotInTrash); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		after, err := captureWeights(tx, id)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		return fmt.Sprintf("Restored entry %d (%s)", id, after[0].Date), Snapshot{Weights: before}, Snapshot{Weights: after}, nil
+	})
+}
+
+// GetTrashedWeights retrieves all entries in the trash, most recently deleted first
+func GetTrashedWeights(db *database.DB) ([]Weight, error) {
+	query := "SELECT " + weightColumns + " FROM weights WHERE deleted_at IS NOT NULL ORDER BY datetime(deleted_at) DESC, id DESC"
+	return queryWeights(db, query)
+}
+
+// GetTrashedWeightsBefore retrieves entries moved to the trash before the cutoff.
+// A zero cutoff returns every trashed entry.
+func GetTrashedWeightsBefore(db *database.DB, cutoff time.Time) ([]Weight, error) {
+	if cutoff.IsZero() {
+		return GetTrashedWeights(db)
+	}
+	query := "SELECT " + weightColumns + " FROM weights WHERE deleted_at IS NOT NULL AND datetime(deleted_at) < datetime(?) ORDER BY datetime(deleted_at) DESC, id DESC"
+	return queryWeights(db, query, cutoff.UTC().Format("2006-01-02 15:04:05"))
+}
+
+// PurgeWeights permanently deletes trashed entries by ID. The purge is
+// journaled with a copy of the entries in one transaction, so it can be undone.
+func PurgeWeights(db *database.DB, ids ...int) error {
+	return journal(db, "trash empty", func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		captured, err := captureWeights(tx, ids...)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		var before []Weight
+		for _, w := range captured {
+			if w.DeletedAt != "" {
+				before = append(before, w)
+			}
+		}
+
+		for _, id := range ids {
+			if _, err := tx.Exec("DELETE FROM weights WHERE id = ? AND deleted_at IS NOT NULL", id); err != nil {
+				return "", Snapshot{}, Snapshot{}, err
+			}
+		}
+		return fmt.Sprintf("Removed %d entries from the trash", len(before)), Snapshot{Weights: before}, Snapshot{}, nil
+	})
+}
+
+// ChangeWeights applies the same update to several weight entries and
+// journals it under the command with the given summary, in a single transaction
+func ChangeWeights(db *database.DB, command, summary string, ids []int, update WeightUpdate) error {
+	return journal(db, command, func(tx *sql.Tx) (string, Snapshot, Snapshot, error) {
+		before, err := captureWeights(tx, ids...)
+		if err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		if err := updateWeights(tx, ids, update); err != nil {
+			return "", Snapshot{}, Snapshot{}, err
+		}
+		after, err := captureWeights(tx, ids...)
+		return summary, Snapshot{Weights: before}, Snapshot{Weights: after}, err
+	})
+}
+
+// updateWeights applies an update to weight entries within a transaction
+func updateWeights(tx *sql.Tx, ids []int, update WeightUpdate) error {
+	clause, values := updateClause(update)
+	if clause == "" {
+		return nil
+	}
+
+	for _, id := range ids {
+		result, err := tx.Exec("UPDATE weights SET "+clause+" WHERE id = ? AND "+notTrashed, append(values, id)...)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result, ErrWeightNotFound); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateClause builds the SET clause and its values for the fields set in an update
+func updateClause(update WeightUpdate) (string, []any) {
+	var sets []string
+	var values []any
+
+	if update.Date != nil {
+		sets = append(sets, "date = ?")
+		values = append(values, *update.Date)
+	}
+	if update.Time != nil {
+		sets = append(sets, "time = ?")
+		values = append(values, *update.Time)
+	}
+	if update.Weight != nil {
+		sets = append(sets, "weight = ?")
+		values = append(values, *update.Weight)
+	}
+	if update.BMI != nil {
+		sets = append(sets, "bmi = ?")
+		values = append(values, *update.BMI)
+	}
+	if update.Note != nil {
+		sets = append(sets, "note = ?")
+		values = append(values, *update.Note)
+	}
+
+	return strings.Join(sets, ", "), values
+}
+
+// requireAffected returns notFound when a statement changed no rows
+func requireAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// GetWeight retrieves a single weight entry by ID. Trashed entries are not returned.
+func GetWeight(db *database.DB, id int) (*Weight, error) {
+	return getWeight(db, "SELECT "+weightColumns+" FROM weights WHERE id = ? AND "+notTrashed, id)
+}
+
+// getWeight runs a query selecting a single weight entry
+func getWeight(db querier, query string, args ...any) (*Weight, error) {
+	row := db.QueryRow(query, args...)
+
+	var w Weight
+	err := row.Scan(&w.ID, &w.Date, &w.Time, &w.Weight, &w.BMI, &w.Note, &w.CreatedAt, &w.DeletedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrWeightNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// GetWeights retrieves the last N weight entries
+func GetWeights(db *database.DB, limit int) ([]Weight, error) {
+	query := "SELECT " + weightColumns + " FROM weights WHERE " + notTrashed + " ORDER BY date DESC, time DESC, id DESC LIMIT ?"
+	return queryWeights(db, query, limit)
 }
 
 // GetWeightsBetweenDates retrieves weight entries between two dates
 func GetWeightsBetweenDates(db *database.DB, startDate, endDate string) ([]Weight, error) {
-	query := "SELECT id, date, weight, bmi FROM weights WHERE date >= ? AND date <= ? ORDER BY date DESC, id DESC"
-	rows, err := db.Query(query, startDate, endDate)
+	query := "SELECT " + weightColumns + " FROM weights WHERE date >= ? AND date <= ? AND " + notTrashed + " ORDER BY date DESC, time DESC, id DESC"
+	return queryWeights(db, query, startDate, endDate)
+}
+
+// SelectWeights retrieves the entries matching a selection, newest first
+func SelectWeights(db *database.DB, selection WeightSelection) ([]Weight, error) {
+	var conditions []string
+	var args []any
+
+	if !selection.IncludeTrash {
+		conditions = append(conditions, notTrashed)
+	}
+
+	if len(selection.IDs) > 0 {
+		conditions = append(conditions, "id IN (?"+strings.Repeat(", ?", len(selection.IDs)-1)+")")
+		for _, id := range selection.IDs {
+			args = append(args, id)
+		}
+	}
+	if selection.From != "" {
+		conditions = append(conditions, "date >= ?")
+		args = append(args, selection.From)
+	}
+	if selection.To != "" {
+		conditions = append(conditions, "date <= ?")
+		args = append(args, selection.To)
+	}
+	if selection.Tag != "" {
+		// A tag is a word starting with #, so it follows whitespace or the
+		// start of the note and ends at the first character a tag can't have
+		conditions = append(conditions, "' ' || lower(note) || ' ' GLOB ?")
+		args = append(args, "*[ \t\n]#"+selection.Tag+"[^a-z0-9_-]*")
+	}
+
+	query := "SELECT " + weightColumns + " FROM weights"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY date DESC, time DESC, id DESC"
+	if selection.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, selection.Limit)
+	}
+	return queryWeights(db, query, args...)
+}
+
+// queryWeights runs a query selecting weightColumns and scans the results
+func queryWeights(db querier, query string, args ...any) ([]Weight, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +378,7 @@ func GetWeightsBetweenDates(db *database.DB, startDate, endDate string) ([]Weigh
 	var weights []Weight
 	for rows.Next() {
 		var w Weight
-		if err := rows.Scan(&w.ID, &w.Date, &w.Weight, &w.BMI); err != nil {
+		if err := rows.Scan(&w.ID, &w.Date, &w.Time, &w.Weight, &w.BMI, &w.Note, &w.CreatedAt, &w.DeletedAt); err != nil {
 			return nil, err
 		}
 		weights = append(weights, w)
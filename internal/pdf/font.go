@@ -0,0 +1,79 @@
+package pdf
+
+import (
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Font is one of the standard PDF fonts, which viewers provide, so they
+// needn't be embedded
+type Font string
+
+// Standard fonts used by documents
+const (
+	Helvetica     Font = "Helvetica"
+	HelveticaBold Font = "Helvetica-Bold"
+	Courier       Font = "Courier"
+)
+
+// fontList lists the fonts every document makes available
+var fontList = []Font{Helvetica, HelveticaBold, Courier}
+
+// ResourceName returns the name content streams select the font by
+func (f Font) ResourceName() string {
+	return strings.ReplaceAll(string(f), "-", "")
+}
+
+// helveticaWidths holds the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size, from its font metrics
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// TextWidth returns the width of text in points. Bold text is measured with
+// the regular Helvetica widths, which are close, and characters outside ASCII
+// with the width of a digit.
+func TextWidth(font Font, size float64, text string) float64 {
+	total := 0
+	for _, r := range text {
+		switch {
+		case font == Courier:
+			total += 600
+		case r >= ' ' && r <= '~':
+			total += helveticaWidths[r-' ']
+		default:
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// EncodeText returns text as a PDF string literal in the fonts' Windows-1252
+// encoding. Characters it doesn't have are replaced with "?".
+func EncodeText(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			c = '?'
+		}
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n', '\r':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,232 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// PageSize is the size of a page in points, 72 to the inch
+type PageSize struct {
+	Name   string
+	Width  float64
+	Height float64
+}
+
+// Page sizes
+var (
+	A4     = PageSize{Name: "a4", Width: 595.28, Height: 841.89}
+	Letter = PageSize{Name: "letter", Width: 612, Height: 792}
+)
+
+// PageSizes lists the page sizes by name, the default first
+var PageSizes = []PageSize{A4, Letter}
+
+// PageSizeByName returns the page size with the given name
+func PageSizeByName(name string) (PageSize, error) {
+	for _, size := range PageSizes {
+		if strings.EqualFold(size.Name, name) {
+			return size, nil
+		}
+	}
+	return PageSize{}, fmt.Errorf("unknown page size %q: use a4 or letter", name)
+}
+
+// Document is a PDF document built page by page. Positions on a page are in
+// points from its top left corner.
+type Document struct {
+	Title string
+	size  PageSize
+	pages []*Page
+	forms []formContent
+}
+
+// formContent is the content stream of a form XObject and the size of its box
+type formContent struct {
+	content       string
+	width, height float64
+}
+
+// New returns an empty document with pages of the given size
+func New(size PageSize) *Document {
+	return &Document{size: size}
+}
+
+// Size returns the document's page size
+func (d *Document) Size() PageSize {
+	return d.size
+}
+
+// AddPage adds a blank page to the end of the document and returns it
+func (d *Document) AddPage() *Page {
+	page := &Page{height: d.size.Height}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Pages returns the document's pages
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Form is reusable content, like a chart, drawn onto pages
+type Form struct {
+	name          string
+	width, height float64
+}
+
+// AddForm adds content drawn in a width × height box, with its origin at the
+// bottom left, that pages can draw with DrawForm. The content can use the
+// document's fonts by their resource names.
+func (d *Document) AddForm(content string, width, height float64) Form {
+	d.forms = append(d.forms, formContent{content: content, width: width, height: height})
+	return Form{name: fmt.Sprintf("Fm%d", len(d.forms)), width: width, height: height}
+}
+
+// Page is a page of a document, holding its content stream
+type Page struct {
+	height  float64
+	content strings.Builder
+}
+
+// Text draws text with its baseline at y
+func (p *Page) Text(x, y float64, font Font, size float64, text string, col color.Color) {
+	fmt.Fprintf(&p.content, "BT %s /%s %s Tf %s %s Td %s Tj ET\n",
+		FillColor(col), font.ResourceName(), Number(size), Number(x), Number(p.height-y), EncodeText(text))
+}
+
+// Rect fills a rectangle with its top left corner at x, y
+func (p *Page) Rect(x, y, width, height float64, col color.Color) {
+	fmt.Fprintf(&p.content, "%s %s %s %s %s re f\n",
+		FillColor(col), Number(x), Number(p.height-y-height), Number(width), Number(height))
+}
+
+// Line strokes a straight line
+func (p *Page) Line(x1, y1, x2, y2, width float64, col color.Color) {
+	fmt.Fprintf(&p.content, "%s %s w %s %s m %s %s l S\n",
+		StrokeColor(col), Number(width), Number(x1), Number(p.height-y1), Number(x2), Number(p.height-y2))
+}
+
+// DrawForm draws a form scaled to the given width, with its top left corner at x, y
+func (p *Page) DrawForm(form Form, x, y, width float64) {
+	scale := width / form.width
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /%s Do Q\n",
+		Number(scale), Number(scale), Number(x), Number(p.height-y-form.height*scale), form.name)
+}
+
+// WriteTo writes the document as a PDF file
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	// Objects are numbered from 1 in the order they're written
+	object := func(body string) int {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+		return len(offsets)
+	}
+	stream := func(dict, content string) int {
+		// Fall back to an uncompressed stream if compression fails
+		data, err := deflate(content)
+		if err != nil {
+			data = []byte(content)
+		} else {
+			dict += " /Filter /FlateDecode"
+		}
+		return object(fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", strings.TrimSpace(dict), len(data), data))
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Fonts, shared by pages and forms
+	var fonts []string
+	for _, font := range fontList {
+		n := object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font))
+		fonts = append(fonts, fmt.Sprintf("/%s %d 0 R", font.ResourceName(), n))
+	}
+	fontDict := "/Font << " + strings.Join(fonts, " ") + " >>"
+
+	var forms []string
+	for i, form := range d.forms {
+		n := stream(fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %s %s] /Resources << %s >>",
+			Number(form.width), Number(form.height), fontDict), form.content)
+		forms = append(forms, fmt.Sprintf("/Fm%d %d 0 R", i+1, n))
+	}
+	resources := "<< " + fontDict
+	if len(forms) > 0 {
+		resources += " /XObject << " + strings.Join(forms, " ") + " >>"
+	}
+	resources += " >>"
+
+	// The page tree is written after the pages, which refer to it by the
+	// number it will get
+	pagesRef := len(offsets) + 2*len(d.pages) + 1
+	var kids []string
+	for _, page := range d.pages {
+		content := stream("", page.content.String())
+		n := object(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			pagesRef, Number(d.size.Width), Number(d.size.Height), resources, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", n))
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	catalog := object(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesRef))
+	info := object(fmt.Sprintf("<< /Title %s /Producer (thicc) >>", EncodeText(d.Title)))
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, catalog, info, xref)
+
+	return buf.WriteTo(w)
+}
+
+// deflate compresses a content stream
+func deflate(content string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Number formats a number for PDF content, with at most two decimals
+func Number(v float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.2f", v), "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// FillColor returns the operator that sets the fill color. Colors are opaque;
+// translucent ones should be blended with what's beneath first.
+func FillColor(col color.Color) string {
+	r, g, b := rgb(col)
+	return fmt.Sprintf("%s %s %s rg", r, g, b)
+}
+
+// StrokeColor returns the operator that sets the stroke color
+func StrokeColor(col color.Color) string {
+	r, g, b := rgb(col)
+	return fmt.Sprintf("%s %s %s RG", r, g, b)
+}
+
+// rgb returns a color's channels from 0 to 1
+func rgb(col color.Color) (string, string, string) {
+	c := color.RGBAModel.Convert(col).(color.RGBA)
+	channel := func(v uint8) string {
+		return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.3f", float64(v)/0xff), "0"), ".")
+	}
+	return channel(c.R), channel(c.G), channel(c.B)
+}
@@ -0,0 +1,125 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/graphics"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// The chart embedded in reports, in pixels
+const (
+	chartWidth  = 960
+	chartHeight = 420
+)
+
+//go:embed report.html
+var htmlSource string
+
+var htmlTemplate = template.Must(template.New("report").Parse(htmlSource))
+
+// stat is a labelled value in the report's summary
+type stat struct {
+	Label string
+	Value string
+}
+
+// periodRow is a row of the monthly summary table
+type periodRow struct {
+	Period  string
+	Entries int
+	Mean    string
+	Min     string
+	Max     string
+	Change  string
+}
+
+// entryRow is a row of the entries table
+type entryRow struct {
+	ID     int
+	Date   string
+	Weight string
+	BMI    string
+	Note   string
+}
+
+// htmlReport is the data the HTML template renders
+type htmlReport struct {
+	Generated    string
+	From, To     string
+	Chart        template.HTML
+	Stats        []stat
+	Goal         string
+	GoalStatus   string
+	GoalProgress int // percent
+	Months       []periodRow
+	Entries      []entryRow
+}
+
+// WriteHTML writes a self-contained HTML report of weights, stored newest
+// first: summary statistics, goal progress, a chart, monthly summaries and
+// every entry. Styles and the chart are inline, so the file works offline.
+func WriteHTML(w io.Writer, weights []models.Weight, settings *models.Settings, generated time.Time) error {
+	if len(weights) == 0 {
+		return fmt.Errorf("no weights to report on")
+	}
+	summary := analytics.Summarize(weights)
+	data := display.PrepareChartFor(weights, settings)
+
+	report := htmlReport{
+		Generated:    generated.Format("January 2, 2006 15:04"),
+		From:         display.FormatDate(summary.From),
+		To:           display.FormatDate(summary.To),
+		Chart:        template.HTML(graphics.RenderChartSVG(data, settings, chartWidth, chartHeight)),
+		Stats:        summaryStats(summary, weights[0], settings),
+		Goal:         display.FormatGoal(settings),
+		GoalStatus:   display.FormatGoalDifference(summary.Latest, settings),
+		GoalProgress: int(math.Round(analytics.GoalProgress(summary.Start, summary.Latest, settings.GoalFrom(summary.Start)) * 100)),
+	}
+
+	// Newest month first, like the entries
+	months := analytics.MonthlySummaries(weights)
+	for i := len(months) - 1; i >= 0; i-- {
+		m := months[i]
+		report.Months = append(report.Months, periodRow{
+			Period:  m.Start.Format("January 2006"),
+			Entries: m.Count,
+			Mean:    display.FormatWeightFor(m.Mean, settings),
+			Min:     display.FormatWeightFor(m.Min, settings),
+			Max:     display.FormatWeightFor(m.Max, settings),
+			Change:  display.FormatWeightChange(m.Change(), settings),
+		})
+	}
+
+	for _, w := range weights {
+		report.Entries = append(report.Entries, entryRow{
+			ID:     w.ID,
+			Date:   display.FormatDateTime(w.Date, w.Time),
+			Weight: display.FormatWeightFor(w.Weight, settings),
+			BMI:    display.FormatBMI(w.BMI),
+			Note:   w.Note,
+		})
+	}
+
+	return htmlTemplate.Execute(w, report)
+}
+
+// summaryStats returns the statistics shown at the top of a report
+func summaryStats(s analytics.Summary, latest models.Weight, settings *models.Settings) []stat {
+	return []stat{
+		{"Latest", display.FormatWeightFor(s.Latest, settings)},
+		{"BMI", display.FormatBMIDetails(latest.BMI, latest.Date, settings)},
+		{"Change", display.FormatWeightChange(s.Change(), settings)},
+		{"Average", display.FormatWeightFor(s.Mean, settings)},
+		{"Min", display.FormatWeightFor(s.Min, settings)},
+		{"Max", display.FormatWeightFor(s.Max, settings)},
+		{"Entries", fmt.Sprintf("%d", s.Count)},
+	}
+}
@@ -0,0 +1,329 @@
+package report
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/graphics"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/pdf"
+)
+
+// Page layout, in points
+const (
+	pdfMargin       = 50
+	pdfFooterHeight = 20
+	pdfTitleSize    = 20
+	pdfHeadingSize  = 13
+	pdfTextSize     = 9.5
+	pdfLineHeight   = 14
+	pdfCellPadding  = 4
+)
+
+// The chart in PDF reports, in the chart's pixels, scaled to the page width
+const (
+	pdfChartWidth  = 900
+	pdfChartHeight = 400
+)
+
+// Report text colors
+var (
+	pdfTextColor   = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	pdfMutedColor  = color.RGBA{0x66, 0x66, 0x66, 0xff}
+	pdfRuleColor   = color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+	pdfHeaderColor = color.RGBA{0xf0, 0xf0, 0xf0, 0xff}
+)
+
+// pdfColumn is a column of a PDF table, with its share of the table's width
+type pdfColumn struct {
+	title string
+	share float64
+	right bool // right-aligned, for numbers
+}
+
+// pdfLayout places text and tables down the pages of a document, starting a
+// new page when one is full
+type pdfLayout struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64 // top of the free space on the page
+}
+
+// WritePDF writes a printable report of weights, stored newest first: the
+// person's details and goal, a chart, the rate of change, BMI category
+// history, monthly averages and notable events
+func WritePDF(w io.Writer, weights []models.Weight, settings *models.Settings, size pdf.PageSize, generated time.Time) error {
+	if len(weights) == 0 {
+		return fmt.Errorf("no weights to report on")
+	}
+	summary := analytics.Summarize(weights)
+
+	doc := pdf.New(size)
+	doc.Title = "Weight Progress Report"
+	l := &pdfLayout{doc: doc}
+	l.newPage()
+
+	// Title
+	l.y += pdfTitleSize
+	l.page.Text(pdfMargin, l.y, pdf.HelveticaBold, pdfTitleSize, doc.Title, pdfTextColor)
+	l.y += pdfLineHeight + 2
+	l.page.Text(pdfMargin, l.y, pdf.Helvetica, pdfTextSize,
+		fmt.Sprintf("%s – %s · Generated %s", display.FormatDate(summary.From), display.FormatDate(summary.To),
+			generated.Format("January 2, 2006")), pdfMutedColor)
+	l.y += pdfLineHeight
+
+	l.heading("Details")
+	l.details([][2]string{
+		{"Height", fmt.Sprintf("%g %s", settings.Height, settings.HeightUnit)},
+		{"Units", settings.WeightUnit + ", " + settings.HeightUnit},
+		{"Goal weight", display.FormatGoal(settings)},
+		{"Goal status", display.FormatGoalDifference(summary.Latest, settings)},
+		{"Latest weight", display.FormatWeightFor(summary.Latest, settings)},
+		{"Latest BMI", display.FormatBMIDetails(weights[0].BMI, weights[0].Date, settings)},
+		{"Starting weight", display.FormatWeightFor(summary.Start, settings)},
+		{"Change", display.FormatWeightChange(summary.Change(), settings)},
+		{"Average", display.FormatWeightFor(summary.Mean, settings)},
+		{"Entries", fmt.Sprintf("%d", summary.Count)},
+	})
+
+	// Chart, scaled to the width of the page
+	l.heading("Chart")
+	data := display.PrepareChartFor(weights, settings)
+	chart := doc.AddForm(graphics.RenderChartPDF(data, settings, pdfChartWidth, pdfChartHeight, graphics.LightTheme),
+		pdfChartWidth, pdfChartHeight)
+	chartHeight := l.contentWidth() * pdfChartHeight / pdfChartWidth
+	l.ensure(chartHeight)
+	l.page.DrawForm(chart, pdfMargin, l.y, l.contentWidth())
+	l.y += chartHeight
+
+	l.heading("Rate of change")
+	var rateRows [][]string
+	for _, period := range []struct {
+		name string
+		days int
+	}{
+		{"Last 4 weeks", 28},
+		{"Last 12 weeks", 84},
+		{"Whole report", 0},
+	} {
+		selected := weights
+		if period.days > 0 {
+			selected = analytics.Recent(weights, period.days)
+		}
+		rate := analytics.RatePerWeek(selected)
+		rateRows = append(rateRows, []string{
+			period.name,
+			fmt.Sprintf("%d", len(selected)),
+			display.FormatWeightChange(rate, settings) + " per week",
+			fmt.Sprintf("%+.2f%%", rate/summary.Latest*100),
+		})
+	}
+	l.table([]pdfColumn{
+		{"Period", 0.3, false},
+		{"Entries", 0.15, true},
+		{"Rate", 0.3, true},
+		{"Of body weight", 0.25, true},
+	}, rateRows)
+
+	l.heading("BMI category history")
+	var bmiRows [][]string
+	for _, p := range analytics.BMIHistoryFor(weights, settings.BMICutoffs()) {
+		bmiRows = append(bmiRows, []string{
+			p.Category.Name,
+			display.FormatDate(p.From),
+			display.FormatDate(p.To),
+			fmt.Sprintf("%d", p.Entries),
+			display.FormatBMI(p.MinBMI) + " – " + display.FormatBMI(p.MaxBMI),
+		})
+	}
+	l.table([]pdfColumn{
+		{"Category", 0.25, false},
+		{"From", 0.2, false},
+		{"To", 0.2, false},
+		{"Entries", 0.15, true},
+		{"BMI", 0.2, true},
+	}, bmiRows)
+
+	l.heading("Monthly averages")
+	var monthRows [][]string
+	for _, m := range analytics.MonthlySummaries(weights) {
+		monthRows = append(monthRows, []string{
+			m.Start.Format("January 2006"),
+			fmt.Sprintf("%d", m.Count),
+			display.FormatWeightFor(m.Mean, settings),
+			display.FormatWeightFor(m.Min, settings),
+			display.FormatWeightFor(m.Max, settings),
+			display.FormatWeightChange(m.Change(), settings),
+		})
+	}
+	l.table([]pdfColumn{
+		{"Month", 0.22, false},
+		{"Entries", 0.1, true},
+		{"Average", 0.17, true},
+		{"Min", 0.17, true},
+		{"Max", 0.17, true},
+		{"Change", 0.17, true},
+	}, monthRows)
+
+	l.heading("Notable events")
+	events := notableEvents(weights, settings)
+	if len(events) == 0 {
+		l.line("No notes or milestones in this period.", pdfMutedColor)
+	} else {
+		l.table([]pdfColumn{
+			{"Date", 0.2, false},
+			{"Weight", 0.18, true},
+			{"Event", 0.62, false},
+		}, events)
+	}
+
+	// Footers, now the number of pages is known
+	pages := doc.Pages()
+	for i, page := range pages {
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(pages))
+		page.Text(size.Width-pdfMargin-pdf.TextWidth(pdf.Helvetica, pdfTextSize, footer), size.Height-pdfMargin/2,
+			pdf.Helvetica, pdfTextSize, footer, pdfMutedColor)
+		page.Text(pdfMargin, size.Height-pdfMargin/2, pdf.Helvetica, pdfTextSize, doc.Title, pdfMutedColor)
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// notableEvents returns rows for the entries with notes, the lowest and
+// highest weights, and the first entry to reach the goal, oldest first
+func notableEvents(weights []models.Weight, settings *models.Settings) [][]string {
+	summary := analytics.Summarize(weights)
+	losing := summary.Start > settings.GoalWeight
+	goalReached := false
+	lowestSeen, highestSeen := false, false
+
+	var rows [][]string
+	for i := len(weights) - 1; i >= 0; i-- {
+		w := weights[i]
+		var events []string
+		if w.Note != "" {
+			events = append(events, w.Note)
+		}
+		if !goalReached && i < len(weights)-1 && (losing && w.Weight <= settings.GoalWeight || !losing && w.Weight >= settings.GoalWeight) {
+			goalReached = true
+			events = append(events, "Goal reached")
+		}
+		if w.Weight == summary.Min && !lowestSeen && summary.Min != summary.Max {
+			lowestSeen = true
+			events = append(events, "Lowest weight")
+		}
+		if w.Weight == summary.Max && !highestSeen && summary.Min != summary.Max {
+			highestSeen = true
+			events = append(events, "Highest weight")
+		}
+		for _, event := range events {
+			rows = append(rows, []string{display.FormatDate(w.Date), display.FormatWeightFor(w.Weight, settings), event})
+		}
+	}
+	return rows
+}
+
+// contentWidth returns the width between the page margins
+func (l *pdfLayout) contentWidth() float64 {
+	return l.doc.Size().Width - 2*pdfMargin
+}
+
+// newPage starts a new page
+func (l *pdfLayout) newPage() {
+	l.page = l.doc.AddPage()
+	l.y = pdfMargin
+}
+
+// ensure starts a new page unless the given height fits on this one
+func (l *pdfLayout) ensure(height float64) {
+	if l.y+height > l.doc.Size().Height-pdfMargin-pdfFooterHeight {
+		l.newPage()
+	}
+}
+
+// heading starts a section, keeping it on the same page as a few lines of it
+func (l *pdfLayout) heading(text string) {
+	l.ensure(pdfHeadingSize + 4*pdfLineHeight)
+	l.y += pdfHeadingSize + pdfLineHeight
+	l.page.Text(pdfMargin, l.y, pdf.HelveticaBold, pdfHeadingSize, text, pdfTextColor)
+	l.y += pdfLineHeight / 2
+}
+
+// line writes a line of text
+func (l *pdfLayout) line(text string, col color.Color) {
+	l.ensure(pdfLineHeight)
+	l.y += pdfLineHeight
+	l.page.Text(pdfMargin, l.y, pdf.Helvetica, pdfTextSize, text, col)
+}
+
+// details writes label and value pairs in two columns
+func (l *pdfLayout) details(pairs [][2]string) {
+	half := l.contentWidth() / 2
+	for i := 0; i < len(pairs); i += 2 {
+		l.ensure(pdfLineHeight)
+		l.y += pdfLineHeight
+		for j := i; j < min(i+2, len(pairs)); j++ {
+			x := pdfMargin + float64(j-i)*half
+			l.page.Text(x, l.y, pdf.Helvetica, pdfTextSize, pairs[j][0], pdfMutedColor)
+			l.page.Text(x+half*0.4, l.y, pdf.HelveticaBold, pdfTextSize, pairs[j][1], pdfTextColor)
+		}
+	}
+}
+
+// table writes rows under a header, repeating the header on each new page
+func (l *pdfLayout) table(columns []pdfColumn, rows [][]string) {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.title
+	}
+
+	l.ensure(2 * pdfLineHeight)
+	l.tableRow(columns, header, pdf.HelveticaBold, pdfHeaderColor)
+	for _, row := range rows {
+		if l.y+pdfLineHeight > l.doc.Size().Height-pdfMargin-pdfFooterHeight {
+			l.newPage()
+			l.tableRow(columns, header, pdf.HelveticaBold, pdfHeaderColor)
+		}
+		l.tableRow(columns, row, pdf.Helvetica, nil)
+	}
+}
+
+// tableRow writes one row of a table, with a rule under it and an optional background
+func (l *pdfLayout) tableRow(columns []pdfColumn, cells []string, font pdf.Font, background color.Color) {
+	if background != nil {
+		l.page.Rect(pdfMargin, l.y, l.contentWidth(), pdfLineHeight+2, background)
+	}
+	baseline := l.y + pdfLineHeight - pdfCellPadding + 1
+	x := float64(pdfMargin)
+	for i, col := range columns {
+		width := col.share * l.contentWidth()
+		text := fitText(cells[i], font, width-2*pdfCellPadding)
+		textX := x + pdfCellPadding
+		if col.right {
+			textX = x + width - pdfCellPadding - pdf.TextWidth(font, pdfTextSize, text)
+		}
+		l.page.Text(textX, baseline, font, pdfTextSize, text, pdfTextColor)
+		x += width
+	}
+	l.y += pdfLineHeight + 2
+	l.page.Line(pdfMargin, l.y, pdfMargin+l.contentWidth(), l.y, 0.5, pdfRuleColor)
+}
+
+// fitText shortens text with an ellipsis to fit a width
+func fitText(text string, font pdf.Font, width float64) string {
+	if pdf.TextWidth(font, pdfTextSize, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for n := len(runes) - 1; n > 0; n-- {
+		if short := string(runes[:n]) + "…"; pdf.TextWidth(font, pdfTextSize, short) <= width {
+			return short
+		}
+	}
+	return ""
+}
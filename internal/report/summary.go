@@ -0,0 +1,90 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+// SummaryRecord is a period of a summary as written to JSON and CSV. Weights
+// are in the user's weight unit, rounded to their precision. For stones the
+// precision is that of the pounds, so weights are decimal stones rounded to
+// the nearest pounds at that precision.
+type SummaryRecord struct {
+	Period      string  `json:"period"`
+	Start       string  `json:"start"` // first day of the period
+	End         string  `json:"end"`   // last day of the period
+	Entries     int     `json:"entries"`
+	Mean        float64 `json:"mean"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	First       float64 `json:"first"` // oldest weight in the period
+	Last        float64 `json:"last"`  // newest weight in the period
+	Change      float64 `json:"change"`
+	RatePerWeek float64 `json:"rate_per_week"`
+	Unit        string  `json:"unit"`
+}
+
+// SummaryRecords converts period summaries to records
+func SummaryRecords(periods []analytics.PeriodSummary, period analytics.Period, settings *models.Settings) []SummaryRecord {
+	scale := math.Pow(10, float64(settings.WeightDecimals()))
+	round := func(v float64) float64 {
+		if settings.WeightUnit == "st" {
+			// Round the pounds, then drop the float noise of converting back
+			// with four decimals of a stone, well under the pounds' precision
+			stones := math.Round(v*calculator.LbsPerSt*scale) / scale / calculator.LbsPerSt
+			return math.Round(stones*1e4) / 1e4
+		}
+		return math.Round(v*scale) / scale
+	}
+
+	records := make([]SummaryRecord, 0, len(periods))
+	for _, p := range periods {
+		records = append(records, SummaryRecord{
+			Period:      string(period),
+			Start:       p.Start.Format("2006-01-02"),
+			End:         period.End(p.Start).Format("2006-01-02"),
+			Entries:     p.Count,
+			Mean:        round(p.Mean),
+			Min:         round(p.Min),
+			Max:         round(p.Max),
+			First:       round(p.Summary.Start),
+			Last:        round(p.Latest),
+			Change:      round(p.Change()),
+			RatePerWeek: round(p.RatePerWeek),
+			Unit:        settings.WeightUnit,
+		})
+	}
+	return records
+}
+
+// WriteSummaryJSON writes summary records as an indented JSON array
+func WriteSummaryJSON(w io.Writer, records []SummaryRecord) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// WriteSummaryCSV writes summary records as CSV with a header row
+func WriteSummaryCSV(w io.Writer, records []SummaryRecord) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"period", "start", "end", "entries", "mean", "min", "max", "first", "last", "change", "rate_per_week", "unit"})
+	number := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	for _, r := range records {
+		cw.Write([]string{
+			r.Period, r.Start, r.End, strconv.Itoa(r.Entries),
+			number(r.Mean), number(r.Min), number(r.Max), number(r.First), number(r.Last),
+			number(r.Change), number(r.RatePerWeek), r.Unit,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,287 @@
+package validation
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateOrder is the order of day and month in numeric dates such as 05/01/2024
+type DateOrder string
+
+// Supported date orders
+const (
+	DayMonthYear DateOrder = "dmy"
+	MonthDayYear DateOrder = "mdy"
+)
+
+// Date parsing errors
+var (
+	ErrUnrecognizedDate = errors.New("unrecognized date: use YYYY-MM-DD, a numeric date like 05/01/2024, or e.g. today, yesterday, 3d ago, 2 weeks ago, last monday, jan 5")
+	ErrFutureDate       = errors.New("date is in the future (pass --allow-future to allow it)")
+	ErrInvalidDateOrder = errors.New("date order must be 'dmy' or 'mdy'")
+)
+
+var (
+	// relativeShortPattern matches shorthand offsets such as -3d, +2w or -1m
+	relativeShortPattern = regexp.MustCompile(`^([+-])(\d+)\s*([dwmy])$`)
+
+	// agoPattern matches phrases such as "3 days ago", "a week ago" or "3d ago"
+	agoPattern = regexp.MustCompile(`^(\d+|a|an|one)\s*(d|days?|w|wks?|weeks?|m|mos?|months?|y|yrs?|years?)\s+ago$`)
+
+	// numericPattern matches numeric dates such as 05/01/2024, 5.1.24 or 5/1
+	numericPattern = regexp.MustCompile(`^(\d{1,2})[/.\-](\d{1,2})(?:[/.\-](\d{2}|\d{4}))?$`)
+
+	// ordinalSuffix matches the suffix of ordinal days such as 1st or 22nd
+	ordinalSuffix = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)$`)
+)
+
+// monthNames maps full and abbreviated month names to months
+var monthNames = map[string]time.Month{
+	"jan": time.January, "january": time.January,
+	"feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March,
+	"apr": time.April, "april": time.April,
+	"may": time.May,
+	"jun": time.June, "june": time.June,
+	"jul": time.July, "july": time.July,
+	"aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// weekdayNames maps full and abbreviated weekday names to weekdays
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ValidateDateOrder validates a date order setting
+func ValidateDateOrder(order string) error {
+	if DateOrder(order) != DayMonthYear && DateOrder(order) != MonthDayYear {
+		return ErrInvalidDateOrder
+	}
+	return nil
+}
+
+// NormalizeDate parses a date relative to now and returns it in YYYY-MM-DD format.
+//
+// Accepted forms are YYYY-MM-DD, today/yesterday/tomorrow, offsets such as -3d,
+// -2w, -1m or -1y, phrases such as "3 days ago" or "2 weeks ago", weekdays
+// ("monday" is the latest Monday up to today, "last monday" the one before
+// today), month names ("jan 5", "5 january 2024") and numeric dates such as
+// 05/01/2024 read in the given day/month order. Dates without a year resolve
+// to their latest occurrence up to today.
+func NormalizeDate(input string, now time.Time, order DateOrder) (string, error) {
+	date, err := ParseDate(input, now, order)
+	if err != nil {
+		return "", err
+	}
+	return date.Format(DateFormat), nil
+}
+
+// ParseDate parses a date relative to now. See NormalizeDate for the accepted forms.
+func ParseDate(input string, now time.Time, order DateOrder) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// Normalise case, commas and repeated spaces ("January 5, 2024" -> "january 5 2024")
+	text := strings.Join(strings.Fields(strings.ReplaceAll(strings.ToLower(input), ",", " ")), " ")
+	if text == "" {
+		return time.Time{}, ErrInvalidDateFormat
+	}
+
+	// Stored format first, so existing usage keeps working
+	if date, err := time.ParseInLocation(DateFormat, text, now.Location()); err == nil {
+		return date, nil
+	}
+	if date, err := time.ParseInLocation("2006/01/02", text, now.Location()); err == nil {
+		return date, nil
+	}
+
+	switch text {
+	case "today", "now":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if m := relativeShortPattern.FindStringSubmatch(text); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		if m[1] == "-" {
+			n = -n
+		}
+		return offsetDate(today, n, m[3]), nil
+	}
+
+	if m := agoPattern.FindStringSubmatch(text); m != nil {
+		n := 1
+		if m[1] != "a" && m[1] != "an" && m[1] != "one" {
+			n, _ = strconv.Atoi(m[1])
+		}
+		return offsetDate(today, -n, m[2][:1]), nil
+	}
+
+	if date, ok := parseWeekday(text, today); ok {
+		return date, nil
+	}
+
+	if date, ok, err := parseMonthName(text, today); ok {
+		return date, err
+	}
+
+	if m := numericPattern.FindStringSubmatch(text); m != nil {
+		first, _ := strconv.Atoi(m[1])
+		second, _ := strconv.Atoi(m[2])
+		day, month := first, second
+		if order == MonthDayYear {
+			day, month = second, first
+		}
+		return resolveDate(today, m[3], time.Month(month), day)
+	}
+
+	return time.Time{}, ErrUnrecognizedDate
+}
+
+// ValidateNotFuture returns ErrFutureDate when a YYYY-MM-DD date is after today
+func ValidateNotFuture(date string, now time.Time) error {
+	if date > now.Format(DateFormat) {
+		return ErrFutureDate
+	}
+	return nil
+}
+
+// offsetDate moves a date by n days, weeks, months or years (unit d, w, m or y).
+// Month and year offsets keep the day of month, clamped to the target month's length.
+func offsetDate(date time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "w":
+		return date.AddDate(0, 0, 7*n)
+	case "m":
+		return addMonths(date, n)
+	case "y":
+		return addMonths(date, 12*n)
+	default:
+		return date.AddDate(0, 0, n)
+	}
+}
+
+// addMonths adds months to a date without overflowing into the following month
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location()).AddDate(0, months, 0)
+	day := date.Day()
+	if last := daysIn(first.Year(), first.Month()); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, date.Location())
+}
+
+// daysIn returns the number of days in a month
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// parseWeekday parses "monday" (latest Monday up to today) and "last monday"
+// (latest Monday before today)
+func parseWeekday(text string, today time.Time) (time.Time, bool) {
+	name, strict := text, false
+	if rest, found := strings.CutPrefix(text, "last "); found {
+		name, strict = rest, true
+	}
+
+	weekday, ok := weekdayNames[name]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	daysBack := (int(today.Weekday()) - int(weekday) + 7) % 7
+	if strict && daysBack == 0 {
+		daysBack = 7
+	}
+	return today.AddDate(0, 0, -daysBack), true
+}
+
+// parseMonthName parses dates with a month name: "jan 5", "5 jan", "jan 5 2024",
+// "5th january 2024". ok is false when the text has no month name.
+func parseMonthName(text string, today time.Time) (date time.Time, ok bool, err error) {
+	tokens := strings.Fields(text)
+	if len(tokens) < 2 || len(tokens) > 3 {
+		return time.Time{}, false, nil
+	}
+
+	monthIndex := -1
+	var month time.Month
+	for i, token := range tokens[:2] {
+		if m, found := monthNames[strings.TrimSuffix(token, ".")]; found {
+			monthIndex, month = i, m
+			break
+		}
+	}
+	if monthIndex < 0 {
+		return time.Time{}, false, nil
+	}
+
+	dayToken := tokens[1-monthIndex]
+	if m := ordinalSuffix.FindStringSubmatch(dayToken); m != nil {
+		dayToken = m[1]
+	}
+	day, convErr := strconv.Atoi(dayToken)
+	if convErr != nil {
+		return time.Time{}, true, ErrUnrecognizedDate
+	}
+
+	year := ""
+	if len(tokens) == 3 {
+		year = tokens[2]
+	}
+
+	date, err = resolveDate(today, year, month, day)
+	return date, true, err
+}
+
+// resolveDate builds a date from its parts. A two-digit year is in the 2000s;
+// without a year, the latest occurrence up to today is used.
+func resolveDate(today time.Time, yearText string, month time.Month, day int) (time.Time, error) {
+	if month < time.January || month > time.December || day < 1 {
+		return time.Time{}, ErrInvalidDate
+	}
+
+	year := today.Year()
+	if yearText != "" {
+		var err error
+		year, err = strconv.Atoi(yearText)
+		if err != nil || (len(yearText) != 2 && len(yearText) != 4) {
+			return time.Time{}, ErrUnrecognizedDate
+		}
+		if len(yearText) == 2 {
+			year += 2000
+		}
+	}
+
+	if day > daysIn(year, month) {
+		// Feb 29 without a year may still resolve to the previous leap year
+		if yearText != "" || day > 29 || month != time.February {
+			return time.Time{}, ErrInvalidDate
+		}
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if yearText == "" {
+		for date.After(today) || date.Day() != day {
+			year--
+			date = time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+		}
+	}
+
+	return date, nil
+}
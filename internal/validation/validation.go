@@ -2,14 +2,23 @@ package validation
 
 import (
 	"errors"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // Date format constants
 const DateFormat = "2006-01-02"
 
+// TimeFormat is the format for the optional time of day on an entry
+const TimeFormat = "15:04"
+
+// MaxNoteLength is the maximum number of characters in an entry note
+const MaxNoteLength = 200
+
 // Weight bounds (in any unit)
 const (
 	MinWeight = 1.0
@@ -22,6 +31,9 @@ const (
 	MaxBMI = 100.0
 )
 
+// MaxIDListSize is the maximum number of IDs an ID list may expand to
+const MaxIDListSize = 10000
+
 // Height bounds
 const (
 	MinHeightCm = 50.0
@@ -39,6 +51,14 @@ var (
 	ErrInvalidDate       = errors.New("date must be in YYYY-MM-DD format and be a valid date")
 	ErrNegativeNumber    = errors.New("value must be a positive number")
 	ErrInvalidDateFormat = errors.New("date format must be YYYY-MM-DD")
+	ErrInvalidTime       = errors.New("time must be in HH:MM format (24-hour)")
+	ErrNoteTooLong       = errors.New("note must be at most 200 characters")
+	ErrInvalidDays       = errors.New("age must be a positive number of days or weeks, e.g. 30d or 4w")
+	ErrInvalidIDList     = errors.New("IDs must be positive numbers or ranges, e.g. 3,5,9-14")
+	ErrIDRangeTooLarge   = errors.New("ID ranges may cover at most 10000 IDs")
+	ErrInvalidTag        = errors.New("tag must be letters, digits, '-' or '_', e.g. import or #import")
+	ErrInvalidSex        = errors.New("sex must be 'male', 'female' or 'none'")
+	ErrInvalidBirthDate  = errors.New("birth date must be 'none' or a YYYY-MM-DD date that isn't in the future")
 )
 
 // ValidateDate validates a date string is in YYYY-MM-DD format and is a valid date
@@ -56,12 +76,34 @@ func ValidateDate(dateStr string) error {
 	return nil
 }
 
+// ValidateTime validates a time string is in HH:MM format.
+// An empty string is valid and means no time was recorded.
+func ValidateTime(timeStr string) error {
+	if timeStr == "" {
+		return nil
+	}
+
+	if _, err := time.Parse(TimeFormat, timeStr); err != nil {
+		return ErrInvalidTime
+	}
+
+	return nil
+}
+
+// ValidateNote validates a note is not too long
+func ValidateNote(note string) error {
+	if utf8.RuneCountInString(note) > MaxNoteLength {
+		return ErrNoteTooLong
+	}
+	return nil
+}
+
 // ValidateWeight validates a weight value is within reasonable bounds
 func ValidateWeight(weight float64) error {
 	if weight <= 0 {
 		return ErrNegativeNumber
 	}
-	if weight < MinWeight || weight > MaxWeight {
+	if math.IsNaN(weight) || weight < MinWeight || weight > MaxWeight {
 		return ErrInvalidWeight
 	}
 	return nil
@@ -126,3 +168,102 @@ func ParseAndValidateWeight(s string) (float64, error) {
 
 	return weight, nil
 }
+
+// ParseDays parses an age such as "30d", "4w" or "30" (days) into a number of days
+func ParseDays(s string) (int, error) {
+	trimmed := strings.ToLower(strings.TrimSpace(s))
+
+	multiplier := 1
+	if strings.HasSuffix(trimmed, "w") {
+		multiplier = 7
+		trimmed = strings.TrimSuffix(trimmed, "w")
+	} else {
+		trimmed = strings.TrimSuffix(trimmed, "d")
+	}
+
+	days, err := strconv.Atoi(trimmed)
+	if err != nil || days <= 0 {
+		return 0, ErrInvalidDays
+	}
+
+	return days * multiplier, nil
+}
+
+// ParseTag parses a tag as written in notes, with or without its leading #,
+// into the lower-case tag without the #
+func ParseTag(s string) (string, error) {
+	tag := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "#"))
+	if tag == "" {
+		return "", ErrInvalidTag
+	}
+	for _, r := range tag {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", ErrInvalidTag
+		}
+	}
+	return tag, nil
+}
+
+// ParseIDList parses a comma-separated list of IDs and inclusive ranges such as
+// "3,5,9-14" into a sorted list of unique IDs
+func ParseIDList(s string) ([]int, error) {
+	seen := make(map[int]bool)
+	var ids []int
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, ErrInvalidIDList
+		}
+
+		first, last := part, part
+		if start, end, isRange := strings.Cut(part, "-"); isRange {
+			first, last = strings.TrimSpace(start), strings.TrimSpace(end)
+		}
+
+		low, err := strconv.Atoi(first)
+		if err != nil || low <= 0 {
+			return nil, ErrInvalidIDList
+		}
+		high, err := strconv.Atoi(last)
+		if err != nil || high < low {
+			return nil, ErrInvalidIDList
+		}
+		if high-low >= MaxIDListSize {
+			return nil, ErrIDRangeTooLarge
+		}
+
+		for id := low; id <= high; id++ {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > MaxIDListSize {
+			return nil, ErrIDRangeTooLarge
+		}
+	}
+
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// ValidateSex validates the sex setting used by the ideal weight formulas
+func ValidateSex(sex string) error {
+	if sex != "male" && sex != "female" && sex != "none" {
+		return ErrInvalidSex
+	}
+	return nil
+}
+
+// ValidateBirthDate validates the birth date setting used for BMI-for-age
+// percentiles: "none" or a YYYY-MM-DD date up to today
+func ValidateBirthDate(date string) error {
+	if date == "none" {
+		return nil
+	}
+	if ValidateDate(date) != nil || ValidateNotFuture(date, time.Now()) != nil {
+		return ErrInvalidBirthDate
+	}
+	return nil
+}
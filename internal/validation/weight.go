@@ -0,0 +1,177 @@
+package validation
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tryonlinux/thicc/internal/calculator"
+)
+
+// Weight input errors
+var (
+	ErrInvalidWeightInput = errors.New("invalid weight: use a number with an optional unit, e.g. 75.4, 165.4lb, 75kg or 11st 4lb")
+	ErrDecimalComma       = errors.New("invalid weight: use '.' as the decimal separator, or set a locale that uses ',' with: thicc config locale <code>")
+	ErrInvalidLocale      = errors.New("locale must be a language code such as en, de or fr-CA")
+	ErrInvalidWeightUnit  = errors.New("weight unit must be 'lbs', 'kg' or 'st'")
+	ErrInvalidPrecision   = errors.New("precision must be 'auto' or a number of decimals from 0 to 4")
+	ErrInvalidIncrement   = errors.New("increment must be 'none' or a positive number up to 10, e.g. 0.2 or 0.05")
+	ErrInvalidBMIStandard = errors.New("BMI standard must be 'who' or 'asian'")
+)
+
+var (
+	// weightInputPattern matches a number with an optional unit suffix
+	weightInputPattern = regexp.MustCompile(`^([0-9]+(?:[.,][0-9]+)?)\s*([a-z]*)$`)
+
+	// stonesPoundsPattern matches stones with optional pounds, e.g. "11st 4lb" or "11 st 4.5"
+	stonesPoundsPattern = regexp.MustCompile(`^([0-9]+(?:[.,][0-9]+)?)\s*(?:st|stone|stones)\s*(?:([0-9]+(?:[.,][0-9]+)?)\s*(?:lb|lbs|pound|pounds)?)?$`)
+
+	// localePattern matches language codes with an optional region, e.g. de or pt-BR
+	localePattern = regexp.MustCompile(`^[a-z]{2,3}(?:[-_][a-z]{2})?$`)
+)
+
+// unitSuffixes maps accepted unit suffixes to weight units
+var unitSuffixes = map[string]string{
+	"kg": "kg", "kgs": "kg", "kilo": "kg", "kilos": "kg", "kilogram": "kg", "kilograms": "kg",
+	"lb": "lbs", "lbs": "lbs", "pound": "lbs", "pounds": "lbs",
+	"st": "st", "stone": "st", "stones": "st",
+}
+
+// decimalCommaLanguages lists languages that write decimals with a comma
+var decimalCommaLanguages = map[string]bool{
+	"bg": true, "cs": true, "da": true, "de": true, "el": true, "es": true, "et": true,
+	"fi": true, "fr": true, "hr": true, "hu": true, "id": true, "it": true, "lt": true,
+	"lv": true, "nb": true, "nl": true, "nn": true, "no": true, "pl": true, "pt": true,
+	"ro": true, "ru": true, "sk": true, "sl": true, "sr": true, "sv": true, "tr": true,
+	"uk": true, "vi": true,
+}
+
+// ValidateWeightUnit validates a weight unit setting
+func ValidateWeightUnit(unit string) error {
+	if unit != "lbs" && unit != "kg" && unit != "st" {
+		return ErrInvalidWeightUnit
+	}
+	return nil
+}
+
+// ValidatePrecision validates a display precision setting: "auto" or 0 to 4 decimals
+func ValidatePrecision(precision string) error {
+	if precision == "auto" {
+		return nil
+	}
+	decimals, err := strconv.Atoi(precision)
+	if err != nil || decimals < 0 || decimals > 4 {
+		return ErrInvalidPrecision
+	}
+	return nil
+}
+
+// ValidateIncrement validates a scale increment setting: "none" or a positive
+// number up to 10 such as 0.2 or 0.05
+func ValidateIncrement(increment string) error {
+	if increment == "none" {
+		return nil
+	}
+	// ParseFloat accepts "nan", which fails every comparison below
+	value, err := strconv.ParseFloat(increment, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) || value <= 0 || value > 10 {
+		return ErrInvalidIncrement
+	}
+	return nil
+}
+
+// ValidateBMIStandard validates the BMI standard setting, the cut-offs BMIs
+// are categorized by
+func ValidateBMIStandard(standard string) error {
+	for _, s := range calculator.BMIStandards {
+		if s.Key == standard {
+			return nil
+		}
+	}
+	return ErrInvalidBMIStandard
+}
+
+// ValidateLocale validates a locale setting such as "en", "de" or "fr-CA"
+func ValidateLocale(locale string) error {
+	if !localePattern.MatchString(strings.ToLower(locale)) {
+		return ErrInvalidLocale
+	}
+	return nil
+}
+
+// UsesDecimalComma reports whether a locale writes decimals with a comma
+func UsesDecimalComma(locale string) bool {
+	language, _, _ := strings.Cut(strings.ReplaceAll(strings.ToLower(locale), "_", "-"), "-")
+	return decimalCommaLanguages[language]
+}
+
+// ParseWeightInput parses a weight typed by the user and converts it to the
+// given unit. The weight may have a unit suffix (75kg, 165.4 lb) or be given
+// in stones and pounds (11st 4lb). Locales that write decimals with a comma
+// also accept 75,4.
+func ParseWeightInput(s, unit, locale string) (float64, error) {
+	text := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	if text == "" {
+		return 0, ErrNegativeNumber
+	}
+	if strings.HasPrefix(text, "-") {
+		return 0, ErrNegativeNumber
+	}
+
+	var weight float64
+	if m := stonesPoundsPattern.FindStringSubmatch(text); m != nil {
+		stones, err := parseDecimal(m[1], locale)
+		if err != nil {
+			return 0, err
+		}
+		pounds := 0.0
+		if m[2] != "" {
+			pounds, err = parseDecimal(m[2], locale)
+			if err != nil {
+				return 0, err
+			}
+		}
+		weight = calculator.ConvertWeight(stones*calculator.LbsPerSt+pounds, "lbs", unit)
+	} else if m := weightInputPattern.FindStringSubmatch(text); m != nil {
+		value, err := parseDecimal(m[1], locale)
+		if err != nil {
+			return 0, err
+		}
+		from := unit
+		if m[2] != "" {
+			suffixUnit, ok := unitSuffixes[m[2]]
+			if !ok {
+				return 0, ErrInvalidWeightInput
+			}
+			from = suffixUnit
+		}
+		weight = calculator.ConvertWeight(value, from, unit)
+	} else {
+		return 0, ErrInvalidWeightInput
+	}
+
+	if err := ValidateWeight(weight); err != nil {
+		return 0, err
+	}
+
+	return weight, nil
+}
+
+// parseDecimal parses a decimal number, accepting a comma as the decimal
+// separator only for locales that use one
+func parseDecimal(s, locale string) (float64, error) {
+	if strings.Contains(s, ",") {
+		if !UsesDecimalComma(locale) {
+			return 0, ErrDecimalComma
+		}
+		s = strings.Replace(s, ",", ".", 1)
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrInvalidWeightInput
+	}
+	return value, nil
+}
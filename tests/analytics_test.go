@@ -0,0 +1,478 @@
+package tests
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+func TestSummarize(t *testing.T) {
+	weights := []models.Weight{
+		{Date: "2024-02-10", Weight: 80},
+		{Date: "2024-02-01", Weight: 83},
+		{Date: "2024-01-20", Weight: 81},
+		{Date: "2024-01-01", Weight: 84},
+	}
+
+	s := analytics.Summarize(weights)
+	if s.Count != 4 || s.From != "2024-01-01" || s.To != "2024-02-10" {
+		t.Errorf("Summarize() = %d entries from %s to %s, want 4 from 2024-01-01 to 2024-02-10", s.Count, s.From, s.To)
+	}
+	if s.Min != 80 || s.Max != 84 || s.Mean != 82 || s.Change() != -4 {
+		t.Errorf("Summarize() min %.1f max %.1f mean %.1f change %.1f, want 80, 84, 82, -4", s.Min, s.Max, s.Mean, s.Change())
+	}
+
+	months := analytics.MonthlySummaries(weights)
+	if len(months) != 2 {
+		t.Fatalf("MonthlySummaries() returned %d months, want 2", len(months))
+	}
+	jan, feb := months[0], months[1]
+	if jan.Start.Format("2006-01") != "2024-01" || jan.Count != 2 || jan.Change() != -3 {
+		t.Errorf("January = %s with %d entries, change %.1f; want 2024-01, 2, -3", jan.Start.Format("2006-01"), jan.Count, jan.Change())
+	}
+	if feb.Start.Format("2006-01") != "2024-02" || feb.Count != 2 || feb.Mean != 81.5 {
+		t.Errorf("February = %s with %d entries, mean %.1f; want 2024-02, 2, 81.5", feb.Start.Format("2006-01"), feb.Count, feb.Mean)
+	}
+
+	if s := analytics.Summarize(nil); s.Count != 0 {
+		t.Errorf("Summarize(nil) has %d entries, want 0", s.Count)
+	}
+}
+
+func TestGoalProgress(t *testing.T) {
+	tests := []struct {
+		start, latest, goal float64
+		expected            float64
+	}{
+		{200, 175, 150, 0.5},
+		{200, 210, 150, 0},   // moving away from the goal
+		{200, 140, 150, 1},   // past the goal
+		{120, 125, 130, 0.5}, // gaining towards a goal
+		{150, 150, 150, 1},
+	}
+
+	for _, tt := range tests {
+		if got := analytics.GoalProgress(tt.start, tt.latest, tt.goal); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("GoalProgress(%.0f, %.0f, %.0f) = %.2f, want %.2f", tt.start, tt.latest, tt.goal, got, tt.expected)
+		}
+	}
+}
+
+func TestRatePerWeek(t *testing.T) {
+	weights := []models.Weight{
+		{Date: "2024-01-29", Weight: 96},
+		{Date: "2024-01-15", Weight: 98},
+		{Date: "2024-01-08", Weight: 99},
+		{Date: "2024-01-01", Weight: 100},
+	}
+
+	if got := analytics.RatePerWeek(weights); math.Abs(got+1) > 1e-9 {
+		t.Errorf("RatePerWeek() = %.2f, want -1", got)
+	}
+	if got := analytics.RatePerWeek(weights[:1]); got != 0 {
+		t.Errorf("RatePerWeek() of one entry = %.2f, want 0", got)
+	}
+	if recent := analytics.Recent(weights, 14); len(recent) != 2 {
+		t.Errorf("Recent(14 days) returned %d entries, want 2", len(recent))
+	}
+}
+
+func TestGoalPace(t *testing.T) {
+	weights := []models.Weight{
+		{Date: "2024-01-29", Weight: 96},
+		{Date: "2024-01-15", Weight: 98},
+		{Date: "2024-01-08", Weight: 99},
+		{Date: "2024-01-01", Weight: 100},
+	}
+
+	tests := []struct {
+		name        string
+		goal        float64
+		deadline    string
+		required    float64
+		onTrack     bool
+		tooFast     bool
+		underweight bool
+	}{
+		{"on track", 93, "2024-03-11", -0.5, true, false, false},
+		{"too fast", 90, "2024-02-12", -3, false, true, false},
+		{"underweight goal", 55, "2025-01-27", -41.0 / 52, true, false, true},
+		{"deadline passed", 90, "2024-01-22", 0, false, false, false},
+		{"no deadline", 90, "", 0, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: tt.goal}
+			pace, err := analytics.Pace(weights, settings, tt.deadline, "2024-01-29")
+			if err != nil {
+				t.Fatalf("Pace() returned error: %v", err)
+			}
+			if math.Abs(pace.Required-tt.required) > 1e-9 {
+				t.Errorf("Required = %.3f, want %.3f", pace.Required, tt.required)
+			}
+			if math.Abs(pace.Trend+1) > 1e-9 {
+				t.Errorf("Trend = %.3f, want -1", pace.Trend)
+			}
+			if pace.OnTrack != tt.onTrack || pace.TooFast != tt.tooFast || pace.Underweight != tt.underweight {
+				t.Errorf("OnTrack, TooFast, Underweight = %v, %v, %v, want %v, %v, %v",
+					pace.OnTrack, pace.TooFast, pace.Underweight, tt.onTrack, tt.tooFast, tt.underweight)
+			}
+		})
+	}
+
+	if _, err := analytics.Pace(weights, &models.Settings{GoalWeight: 90}, "12/31", "2024-01-29"); err == nil {
+		t.Error("Pace() with an invalid deadline expected error")
+	}
+}
+
+func TestBMIHistory(t *testing.T) {
+	weights := []models.Weight{
+		{Date: "2024-03-01", BMI: 24.5},
+		{Date: "2024-02-01", BMI: 24.9},
+		{Date: "2024-01-15", BMI: 25.2},
+		{Date: "2024-01-01", BMI: 26},
+	}
+
+	periods := analytics.BMIHistory(weights)
+	if len(periods) != 2 {
+		t.Fatalf("BMIHistory() returned %d periods, want 2", len(periods))
+	}
+	over, normal := periods[0], periods[1]
+	if over.Category.Name != "Overweight" || over.From != "2024-01-01" || over.To != "2024-01-15" || over.Entries != 2 {
+		t.Errorf("first period = %s from %s to %s with %d entries, want Overweight from 2024-01-01 to 2024-01-15 with 2",
+			over.Category.Name, over.From, over.To, over.Entries)
+	}
+	if normal.Category.Name != "Normal" || normal.MinBMI != 24.5 || normal.MaxBMI != 24.9 {
+		t.Errorf("second period = %s with BMI %.1f to %.1f, want Normal with 24.5 to 24.9", normal.Category.Name, normal.MinBMI, normal.MaxBMI)
+	}
+}
+
+func TestSummaries(t *testing.T) {
+	weights := []models.Weight{
+		{Date: "2024-12-31", Weight: 78}, // Tuesday
+		{Date: "2024-12-30", Weight: 79}, // Monday
+		{Date: "2024-12-29", Weight: 80}, // Sunday
+		{Date: "2024-01-02", Weight: 84},
+	}
+
+	tests := []struct {
+		period analytics.Period
+		starts []string
+	}{
+		{analytics.Week, []string{"2024-01-01", "2024-12-23", "2024-12-30"}},
+		{analytics.Month, []string{"2024-01-01", "2024-12-01"}},
+		{analytics.Year, []string{"2024-01-01"}},
+	}
+
+	for _, tt := range tests {
+		periods := analytics.Summaries(weights, tt.period)
+		var starts []string
+		for _, p := range periods {
+			starts = append(starts, p.Start.Format("2006-01-02"))
+		}
+		if strings.Join(starts, " ") != strings.Join(tt.starts, " ") {
+			t.Errorf("Summaries(%s) starts %v, want %v", tt.period, starts, tt.starts)
+		}
+	}
+
+	weeks := analytics.Summaries(weights, analytics.Week)
+	if last := weeks[len(weeks)-1]; last.Count != 2 || last.Change() != -1 || last.RatePerWeek != -7 {
+		t.Errorf("last week has %d entries, change %.1f, rate %.1f; want 2, -1, -7", last.Count, last.Change(), last.RatePerWeek)
+	}
+	if end := analytics.Week.End(weeks[0].Start).Format("2006-01-02"); end != "2024-01-07" {
+		t.Errorf("Week.End() = %s, want 2024-01-07", end)
+	}
+	if changes := analytics.MeanChanges(weeks); len(changes) != 2 || changes[0] != -4 || changes[1] != -1.5 {
+		t.Errorf("MeanChanges() = %v, want [-4 -1.5]", changes)
+	}
+
+	if _, err := analytics.ParsePeriod("day"); err == nil {
+		t.Error("ParsePeriod(\"day\") should return an error")
+	}
+}
+
+func TestDaysAndStreaks(t *testing.T) {
+	weights := []models.Weight{
+		{Date: "2024-03-10", Weight: 79},
+		{Date: "2024-03-09", Time: "20:00", Weight: 80.5},
+		{Date: "2024-03-09", Time: "07:00", Weight: 81},
+		{Date: "2024-03-08", Weight: 80},
+		{Date: "2024-03-01", Weight: 82},
+		{Date: "2024-02-29", Weight: 83},
+	}
+
+	days := analytics.Days(weights)
+	if len(days) != 5 {
+		t.Fatalf("Days() returned %d days, want 5", len(days))
+	}
+	if !days[0].First || days[1].First {
+		t.Error("only the first day should be marked First")
+	}
+	// The last entry of a day counts
+	if d := days[3]; d.Date != "2024-03-09" || d.Weight != 80.5 || d.Change != 0.5 {
+		t.Errorf("day = %s %.1f change %.1f, want 2024-03-09 80.5 change 0.5", d.Date, d.Weight, d.Change)
+	}
+
+	tests := []struct {
+		today   string
+		current int
+	}{
+		{"2024-03-10", 3},
+		{"2024-03-11", 3}, // no entry yet today
+		{"2024-03-12", 0},
+	}
+	for _, tt := range tests {
+		current, longest := analytics.Streaks(days, tt.today)
+		if current != tt.current || longest != 3 {
+			t.Errorf("Streaks(%s) = %d, %d; want %d, 3", tt.today, current, longest, tt.current)
+		}
+	}
+
+	// The entries are in the weeks of February 26 and March 4
+	if current, longest := analytics.WeekStreaks(days, "2024-03-17"); current != 2 || longest != 2 {
+		t.Errorf("WeekStreaks() = %d, %d; want 2, 2", current, longest)
+	}
+	if current, _ := analytics.WeekStreaks(days, "2024-03-18"); current != 0 {
+		t.Errorf("WeekStreaks() two weeks later = %d, want 0", current)
+	}
+
+	// Days from March 1 end at 82, 80, 80.5 and 79
+	if in, total := analytics.DaysInRange(days, "2024-03-01", 79.5, 82); in != 3 || total != 4 {
+		t.Errorf("DaysInRange() = %d of %d, want 3 of 4", in, total)
+	}
+}
+
+func TestMilestones(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", GoalWeight: 80}
+	// Eight days in a row, losing from 90 kg to the goal
+	weights := []models.Weight{
+		{ID: 8, Date: "2024-01-08", Weight: 79.5, BMI: 24.5},
+		{ID: 7, Date: "2024-01-07", Weight: 82, BMI: 25.3},
+		{ID: 6, Date: "2024-01-06", Weight: 84, BMI: 25.9},
+		{ID: 5, Date: "2024-01-05", Weight: 86.5, BMI: 26.7},
+		{ID: 4, Date: "2024-01-04", Weight: 85, BMI: 26.2},
+		{ID: 3, Date: "2024-01-03", Weight: 87, BMI: 26.9},
+		{ID: 2, Date: "2024-01-02", Weight: 88, BMI: 27.2},
+		{ID: 1, Date: "2024-01-01", Weight: 90, BMI: 27.8},
+	}
+
+	var got []string
+	for _, m := range analytics.Milestones(weights, settings) {
+		got = append(got, fmt.Sprintf("%s@%d", m.Key, m.WeightID))
+	}
+	want := []string{
+		"lost-1@2", // 2 kg
+		"lost-2@4", // 4 kg, not again when regained on day 5
+		"lost-3@6", "halfway-80@4",
+		"lost-4@7", "streak-days-7@7",
+		"lost-5@8", "bmi-Normal@8", "goal-80@8",
+	}
+	slices.Sort(got)
+	slices.Sort(want)
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Milestones() = %v, want %v", got, want)
+	}
+
+	// Gaining towards a goal counts gains
+	gaining := &models.Settings{WeightUnit: "lbs", GoalWeight: 150}
+	milestones := analytics.Milestones([]models.Weight{
+		{ID: 2, Date: "2024-01-08", Weight: 140},
+		{ID: 1, Date: "2024-01-01", Weight: 134},
+	}, gaining)
+	if len(milestones) != 1 || milestones[0].Key != "gained-1" || milestones[0].Amount != 5 {
+		t.Errorf("Milestones() while gaining = %+v, want gained-1 of 5 lbs", milestones)
+	}
+}
+
+func TestStaleAchievements(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "lbs", GoalWeight: 150}
+	achievements := []models.Achievement{
+		{ID: 1, Key: "lost-1", WeightID: 2},
+		{ID: 2, Key: "goal-160", WeightID: 2}, // reached under an earlier goal
+		{ID: 3, Key: "halfway-150", WeightID: 2},
+	}
+
+	// While the entry still reaches them, only the current goal's milestone it never reached is stale
+	weights := []models.Weight{
+		{ID: 2, Date: "2024-01-02", Weight: 194},
+		{ID: 1, Date: "2024-01-01", Weight: 200},
+	}
+	stale := analytics.StaleAchievements(achievements, weights, settings)
+	if len(stale) != 1 || stale[0].ID != 3 {
+		t.Errorf("StaleAchievements() = %+v, want only halfway-150", stale)
+	}
+
+	// Changing the weight so it no longer reaches 5 lbs lost revokes that too
+	weights[0].Weight = 199
+	stale = analytics.StaleAchievements(achievements, weights, settings)
+	if len(stale) != 2 || stale[0].ID != 1 || stale[1].ID != 3 {
+		t.Errorf("StaleAchievements() after the change = %+v, want lost-1 and halfway-150", stale)
+	}
+
+	// A milestone now first reached by another entry is stale, so it's recorded again for that one
+	weights = append([]models.Weight{{ID: 3, Date: "2024-01-03", Weight: 194}}, weights...)
+	stale = analytics.StaleAchievements(achievements[:1], weights, settings)
+	if len(stale) != 1 || stale[0].ID != 1 {
+		t.Errorf("StaleAchievements() with another entry reaching it = %+v, want lost-1", stale)
+	}
+}
+
+func TestSuggestGoals(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "in", Height: 70}
+
+	// Without a sex only the healthy BMI range is suggested
+	suggestions := analytics.SuggestGoals(settings)
+	if len(suggestions) != 1 || !suggestions[0].IsRange() {
+		t.Fatalf("Expected only the healthy range, got %+v", suggestions)
+	}
+	low := calculator.CalculateBMI(suggestions[0].Low, 70, "kg", "in")
+	high := calculator.CalculateBMI(suggestions[0].High, 70, "kg", "in")
+	if math.Abs(low-18.5) > 1e-9 || math.Abs(high-24.9) > 1e-9 {
+		t.Errorf("Healthy range BMIs = %.2f-%.2f, want 18.5-24.9", low, high)
+	}
+
+	// Ten inches over five feet
+	expected := map[string]map[string]float64{
+		calculator.Male:   {"devine": 73, "robinson": 71, "miller": 70.3, "hamwi": 75},
+		calculator.Female: {"devine": 68.5, "robinson": 66, "miller": 66.7, "hamwi": 67.5},
+	}
+	for sex, weights := range expected {
+		settings.Sex = sex
+		suggestions := analytics.SuggestGoals(settings)
+		if len(suggestions) != 5 {
+			t.Fatalf("Expected 5 suggestions for %s, got %d", sex, len(suggestions))
+		}
+		for key, weight := range weights {
+			s, ok := analytics.FindSuggestion(suggestions, strings.ToUpper(key))
+			if !ok || s.IsRange() || math.Abs(s.Low-weight) > 1e-9 {
+				t.Errorf("%s suggestion for %s = %+v, want %.1f kg", key, sex, s, weight)
+			}
+		}
+	}
+
+	// Metric heights are converted to inches, and weights to the user's unit
+	settings = &models.Settings{WeightUnit: "lbs", HeightUnit: "cm", Height: 177.8, Sex: calculator.Male}
+	s, _ := analytics.FindSuggestion(analytics.SuggestGoals(settings), "devine")
+	if math.Abs(s.Low-73*calculator.LbsPerKg) > 1e-6 {
+		t.Errorf("Devine for 177.8 cm = %.2f lbs, want %.2f", s.Low, 73*calculator.LbsPerKg)
+	}
+	if _, ok := analytics.FindSuggestion(analytics.SuggestGoals(settings), "unknown"); ok {
+		t.Error("Expected no unknown suggestion")
+	}
+}
+
+func TestChildBMI(t *testing.T) {
+	settings := &models.Settings{
+		Sex:       calculator.Female,
+		BirthDate: "2016-01-01",
+		Growth:    calculator.NewLMSTable([]calculator.LMS{{Sex: calculator.Female, AgeMonths: 24, L: 0, M: 16, S: 0.1}, {Sex: calculator.Female, AgeMonths: 240, L: 0, M: 22, S: 0.1}}),
+	}
+
+	// Ten years old, when the median is 16 + 96/216 × 6
+	median := 16 + 6*96.0/216
+	child, ok := analytics.ChildBMI(median, "2026-01-01", settings)
+	if !ok || !child.Known || math.Abs(child.AgeMonths-120) > 0.1 {
+		t.Fatalf("ChildBMI() = %+v, %v; want a known result at 120 months", child, ok)
+	}
+	if math.Abs(child.Percentile-50) > 0.5 || child.Category.Name != "Healthy weight" {
+		t.Errorf("ChildBMI(median) = %.1f percentile %s, want 50 and Healthy weight", child.Percentile, child.Category.Name)
+	}
+	if child, _ := analytics.ChildBMI(median*math.Exp(0.2), "2026-01-01", settings); child.Category.Name != "Obesity" {
+		t.Errorf("Expected two standard deviations above the median to be Obesity, got %s", child.Category.Name)
+	}
+
+	// Under two and without a reference for the sex, the percentile isn't known
+	if child, ok := analytics.ChildBMI(16, "2017-01-01", settings); !ok || child.Known {
+		t.Errorf("Expected an unknown percentile at one year old, got %+v", child)
+	}
+	settings.Sex = calculator.Male
+	if child, ok := analytics.ChildBMI(16, "2026-01-01", settings); !ok || child.Known {
+		t.Errorf("Expected an unknown percentile without a reference for the sex, got %+v", child)
+	}
+
+	// Adults and people without a birth date get the adult categories
+	if _, ok := analytics.ChildBMI(22, "2036-01-01", settings); ok {
+		t.Error("Expected no BMI-for-age at 20")
+	}
+	settings.BirthDate = ""
+	if _, ok := analytics.ChildBMI(22, "2026-01-01", settings); ok {
+		t.Error("Expected no BMI-for-age without a birth date")
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	weights := []models.Weight{
+		{Date: "2024-03-11", Weight: 79}, // Monday
+		{Date: "2024-03-10", Weight: 80},
+		{Date: "2024-03-09", Time: "20:00", Weight: 81},
+		{Date: "2024-03-09", Time: "07:00", Weight: 82},
+		{Date: "2024-03-08", Weight: 80},
+		{Date: "2024-03-05", Weight: 83}, // Tuesday
+	}
+	s := analytics.Statistics(weights)
+
+	if s.Count != 6 || s.Min != 79 || s.Max != 83 {
+		t.Errorf("summary = %d entries %.1f-%.1f, want 6 entries 79.0-83.0", s.Count, s.Min, s.Max)
+	}
+	if s.Median != 80.5 {
+		t.Errorf("Median = %.2f, want 80.50", s.Median)
+	}
+	if math.Abs(s.StdDev-1.472) > 0.001 {
+		t.Errorf("StdDev = %.3f, want 1.472", s.StdDev)
+	}
+	if want := []float64{79.5, 80, 81.75, 82.5}; !slices.Equal(s.Percentiles, want) {
+		t.Errorf("Percentiles = %v, want %v", s.Percentiles, want)
+	}
+
+	// Only 03-08 to 03-09, 03-09 to 03-10 and 03-10 to 03-11 are a day apart
+	if s.DailyFluctuation != 1 {
+		t.Errorf("DailyFluctuation = %.2f, want 1.00", s.DailyFluctuation)
+	}
+	if s.LargestGain != (analytics.DayChange{Date: "2024-03-09", Change: 1}) {
+		t.Errorf("LargestGain = %+v, want +1 on 2024-03-09", s.LargestGain)
+	}
+	if s.LargestLoss != (analytics.DayChange{Date: "2024-03-10", Change: -1}) {
+		t.Errorf("LargestLoss = %+v, want -1 on 2024-03-10", s.LargestLoss)
+	}
+
+	// Streaks run over days with entries whatever the gap
+	if want := (analytics.Streak{Days: 2, From: "2024-03-09", To: "2024-03-11", Change: -2}); s.LosingStreak != want {
+		t.Errorf("LosingStreak = %+v, want %+v", s.LosingStreak, want)
+	}
+	if want := (analytics.Streak{Days: 1, From: "2024-03-08", To: "2024-03-09", Change: 1}); s.GainingStreak != want {
+		t.Errorf("GainingStreak = %+v, want %+v", s.GainingStreak, want)
+	}
+
+	var weekdays []string
+	for _, w := range s.Weekdays {
+		weekdays = append(weekdays, fmt.Sprintf("%s %d %.0f", w.Weekday, w.Days, w.Mean))
+	}
+	if want := []string{"Monday 1 79", "Tuesday 1 83", "Friday 1 80", "Saturday 1 81", "Sunday 1 80"}; !slices.Equal(weekdays, want) {
+		t.Errorf("Weekdays = %v, want %v", weekdays, want)
+	}
+
+	var counts []int
+	for _, b := range s.Histogram {
+		counts = append(counts, b.Count)
+	}
+	if want := []int{1, 0, 2, 0, 1, 0, 1, 1}; !slices.Equal(counts, want) {
+		t.Errorf("Histogram counts = %v, want %v", counts, want)
+	}
+	if last := s.Histogram[len(s.Histogram)-1]; last.High != 83 {
+		t.Errorf("last bin ends at %.2f, want 83.00", last.High)
+	}
+
+	if empty := analytics.Statistics(nil); empty.Count != 0 || empty.Histogram != nil {
+		t.Errorf("Statistics(nil) = %+v, want zero", empty)
+	}
+	if same := analytics.Histogram([]float64{80, 80}, analytics.HistogramBins); len(same) != 1 || same[0].Count != 2 {
+		t.Errorf("Histogram of equal values = %+v, want one bin of 2", same)
+	}
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"math"
+	"strings"
 	"testing"
 
 	"github.com/tryonlinux/thicc/internal/calculator"
@@ -87,3 +88,187 @@ func TestCalculateBMI_Overweight(t *testing.T) {
 		t.Errorf("Expected BMI %.2f, got %.2f", expected, bmi)
 	}
 }
+
+func TestCalculateBMI_Stones(t *testing.T) {
+	// 11 st = 154 lbs = 69.85 kg
+	weight := 11.0 // st
+	height := 175.0 // cm
+	bmi := calculator.CalculateBMI(weight, height, "st", "cm")
+
+	// BMI = 69.85 / (1.75^2) = 22.81
+	expected := 22.81
+	if math.Abs(bmi-expected) > 0.01 {
+		t.Errorf("Expected BMI %.2f, got %.2f", expected, bmi)
+	}
+
+	// Same person in inches: 175 cm = 68.9 in
+	bmiInches := calculator.CalculateBMI(weight, 175.0/2.54, "st", "in")
+	if math.Abs(bmiInches-bmi) > 0.01 {
+		t.Errorf("Expected BMI %.2f with inches, got %.2f", bmi, bmiInches)
+	}
+}
+
+func TestConvertWeight(t *testing.T) {
+	tests := []struct {
+		weight   float64
+		from, to string
+		expected float64
+	}{
+		{100, "kg", "lbs", 220.462},
+		{154, "lbs", "st", 11.0},
+		{11, "st", "kg", 69.853},
+		{70, "kg", "kg", 70},
+	}
+
+	for _, tt := range tests {
+		result := calculator.ConvertWeight(tt.weight, tt.from, tt.to)
+		if math.Abs(result-tt.expected) > 0.001 {
+			t.Errorf("ConvertWeight(%.2f, %s, %s) = %.3f, want %.3f", tt.weight, tt.from, tt.to, result, tt.expected)
+		}
+	}
+}
+
+func TestWeightForBMI(t *testing.T) {
+	// BMI 25 at 180 cm is 25 * 1.8^2 = 81 kg
+	if w := calculator.WeightForBMI(25, 180, "kg", "cm"); math.Abs(w-81) > 0.001 {
+		t.Errorf("WeightForBMI(25, 180 cm) = %.3f kg, want 81", w)
+	}
+
+	// The weight for a BMI gives that BMI back, in every unit
+	for _, units := range [][2]string{{"lbs", "in"}, {"st", "cm"}, {"kg", "in"}} {
+		w := calculator.WeightForBMI(18.5, 70, units[0], units[1])
+		if bmi := calculator.CalculateBMI(w, 70, units[0], units[1]); math.Abs(bmi-18.5) > 0.001 {
+			t.Errorf("CalculateBMI(WeightForBMI(18.5)) in %s/%s = %.3f, want 18.5", units[0], units[1], bmi)
+		}
+	}
+}
+
+func TestBMIStandards(t *testing.T) {
+	who := calculator.BMIStandardFor(calculator.WHOStandard)
+	asian := calculator.BMIStandardFor(calculator.AsianStandard)
+	tests := []struct {
+		bmi   float64
+		who   string
+		asian string
+	}{
+		{17, "Underweight", "Underweight"},
+		{22, "Normal", "Normal"},
+		{24, "Normal", "Overweight"},
+		{28, "Overweight", "Obese class I"},
+		{33, "Obese class I", "Obese class II"},
+		{38, "Obese class II", "Obese class III"},
+		{45, "Obese class III", "Obese class III"},
+	}
+	for _, tt := range tests {
+		if got := who.Category(tt.bmi).Name; got != tt.who {
+			t.Errorf("WHO category for %.1f = %s, want %s", tt.bmi, got, tt.who)
+		}
+		if got := asian.Category(tt.bmi).Name; got != tt.asian {
+			t.Errorf("Asian category for %.1f = %s, want %s", tt.bmi, got, tt.asian)
+		}
+	}
+
+	// BMI Prime is the ratio to the top of the normal range
+	if prime := who.Prime(30); math.Abs(prime-1.2) > 1e-9 {
+		t.Errorf("WHO BMI Prime of 30 = %.3f, want 1.2", prime)
+	}
+	if prime := asian.Prime(23); math.Abs(prime-1) > 1e-9 {
+		t.Errorf("Asian BMI Prime of 23 = %.3f, want 1", prime)
+	}
+
+	if who.Distance(who.Category(36)) != 3 || who.Distance(who.Category(17)) != 1 {
+		t.Error("Expected Obese class II 3 categories from Normal and Underweight 1")
+	}
+	if calculator.BMIStandardFor("unknown").Key != calculator.WHOStandard {
+		t.Error("Expected the WHO standard for an unknown key")
+	}
+}
+
+func TestLMSTable(t *testing.T) {
+	// A synthetic reference in the CDC's format, with the header repeated between the sexes
+	csv := `Sex,Agemos,L,M,S,P50
+1,24,-1,16,0.1,16
+1,48,1,18,0.1,18
+Sex,Agemos,L,M,S,P50
+2,24,0,16,0.1,16
+`
+	values, err := calculator.ParseLMSTable(strings.NewReader(csv), "")
+	if err != nil {
+		t.Fatalf("ParseLMSTable failed: %v", err)
+	}
+	table := calculator.NewLMSTable(values)
+	if len(table[calculator.Male]) != 2 || len(table[calculator.Female]) != 1 {
+		t.Fatalf("Expected 2 male and 1 female ages, got %+v", table)
+	}
+
+	// Halfway between the ages the values are interpolated
+	lms, ok := table.At(calculator.Male, 36)
+	if !ok || lms.L != 0 || lms.M != 17 || lms.S != 0.1 {
+		t.Errorf("At(36) = %+v, want L 0, M 17, S 0.1", lms)
+	}
+	if _, ok := table.At(calculator.Male, 60); ok {
+		t.Error("Expected no values past the oldest age")
+	}
+	if _, ok := table.At(calculator.Female, 30); ok {
+		t.Error("Expected no values past the only age")
+	}
+
+	// At the median the z-score is 0; L = 0 uses the log formula
+	if z := lms.ZScore(17); math.Abs(z) > 1e-9 {
+		t.Errorf("ZScore(median) = %.3f, want 0", z)
+	}
+	if z := lms.ZScore(17 * math.Exp(0.2)); math.Abs(z-2) > 1e-9 {
+		t.Errorf("ZScore(M e^2S) = %.3f, want 2", z)
+	}
+	if z := (calculator.LMS{L: -1, M: 16, S: 0.1}).ZScore(16 / 0.9); math.Abs(z-1) > 1e-9 {
+		t.Errorf("ZScore with L = -1 = %.3f, want 1", z)
+	}
+
+	if p := calculator.Percentile(0); math.Abs(p-50) > 1e-9 {
+		t.Errorf("Percentile(0) = %.2f, want 50", p)
+	}
+	if p := calculator.Percentile(1.6449); math.Abs(p-95) > 0.01 {
+		t.Errorf("Percentile(1.6449) = %.2f, want 95", p)
+	}
+	for percentile, name := range map[float64]string{3: "Underweight", 50: "Healthy weight", 90: "Overweight", 97: "Obesity"} {
+		if got := calculator.CategoryForPercentile(percentile).Name; got != name {
+			t.Errorf("CategoryForPercentile(%.0f) = %s, want %s", percentile, got, name)
+		}
+	}
+
+	// Files for one sex need it given
+	single := "Month,L,M,S\n24,-1,16,0.1\n"
+	if _, err := calculator.ParseLMSTable(strings.NewReader(single), ""); err == nil {
+		t.Error("Expected an error without a Sex column or sex")
+	}
+	values, err = calculator.ParseLMSTable(strings.NewReader(single), calculator.Female)
+	if err != nil || len(values) != 1 || values[0].Sex != calculator.Female {
+		t.Errorf("Expected one female age, got %+v (%v)", values, err)
+	}
+	if _, err := calculator.ParseLMSTable(strings.NewReader("Sex,Agemos,L,M\n1,24,-1,16\n"), ""); err == nil {
+		t.Error("Expected an error without an S column")
+	}
+}
+
+func TestCDCGrowthReference(t *testing.T) {
+	table := calculator.NewLMSTable(calculator.CDCGrowthReference())
+	if len(table) == 0 {
+		t.Skip("internal/calculator/bmiagerev.csv has only its header row; add the CDC's bmiagerev.csv")
+	}
+
+	for _, sex := range []string{calculator.Male, calculator.Female} {
+		ages := table[sex]
+		if len(ages) == 0 || ages[0].AgeMonths > calculator.PediatricMinAgeMonths || ages[len(ages)-1].AgeMonths < calculator.PediatricMaxAgeMonths {
+			t.Errorf("CDC reference for %s covers %d ages, want 24 to 240 months", sex, len(ages))
+		}
+	}
+
+	// The CDC median BMI of boys aged two is 16.575
+	lms, ok := table.At(calculator.Male, 24)
+	if !ok {
+		t.Fatal("CDC reference has no boys aged 24 months")
+	}
+	if p := calculator.Percentile(lms.ZScore(16.575)); math.Abs(p-50) > 0.5 {
+		t.Errorf("percentile of BMI 16.575 for boys aged two = %.1f, want 50", p)
+	}
+}
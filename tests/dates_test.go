@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/validation"
+)
+
+func TestNormalizeDate(t *testing.T) {
+	// Wednesday, 13 March 2024
+	now := time.Date(2024, time.March, 13, 15, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		input    string
+		order    validation.DateOrder
+		expected string
+	}{
+		{"2024-01-05", validation.MonthDayYear, "2024-01-05"},
+		{"2024/01/05", validation.MonthDayYear, "2024-01-05"},
+		{"today", validation.MonthDayYear, "2024-03-13"},
+		{" Yesterday ", validation.MonthDayYear, "2024-03-12"},
+		{"tomorrow", validation.MonthDayYear, "2024-03-14"},
+		{"-3d", validation.MonthDayYear, "2024-03-10"},
+		{"-2w", validation.MonthDayYear, "2024-02-28"},
+		{"-1m", validation.MonthDayYear, "2024-02-13"},
+		{"-1y", validation.MonthDayYear, "2023-03-13"},
+		{"3 days ago", validation.MonthDayYear, "2024-03-10"},
+		{"2 weeks ago", validation.MonthDayYear, "2024-02-28"},
+		{"a month ago", validation.MonthDayYear, "2024-02-13"},
+		{"3d ago", validation.MonthDayYear, "2024-03-10"},
+		{"monday", validation.MonthDayYear, "2024-03-11"},
+		{"wednesday", validation.MonthDayYear, "2024-03-13"},
+		{"last wednesday", validation.MonthDayYear, "2024-03-06"},
+		{"last monday", validation.MonthDayYear, "2024-03-11"},
+		{"jan 5", validation.MonthDayYear, "2024-01-05"},
+		{"5 jan", validation.MonthDayYear, "2024-01-05"},
+		{"January 5, 2023", validation.MonthDayYear, "2023-01-05"},
+		{"5th jan 2023", validation.MonthDayYear, "2023-01-05"},
+		{"dec 25", validation.MonthDayYear, "2023-12-25"},
+		{"05/01/2024", validation.DayMonthYear, "2024-01-05"},
+		{"05/01/2024", validation.MonthDayYear, "2024-05-01"},
+		{"5.1.24", validation.DayMonthYear, "2024-01-05"},
+		{"5/1", validation.DayMonthYear, "2024-01-05"},
+		{"feb 29", validation.MonthDayYear, "2024-02-29"},
+	}
+
+	for _, tt := range tests {
+		got, err := validation.NormalizeDate(tt.input, now, tt.order)
+		if err != nil {
+			t.Errorf("NormalizeDate(%q, %s) error: %v", tt.input, tt.order, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("NormalizeDate(%q, %s) = %s, want %s", tt.input, tt.order, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeDateMonthEndClamp(t *testing.T) {
+	now := time.Date(2024, time.March, 31, 9, 0, 0, 0, time.Local)
+
+	got, err := validation.NormalizeDate("-1m", now, validation.MonthDayYear)
+	if err != nil || got != "2024-02-29" {
+		t.Errorf("NormalizeDate(-1m) on 2024-03-31 = %s, %v; want 2024-02-29", got, err)
+	}
+}
+
+func TestNormalizeDateErrors(t *testing.T) {
+	now := time.Date(2024, time.March, 13, 15, 30, 0, 0, time.Local)
+
+	for _, input := range []string{"", "someday", "2024-13-01", "31/02/2024", "feb 30", "13/13", "jan x"} {
+		if got, err := validation.NormalizeDate(input, now, validation.DayMonthYear); err == nil {
+			t.Errorf("NormalizeDate(%q) = %s, expected error", input, got)
+		}
+	}
+}
+
+func TestValidateNotFuture(t *testing.T) {
+	now := time.Date(2024, time.March, 13, 15, 30, 0, 0, time.Local)
+
+	if err := validation.ValidateNotFuture("2024-03-13", now); err != nil {
+		t.Errorf("Expected today to be allowed, got %v", err)
+	}
+	if err := validation.ValidateNotFuture("2024-03-14", now); err != validation.ErrFutureDate {
+		t.Errorf("Expected ErrFutureDate for tomorrow, got %v", err)
+	}
+}
@@ -1,9 +1,14 @@
 package tests
 
 import (
+	"fmt"
+	"math"
 	"strings"
 	"testing"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
 	"github.com/tryonlinux/thicc/internal/display"
 	"github.com/tryonlinux/thicc/internal/models"
 )
@@ -139,3 +144,455 @@ func TestRenderWeightsTableDelta(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatWeightStones(t *testing.T) {
+	tests := []struct {
+		weight   float64
+		expected string
+		short    string
+	}{
+		{11.0 + 4.5/14, "11 st 4.5 lb", "11st 4lb"},
+		{11.0, "11 st 0.0 lb", "11st 0lb"},
+		{11.0 + 13.97/14, "12 st 0.0 lb", "12st 0lb"},
+		{1.5 / 14, "1.5 lb", "2lb"},
+	}
+
+	for _, tt := range tests {
+		if result := display.FormatWeight(tt.weight, "st"); result != tt.expected {
+			t.Errorf("FormatWeight(%.4f, st) = %s, want %s", tt.weight, result, tt.expected)
+		}
+		if result := display.FormatWeightShort(tt.weight, "st"); result != tt.short {
+			t.Errorf("FormatWeightShort(%.4f, st) = %s, want %s", tt.weight, result, tt.short)
+		}
+	}
+
+	if result := display.FormatWeightShortPrecision(11.0+4.5/14, "st", 1); result != "11st 4.5lb" {
+		t.Errorf("FormatWeightShortPrecision(11st 4.5lb, st, 1) = %s, want 11st 4.5lb", result)
+	}
+}
+
+func TestWeightPrecisionSettings(t *testing.T) {
+	one := 1
+	tests := []struct {
+		name     string
+		settings *models.Settings
+		weight   float64
+		expected string
+		rounded  float64
+	}{
+		{"default", &models.Settings{WeightUnit: "kg"}, 70.27, "70.27 kg", 70.27},
+		{"increment sets decimals", &models.Settings{WeightUnit: "lbs", Increment: 0.2}, 158.27, "158.3 lbs", 158.2},
+		{"precision overrides increment", &models.Settings{WeightUnit: "kg", Increment: 0.05, Precision: &one}, 70.27, "70.3 kg", 70.25},
+		{"precision alone rounds input", &models.Settings{WeightUnit: "kg", Precision: &one}, 70.27, "70.3 kg", 70.3},
+		{"stones round the pounds", &models.Settings{WeightUnit: "st", Increment: 0.5}, 11 + 4.3/14, "11 st 4.3 lb", 11 + 4.5/14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := display.FormatWeightFor(tt.weight, tt.settings); result != tt.expected {
+				t.Errorf("FormatWeightFor(%.4f) = %s, want %s", tt.weight, result, tt.expected)
+			}
+			if result := tt.settings.RoundWeight(tt.weight); math.Abs(result-tt.rounded) > 1e-9 {
+				t.Errorf("RoundWeight(%.4f) = %.4f, want %.4f", tt.weight, result, tt.rounded)
+			}
+		})
+	}
+}
+
+func TestRenderWeightsTableGraphTimeAxis(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+
+	// Daily entries, two on the same day, then a two-month break
+	weights := []models.Weight{
+		{ID: 6, Date: "2024-08-03", Weight: 74.0},
+		{ID: 5, Date: "2024-08-01", Weight: 74.5},
+		{ID: 4, Date: "2024-06-04", Weight: 76.0},
+		{ID: 3, Date: "2024-06-03", Time: "20:00", Weight: 77.5},
+		{ID: 2, Date: "2024-06-03", Time: "07:00", Weight: 76.5},
+		{ID: 1, Date: "2024-06-01", Weight: 77.0},
+	}
+
+	result := display.RenderWeightsTable(weights, settings, 20)
+	for _, expected := range []string{"Jun 01", "Aug 03", "╌ no entries"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("RenderWeightsTable() output does not contain %q", expected)
+		}
+	}
+
+	ascii, _ := display.ChartRendererByName("ascii")
+	result = display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Chart: ascii})
+	for _, expected := range []string{"~ no entries", "| range of several entries"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("RenderWeightsTableOptions() with ASCII chart does not contain %q", expected)
+		}
+	}
+
+	// Evenly spaced entries have no gaps or ranges
+	weights = []models.Weight{
+		{ID: 3, Date: "2024-03-01", Weight: 74.0},
+		{ID: 2, Date: "2024-02-01", Weight: 75.0},
+		{ID: 1, Date: "2024-01-01", Weight: 76.0},
+	}
+	result = display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Chart: ascii})
+	if strings.Contains(result, "no entries") || strings.Contains(result, "range of several") {
+		t.Error("RenderWeightsTable() shows a gap or range legend for evenly spaced entries")
+	}
+}
+
+func TestRenderWeightsTableLayout(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "lbs", HeightUnit: "in", Height: 70, GoalWeight: 150}
+	var weights []models.Weight
+	for i := 30; i >= 1; i-- {
+		weights = append(weights, models.Weight{ID: i, Date: fmt.Sprintf("2024-01-%02d", i), Weight: 160 + float64(i%5), BMI: 23})
+	}
+
+	tests := []struct {
+		name    string
+		opts    display.Options
+		stacked bool
+	}{
+		{"wide terminal", display.Options{Width: 160, Height: 40}, false},
+		{"80 columns", display.Options{Width: 80, Height: 30}, false},
+		{"narrow terminal", display.Options{Width: 60, Height: 30}, true},
+		{"short terminal", display.Options{Width: 100, Height: 24}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(display.RenderWeightsTableOptions(weights, settings, 20, tt.opts), "\n")
+
+			stacked := false
+			for _, line := range lines {
+				if width := lipgloss.Width(line); width > tt.opts.Width {
+					t.Errorf("line is %d columns wide, more than %d: %q", width, tt.opts.Width, line)
+				}
+				// A stacked graph's top border starts its own line
+				stacked = stacked || strings.HasPrefix(line, "┌──────")
+			}
+			if stacked != tt.stacked {
+				t.Errorf("stacked = %v, want %v", stacked, tt.stacked)
+			}
+			if !tt.stacked && len(lines) >= tt.opts.Height {
+				t.Errorf("output is %d lines, want less than %d", len(lines), tt.opts.Height)
+			}
+		})
+	}
+
+	// Without the graph, e.g. when it is drawn as an image, only the table is rendered
+	output := display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Width: 80, Height: 30, HideGraph: true})
+	if strings.Contains(output, "Goal: ") || !strings.Contains(output, "Goal Weight:") {
+		t.Errorf("HideGraph output should have the goal header but no graph:\n%s", output)
+	}
+}
+
+func TestChartRenderers(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+	weights := []models.Weight{
+		{ID: 3, Date: "2024-01-15", Weight: 74.0},
+		{ID: 2, Date: "2024-01-08", Weight: 75.0},
+		{ID: 1, Date: "2024-01-01", Weight: 76.0},
+	}
+
+	tests := []struct {
+		name    string
+		glyphs  string // at least one of these is drawn
+		unicode bool
+	}{
+		{"braille", "⠁⠂⠄⡀⠈⠐⠠⢀", true},
+		{"block", "▁▂▃▄▅▆▇█", true},
+		{"ascii", "*", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, err := display.ChartRendererByName(tt.name)
+			if err != nil {
+				t.Fatalf("ChartRendererByName(%q) returned error: %v", tt.name, err)
+			}
+			if renderer.Unicode() != tt.unicode {
+				t.Errorf("Unicode() = %v, want %v", renderer.Unicode(), tt.unicode)
+			}
+
+			data := display.PrepareChart(weights, settings.GoalWeight)
+			plot := renderer.Plot(data, 30, 10)
+			if len(plot) != 10 || len(plot[0]) != 30 {
+				t.Fatalf("Plot() returned %dx%d grid, want 30x10", len(plot[0]), len(plot))
+			}
+
+			drawn := false
+			for _, row := range plot {
+				drawn = drawn || strings.ContainsAny(string(row), tt.glyphs)
+			}
+			if !drawn {
+				t.Errorf("Plot() draws none of %q", tt.glyphs)
+			}
+
+			result := display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Chart: renderer})
+			if !tt.unicode {
+				for _, r := range result {
+					if r > 127 {
+						t.Errorf("ASCII output contains non-ASCII character %q", r)
+						break
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := display.ChartRendererByName("sixel"); err == nil {
+		t.Error("ChartRendererByName(\"sixel\") expected error")
+	}
+}
+
+func TestChartMilestoneLines(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70, Milestones: []float64{72, 74}}
+	weights := []models.Weight{
+		{ID: 2, Date: "2024-01-15", Weight: 76.0},
+		{ID: 1, Date: "2024-01-01", Weight: 78.0},
+	}
+
+	data := display.PrepareChart(weights, settings.GoalWeight, settings.Milestones...)
+	if data.Min >= 70 || data.Max <= 78 {
+		t.Errorf("Weight axis %.2f-%.2f doesn't include the goal and entries", data.Min, data.Max)
+	}
+
+	renderer, _ := display.ChartRendererByName("ascii")
+	plot := renderer.Plot(data, 30, 12)
+	rows := map[rune]int{}
+	for _, row := range plot {
+		if line := strings.Trim(string(row), " "); len(line) == 30 {
+			rows[rune(line[0])]++
+		}
+	}
+	if rows['-'] != 1 || rows['_'] != 2 {
+		t.Errorf("Expected one goal line and two milestone lines, got %v", rows)
+	}
+
+	result := display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Chart: renderer})
+	for _, label := range []string{"Goal: 70.00", "Milestone: 72.00", "Milestone: 74.00"} {
+		if !strings.Contains(result, label) {
+			t.Errorf("Expected graph label %q", label)
+		}
+	}
+}
+
+func TestChartGoalRange(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 72, GoalLow: 71, GoalHigh: 73}
+	weights := []models.Weight{
+		{ID: 2, Date: "2024-01-15", Weight: 76.0},
+		{ID: 1, Date: "2024-01-01", Weight: 78.0},
+	}
+
+	data := display.PrepareChartFor(weights, settings)
+	if !data.HasGoalRange() || data.Min >= 71 {
+		t.Fatalf("Expected the weight axis to take in the goal range, got %.2f-%.2f", data.Min, data.Max)
+	}
+
+	renderer, _ := display.ChartRendererByName("ascii")
+	plot := renderer.Plot(data, 30, 12)
+	band := 0
+	for _, row := range plot {
+		if strings.Trim(string(row), " ") == strings.Repeat(":", 30) {
+			band++
+		}
+		if strings.ContainsRune(string(row), '-') {
+			t.Error("Expected no goal line with a goal range")
+		}
+	}
+	if band < 2 {
+		t.Errorf("Expected a band of at least 2 rows, got %d", band)
+	}
+
+	result := display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Chart: renderer})
+	for _, expected := range []string{"Goal Range: 71.00 kg - 73.00 kg (above by 3.00 kg)", "Goal: 71.00", "Goal: 73.00", ": goal range"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q", expected)
+		}
+	}
+}
+
+func TestRenderWeightsTableBMICategories(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+	weights := []models.Weight{
+		{ID: 2, Date: "2024-01-15", Weight: 78.0, BMI: 24.1},
+		{ID: 1, Date: "2024-01-01", Weight: 84.0, BMI: 25.9},
+	}
+	ascii, _ := display.ChartRendererByName("ascii")
+
+	result := display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Chart: ascii})
+	if !strings.Contains(result, "BMI: 24.1 (Normal, Prime 0.96)") {
+		t.Error("Expected the header to show the BMI category and BMI Prime")
+	}
+	if strings.Contains(result, "BMI 25.0") {
+		t.Error("Expected no BMI category lines unless asked for")
+	}
+
+	// The BMI of 25 at 180 cm is 81 kg, within the weight axis
+	result = display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Chart: ascii, BMICutoffs: true})
+	for _, expected := range []string{"BMI 25.0+===", "= BMI categories"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected output to contain %q", expected)
+		}
+	}
+
+	settings.BMIStandard = calculator.AsianStandard
+	result = display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Chart: ascii})
+	if !strings.Contains(result, "BMI: 24.1 (Overweight, Prime 1.05)") {
+		t.Error("Expected the header to use the Asian cut-offs")
+	}
+}
+
+func TestRenderWeightsTableBMIForAge(t *testing.T) {
+	settings := &models.Settings{
+		WeightUnit: "kg", HeightUnit: "cm", Height: 140, GoalWeight: 35,
+		Sex: calculator.Male, BirthDate: "2014-01-01",
+		Growth: calculator.NewLMSTable([]calculator.LMS{{Sex: calculator.Male, AgeMonths: 24, L: 0, M: 18, S: 0.1}, {Sex: calculator.Male, AgeMonths: 240, L: 0, M: 18, S: 0.1}}),
+	}
+	weights := []models.Weight{{ID: 1, Date: "2024-01-01", Weight: 35.28, BMI: 18}}
+
+	result := display.RenderWeightsTableOptions(weights, settings, 20, display.Options{})
+	if !strings.Contains(result, "BMI: 18.0 (50th percentile, Healthy weight)") {
+		t.Error("Expected the header to show the BMI-for-age percentile")
+	}
+	growth := display.RenderGrowthTable(weights, settings)
+	for _, expected := range []string{"10y 0m", "50th", "+0.00", "Healthy weight"} {
+		if !strings.Contains(growth, expected) {
+			t.Errorf("Expected growth table to contain %q", expected)
+		}
+	}
+
+	settings.Growth = nil
+	result = display.RenderWeightsTableOptions(weights, settings, 20, display.Options{})
+	if !strings.Contains(result, "BMI: 18.0 (no BMI-for-age reference for this age)") {
+		t.Error("Expected the header to say there's no reference")
+	}
+}
+
+func TestRenderWeightsTableGoalPace(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 90, Deadline: "2024-02-12"}
+	weights := []models.Weight{
+		{ID: 4, Date: "2024-01-29", Weight: 96},
+		{ID: 3, Date: "2024-01-15", Weight: 98},
+		{ID: 2, Date: "2024-01-08", Weight: 99},
+		{ID: 1, Date: "2024-01-01", Weight: 100},
+	}
+
+	result := display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Today: "2024-01-29"})
+	for _, expected := range []string{
+		"By 2024-02-12 (2 weeks left): -3.00 kg/week needed | Trend: -1.00 kg/week, behind",
+		"Warning: 3.00 kg per week is more than 1% of your body weight",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected header to contain %q", expected)
+		}
+	}
+
+	settings.Deadline = ""
+	result = display.RenderWeightsTableOptions(weights, settings, 20, display.Options{Today: "2024-01-29"})
+	if strings.Contains(result, "needed") || strings.Contains(result, "Warning") {
+		t.Error("Expected no pace or warnings without a deadline")
+	}
+}
+
+func TestRenderSummary(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+	weights := []models.Weight{
+		{Date: "2024-03-10", Weight: 77},
+		{Date: "2024-02-20", Weight: 80},
+		{Date: "2024-02-05", Weight: 78},
+		{Date: "2024-01-10", Weight: 81},
+		{Date: "2024-01-03", Weight: 82},
+	}
+	periods := analytics.Summaries(weights, analytics.Month)
+
+	ascii, _ := display.ChartRendererByName("ascii")
+	result := display.RenderSummary(periods, analytics.Month, settings, display.Options{Width: 100, Chart: ascii})
+	for _, want := range []string{
+		"Month", "January 2024", "81.50 kg", // average
+		"-1.00 kg", // change and rate per week in January
+		"+2.00 kg", // change in February
+		"+0.93 kg", // rate per week in February
+		"-2.50 kg", // average change from January to February
+		"Change in average from the previous month",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("RenderSummary() doesn't contain %q:\n%s", want, result)
+		}
+	}
+	// March has one entry, so no rate
+	for _, line := range strings.Split(result, "\n") {
+		cells := strings.Split(line, "|")
+		if strings.HasPrefix(line, "|March 2024") && strings.TrimSpace(cells[len(cells)-2]) != "-" {
+			t.Errorf("March shows a rate: %s", line)
+		}
+	}
+
+	if result := display.RenderSummary(nil, analytics.Month, settings, display.Options{}); !strings.Contains(result, "No weights tracked") {
+		t.Errorf("RenderSummary(nil) = %q", result)
+	}
+}
+
+func TestRenderCalendar(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+	days := analytics.Days([]models.Weight{
+		{Date: "2024-01-03", Weight: 79},
+		{Date: "2024-01-02", Weight: 80},
+		{Date: "2024-01-01", Weight: 80},
+	})
+	ascii, _ := display.ChartRendererByName("ascii")
+
+	result := display.RenderCalendar(2024, days, settings, "2024-01-10", display.Options{Chart: ascii})
+	lines := strings.Split(result, "\n")
+
+	// 2024 starts on a Monday, and days after today are left blank
+	want := []string{"Mon # .", "    # .", "Wed # .", "    .", "Fri .", "    .", "Sun ."}
+	for i, row := range want {
+		if got := lines[3+i]; got != row {
+			t.Errorf("calendar row %d = %q, want %q", i, got, row)
+		}
+	}
+	if !strings.Contains(lines[2], "Jan") || !strings.Contains(lines[2], "Dec") {
+		t.Errorf("calendar header = %q, want month labels", lines[2])
+	}
+	if !strings.Contains(result, "Current streak: 0 days | Longest streak: 3 days") {
+		t.Errorf("RenderCalendar() streaks are wrong:\n%s", result)
+	}
+
+	// Narrow terminals get the year in blocks
+	narrow := display.RenderCalendar(2024, days, settings, "2024-12-31", display.Options{Chart: ascii, Width: 40})
+	for _, line := range strings.Split(narrow, "\n") {
+		if lipgloss.Width(line) > 40 && !strings.Contains(line, "no entry") && !strings.Contains(line, "streak") {
+			t.Errorf("line is wider than 40 columns: %q", line)
+		}
+	}
+	if !strings.Contains(narrow, "Apr") || strings.Count(narrow, "Mon") != 4 {
+		t.Errorf("RenderCalendar() at 40 columns isn't split into quarters:\n%s", narrow)
+	}
+}
+
+func TestRenderStats(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+	weights := []models.Weight{
+		{Date: "2024-03-11", Weight: 79},
+		{Date: "2024-03-10", Weight: 80},
+		{Date: "2024-03-09", Weight: 81},
+	}
+
+	ascii, _ := display.ChartRendererByName("ascii")
+	result := display.RenderStats(analytics.Statistics(weights), settings, display.Options{Width: 100, Chart: ascii})
+	for _, want := range []string{
+		"3 entries from 2024-03-09 to 2024-03-11",
+		"Median", "80.00 kg", "Standard deviation", "1.00 kg", "90th percentile",
+		"Average fluctuation", "2 losses in a row, 2024-03-09 to 2024-03-11",
+		"Monday", "-1.00 kg", // Monday against the mean
+		"Entries by weight (kg)", "79.00 - 79.25 #",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("RenderStats() doesn't contain %q:\n%s", want, result)
+		}
+	}
+
+	if got := display.RenderStats(analytics.Statistics(nil), settings, display.Options{}); !strings.Contains(got, "No weights tracked") {
+		t.Errorf("RenderStats() without weights = %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tryonlinux/thicc/internal/editor"
+)
+
+func TestParseKeyValues(t *testing.T) {
+	doc := `# Editing weight entry 5
+date = "2024-01-02"
+
+time = ""
+weight = 70.5
+note = "said \"hi\" = fine"
+`
+
+	values, err := editor.ParseKeyValues(doc)
+	if err != nil {
+		t.Fatalf("ParseKeyValues() error: %v", err)
+	}
+
+	expected := map[string]string{
+		"date":   "2024-01-02",
+		"time":   "",
+		"weight": "70.5",
+		"note":   `said "hi" = fine`,
+	}
+	for key, want := range expected {
+		if got, ok := values[key]; !ok || got != want {
+			t.Errorf("values[%q] = %q, want %q", key, got, want)
+		}
+	}
+	if len(values) != len(expected) {
+		t.Errorf("Expected %d values, got %d", len(expected), len(values))
+	}
+}
+
+func TestChangedValues(t *testing.T) {
+	original := map[string]string{"date": "2024-01-02", "weight": "80.33", "note": ""}
+	doc := "# Editing\ndate = \"2024-01-02\"\nweight = 80.33\nnote = \"\"\n"
+
+	// Closing the editor without changes leaves every field alone
+	t.Setenv("VISUAL", "true")
+	edited, err := editor.Edit(doc, "thicc-test-*.toml")
+	if err != nil {
+		t.Fatalf("Edit() error: %v", err)
+	}
+	changed, err := editor.ChangedValues(edited, original)
+	if err != nil || len(changed) != 0 {
+		t.Errorf("ChangedValues() of an unchanged document = %v, %v; want no changes", changed, err)
+	}
+
+	changed, err = editor.ChangedValues("date = \"2024-01-02\"\nweight = 80.4\n", original)
+	if err != nil || len(changed) != 1 || changed["weight"] != "80.4" {
+		t.Errorf("ChangedValues() = %v, %v; want only the weight", changed, err)
+	}
+
+	if _, err := editor.ChangedValues("height = 180\n", original); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestParseKeyValuesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"missing equals", "date 2024-01-02"},
+		{"missing key", "= 70"},
+		{"duplicate key", "weight = 70\nweight = 71"},
+		{"unterminated string", `note = "oops`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := editor.ParseKeyValues(tt.doc); err == nil {
+				t.Errorf("ParseKeyValues(%q) expected error, got nil", tt.doc)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package tests
 
 import (
+	"math"
 	"testing"
 
+	"github.com/tryonlinux/thicc/internal/display"
 	"github.com/tryonlinux/thicc/internal/models"
 )
 
@@ -168,3 +170,227 @@ func TestGoalWeightEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestGoalProgressAndMilestones(t *testing.T) {
+	db := setupTestDB(t)
+
+	for key, value := range map[string]string{"weight_unit": "lbs", "height_unit": "in", "height": "70", "goal_weight": "150"} {
+		if err := models.SetSetting(db, key, value); err != nil {
+			t.Fatalf("Failed to set %s: %v", key, err)
+		}
+	}
+
+	// With no goals recorded, the goal weight becomes the active goal
+	if err := models.SeedGoal(db, &models.Settings{GoalWeight: 150}); err != nil {
+		t.Fatalf("SeedGoal failed: %v", err)
+	}
+	seeded, err := models.GetActiveGoal(db)
+	if err != nil || seeded == nil || seeded.Target != 150 {
+		t.Fatalf("Expected seeded goal of 150, got %+v (%v)", seeded, err)
+	}
+	if err := models.EndGoal(db, seeded.ID, models.GoalAbandoned, seeded.StartDate); err != nil {
+		t.Fatalf("EndGoal failed: %v", err)
+	}
+
+	// A goal set before any entries starts from the first one
+	id, err := models.InsertGoal(db, models.Goal{Target: 170, StartDate: "2024-01-01"})
+	if err != nil {
+		t.Fatalf("InsertGoal failed: %v", err)
+	}
+	for _, target := range []float64{190, 180} {
+		if _, err := models.InsertGoal(db, models.Goal{ParentID: id, Target: target, StartDate: "2024-01-01"}); err != nil {
+			t.Fatalf("InsertGoal failed: %v", err)
+		}
+	}
+
+	settings, err := models.GetSettings(db)
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if len(settings.Milestones) != 2 || settings.Milestones[0] != 180 || settings.Milestones[1] != 190 {
+		t.Errorf("Expected milestones [180 190], got %v", settings.Milestones)
+	}
+
+	steps := []struct {
+		date    string
+		weight  float64
+		reached []float64
+	}{
+		{"2024-01-02", 200, nil},
+		{"2024-01-09", 191, nil},
+		{"2024-01-16", 185, []float64{190}},
+		{"2024-01-23", 168, []float64{180, 170}},
+	}
+	for _, step := range steps {
+		reached, err := models.UpdateGoalProgress(db, models.Weight{Date: step.date, Weight: step.weight})
+		if err != nil {
+			t.Fatalf("UpdateGoalProgress failed: %v", err)
+		}
+		if len(reached) != len(step.reached) {
+			t.Fatalf("%s: expected %d goals reached, got %+v", step.date, len(step.reached), reached)
+		}
+		for i, g := range reached {
+			if g.Target != step.reached[i] || g.Status != models.GoalAchieved || g.EndDate != step.date {
+				t.Errorf("%s: expected %v achieved, got %+v", step.date, step.reached[i], g)
+			}
+		}
+	}
+
+	milestones, err := models.GetMilestones(db, id)
+	if err != nil {
+		t.Fatalf("GetMilestones failed: %v", err)
+	}
+	if len(milestones) != 2 || milestones[0].Target != 190 || milestones[0].StartWeight != 200 {
+		t.Errorf("Expected milestones heaviest first starting at 200, got %+v", milestones)
+	}
+
+	goals, err := models.GetGoals(db)
+	if err != nil {
+		t.Fatalf("GetGoals failed: %v", err)
+	}
+	// Without entries the seeded goal started today, after the other one
+	if len(goals) != 2 || goals[0].Status != models.GoalAbandoned || goals[1].ID != id || goals[1].Status != models.GoalAchieved {
+		t.Errorf("Expected abandoned goal then achieved goal, got %+v", goals)
+	}
+	if active, _ := models.GetActiveGoal(db); active != nil {
+		t.Errorf("Expected no active goal, got %+v", active)
+	}
+}
+
+func TestUndoGoalChange(t *testing.T) {
+	db := setupTestDB(t)
+
+	first, _ := models.InsertGoal(db, models.Goal{Target: 150, StartDate: "2024-01-01", StartWeight: 180})
+	before, _ := models.CaptureAllGoals(db)
+
+	models.EndGoal(db, first, models.GoalAbandoned, "2024-02-01")
+	second, _ := models.InsertGoal(db, models.Goal{Target: 145, StartDate: "2024-02-01", StartWeight: 170})
+	after, _ := models.CaptureAllGoals(db)
+	if err := models.RecordOperation(db, "goal", "Set goal", models.Snapshot{Goals: before}, models.Snapshot{Goals: after}); err != nil {
+		t.Fatalf("Failed to record operation: %v", err)
+	}
+
+	if _, err := models.UndoOperation(db); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	goals, _ := models.CaptureAllGoals(db)
+	if len(goals) != 1 || goals[0].ID != first || goals[0].Status != models.GoalActive || goals[0].EndDate != "" {
+		t.Errorf("Expected only the first goal, active, after undo, got %+v", goals)
+	}
+
+	if _, err := models.RedoOperation(db); err != nil {
+		t.Fatalf("Failed to redo: %v", err)
+	}
+	active, _ := models.GetActiveGoal(db)
+	if active == nil || active.ID != second || active.Target != 145 {
+		t.Errorf("Expected the second goal active after redo, got %+v", active)
+	}
+}
+
+func TestSetGoal(t *testing.T) {
+	db := setupTestDB(t)
+
+	first, _ := models.InsertGoal(db, models.Goal{Target: 150, StartDate: "2024-01-01", StartWeight: 180})
+	models.InsertGoal(db, models.Goal{ParentID: first, Target: 170, StartDate: "2024-01-01", StartWeight: 180})
+	models.SetGoalWeight(db, 150)
+
+	second, err := models.SetGoal(db, models.Goal{Target: 145, StartDate: "2024-02-01", StartWeight: 170}, []float64{160}, "Set goal weight to 145")
+	if err != nil {
+		t.Fatalf("SetGoal() returned error: %v", err)
+	}
+	active, _ := models.GetActiveGoal(db)
+	if active == nil || active.ID != second {
+		t.Fatalf("Expected the new goal active, got %+v", active)
+	}
+	if milestones, _ := models.GetMilestones(db, second); len(milestones) != 1 || milestones[0].Target != 160 {
+		t.Errorf("Expected a milestone of 160, got %+v", milestones)
+	}
+	goals, _ := models.CaptureGoals(db, first, first+1)
+	for _, g := range goals {
+		if g.Status != models.GoalAbandoned || g.EndDate != "2024-02-01" {
+			t.Errorf("Expected goal %d abandoned on 2024-02-01, got %+v", g.ID, g)
+		}
+	}
+	if values, _ := models.CaptureSettings(db, "goal_weight"); values["goal_weight"] != "145.00" {
+		t.Errorf("Expected goal_weight 145.00, got %q", values["goal_weight"])
+	}
+
+	// It's journaled with the change, so undo brings back the first goal and weight
+	if _, err := models.UndoOperation(db); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if active, _ := models.GetActiveGoal(db); active == nil || active.ID != first {
+		t.Errorf("Expected the first goal active after undo, got %+v", active)
+	}
+	if values, _ := models.CaptureSettings(db, "goal_weight"); values["goal_weight"] != "150.00" {
+		t.Errorf("Expected goal_weight 150.00 after undo, got %q", values["goal_weight"])
+	}
+
+	// Without a journal nothing changes
+	db.Exec("DROP TABLE operations")
+	if _, err := models.SetGoal(db, models.Goal{Target: 140, StartDate: "2024-03-01", StartWeight: 165}, nil, "Set goal weight to 140"); err == nil {
+		t.Error("Expected SetGoal to fail without a journal")
+	}
+	if err := models.AbandonGoal(db, first, "2024-03-01", "Abandoned goal of 150"); err == nil {
+		t.Error("Expected AbandonGoal to fail without a journal")
+	}
+	if active, _ := models.GetActiveGoal(db); active == nil || active.ID != first || active.Status != models.GoalActive {
+		t.Errorf("Expected the first goal still active, got %+v", active)
+	}
+	if values, _ := models.CaptureSettings(db, "goal_weight"); values["goal_weight"] != "150.00" {
+		t.Errorf("Expected goal_weight kept at 150.00, got %q", values["goal_weight"])
+	}
+}
+
+func TestRangeGoal(t *testing.T) {
+	db := setupTestDB(t)
+
+	id, err := models.InsertGoal(db, models.Goal{Target: 145, RangeLow: 143, RangeHigh: 147, StartDate: "2024-01-01", StartWeight: 150})
+	if err != nil {
+		t.Fatalf("InsertGoal failed: %v", err)
+	}
+	goal, _ := models.GetActiveGoal(db)
+	if goal == nil || !goal.IsRange() || goal.RangeLow != 143 || goal.RangeHigh != 147 {
+		t.Fatalf("Expected active range goal 143-147, got %+v", goal)
+	}
+	for weight, target := range map[float64]float64{150: 147, 145.5: 145.5, 140: 143} {
+		if got := goal.TargetFrom(weight); got != target {
+			t.Errorf("TargetFrom(%.1f) = %.1f, want %.1f", weight, got, target)
+		}
+	}
+	if !goal.Reached(146) || goal.Reached(148) {
+		t.Error("Expected only weights within the range to reach it")
+	}
+
+	// Maintenance goes on after reaching the range
+	reached, err := models.UpdateGoalProgress(db, models.Weight{Date: "2024-01-08", Weight: 146})
+	if err != nil || len(reached) != 0 {
+		t.Errorf("Expected a range goal not to end when reached, got %+v (%v)", reached, err)
+	}
+
+	models.SetSetting(db, "weight_unit", "lbs")
+	models.SetSetting(db, "height_unit", "in")
+	models.SetSetting(db, "height", "70")
+	models.SetGoalWeight(db, 145)
+	settings, err := models.GetSettings(db)
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if !settings.HasGoalRange() || settings.GoalLow != 143 || settings.GoalHigh != 147 || settings.GoalStart != "2024-01-01" {
+		t.Errorf("Expected settings with the goal range, got %+v", settings)
+	}
+
+	for weight, expected := range map[float64]string{150: "above by 3.00 lbs", 145: "in range", 142.5: "below by 0.50 lbs"} {
+		if got := display.FormatGoalDifference(weight, settings); got != expected {
+			t.Errorf("FormatGoalDifference(%.1f) = %q, want %q", weight, got, expected)
+		}
+	}
+
+	if _, err := models.ChangeWeightUnit(db, "lbs", "kg"); err != nil {
+		t.Fatalf("ChangeWeightUnit failed: %v", err)
+	}
+	goals, _ := models.CaptureGoals(db, id)
+	if len(goals) != 1 || math.Abs(goals[0].RangeHigh-147*0.45359237) > 1e-6 {
+		t.Errorf("Expected the range converted to kg, got %+v", goals)
+	}
+}
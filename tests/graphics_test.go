@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/tryonlinux/thicc/internal/display"
+	"github.com/tryonlinux/thicc/internal/graphics"
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+func chartImageFixture() *image.RGBA {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+	weights := []models.Weight{
+		{ID: 4, Date: "2024-03-01", Weight: 73.5},
+		{ID: 3, Date: "2024-01-15", Weight: 74.0},
+		{ID: 2, Date: "2024-01-08", Weight: 75.0},
+		{ID: 1, Date: "2024-01-01", Weight: 76.0},
+	}
+	data := display.PrepareChart(weights, settings.GoalWeight)
+	return graphics.RenderChart(data, settings, 400, 200)
+}
+
+func TestRenderChart(t *testing.T) {
+	img := chartImageFixture()
+	if img.Bounds().Dx() != 400 || img.Bounds().Dy() != 200 {
+		t.Fatalf("RenderChart() size = %v, want 400x200", img.Bounds().Size())
+	}
+
+	// The chart draws more than its background
+	background := img.At(0, 0)
+	colors := map[color.Color]bool{}
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			if c := img.At(x, y); c != background {
+				colors[c] = true
+			}
+		}
+	}
+	if len(colors) < 4 {
+		t.Errorf("RenderChart() draws %d colors besides the background, want lines, labels and anti-aliasing", len(colors))
+	}
+}
+
+func TestChartTrend(t *testing.T) {
+	settings := &models.Settings{GoalWeight: 70}
+	weights := []models.Weight{
+		{Date: "2024-01-20", Weight: 80}, // over a week after the others, so it averages alone
+		{Date: "2024-01-10", Weight: 72}, // the first two entries are more than a week before
+		{Date: "2024-01-04", Weight: 74},
+		{Date: "2024-01-02", Weight: 76},
+		{Date: "2024-01-01", Weight: 78},
+	}
+	trend := display.PrepareChart(weights, settings.GoalWeight).Trend()
+
+	expected := []float64{78, 77, 76, 73, 80}
+	for i, want := range expected {
+		if trend[i].Weight != want {
+			t.Errorf("Trend()[%d] = %.2f, want %.2f", i, trend[i].Weight, want)
+		}
+	}
+}
+
+func TestEncodeImage(t *testing.T) {
+	img := chartImageFixture()
+
+	tests := []struct {
+		protocol graphics.Protocol
+		prefix   string
+		suffix   string
+	}{
+		{graphics.ProtocolKitty, "\x1b_Ga=T,f=100,q=2,c=40,r=10,m=1;", "\x1b\\"},
+		{graphics.ProtocolITerm2, "\x1b]1337;File=inline=1;", "\a"},
+		{graphics.ProtocolSixel, "\x1bPq\"1;1;400;200", "\x1b\\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.protocol), func(t *testing.T) {
+			out, err := graphics.Encode(img, tt.protocol, 40, 10)
+			if err != nil {
+				t.Fatalf("Encode() returned error: %v", err)
+			}
+			if !strings.HasPrefix(out, tt.prefix) || !strings.HasSuffix(out, tt.suffix) {
+				t.Errorf("Encode() = %q...%q, want %q...%q", out[:min(len(out), 40)], out[max(len(out)-10, 0):], tt.prefix, tt.suffix)
+			}
+		})
+	}
+
+	// Kitty data is sent in chunks, the last one marked as such
+	out, _ := graphics.Encode(img, graphics.ProtocolKitty, 40, 10)
+	if strings.Count(out, "\x1b_G") < 2 || !strings.Contains(out, "\x1b_Gm=0;") {
+		t.Errorf("Encode(kitty) doesn't split the image into chunks ending with m=0")
+	}
+
+	if _, err := graphics.Encode(img, graphics.ProtocolNone, 40, 10); err == nil {
+		t.Error("Encode(none) should return an error")
+	}
+}
+
+func TestParseProtocol(t *testing.T) {
+	for _, name := range []string{"kitty", "iTerm2", "sixel", "none"} {
+		if p, err := graphics.ParseProtocol(name); err != nil || string(p) != strings.ToLower(name) {
+			t.Errorf("ParseProtocol(%q) = %q, %v", name, p, err)
+		}
+	}
+	if _, err := graphics.ParseProtocol("png"); err == nil {
+		t.Error("ParseProtocol(\"png\") should return an error")
+	}
+
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm-kitty")
+	if p := graphics.DetectProtocol(); p != graphics.ProtocolKitty {
+		t.Errorf("DetectProtocol() with TERM=xterm-kitty = %q, want kitty", p)
+	}
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	if p := graphics.DetectProtocol(); p != graphics.ProtocolNone {
+		t.Errorf("DetectProtocol() inside tmux = %q, want none", p)
+	}
+}
+
+func TestExportChartSVG(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+	weights := []models.Weight{
+		{ID: 3, Date: "2024-01-15", Weight: 80.0},
+		{ID: 2, Date: "2024-01-08", Weight: 82.0, Note: "cut <sugar> & snacks"},
+		{ID: 1, Date: "2024-01-01", Weight: 84.0},
+	}
+	data := display.PrepareChart(weights, settings.GoalWeight)
+
+	var out strings.Builder
+	if err := graphics.ExportChart(&out, "svg", data, settings, 800, 400); err != nil {
+		t.Fatalf("ExportChart() returned error: %v", err)
+	}
+	svg := out.String()
+
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="800" height="400"`,
+		"<polyline",               // weight and trend lines
+		"stroke-dasharray",        // goal line
+		">Normal</text>",          // BMI band from 66.7 to 80.7 kg at 180 cm
+		">Overweight</text>",      // BMI band above 80.7 kg
+		"cut &lt;sugar&gt; &amp;", // escaped note
+		"</svg>",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG chart doesn't contain %q", want)
+		}
+	}
+
+	// Axis labels follow the weight precision setting like the tables
+	precision := 2
+	settings.Precision = &precision
+	out.Reset()
+	if err := graphics.ExportChart(&out, "svg", data, settings, 800, 400); err != nil {
+		t.Fatalf("ExportChart() returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), ">80.00</text>") {
+		t.Error("SVG chart axis labels don't use the precision of 2 decimals")
+	}
+
+	if err := graphics.ExportChart(&out, "gif", data, settings, 800, 400); err == nil {
+		t.Error("ExportChart() with an unknown format should return an error")
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]string{
+		"progress.svg": "svg",
+		"Progress.PNG": "png",
+		"progress.gif": "",
+		"progress":     "",
+	}
+	for path, want := range tests {
+		if got := graphics.FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package tests
 
 import (
 	"os"
+	"slices"
 	"testing"
 
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/calculator"
 	"github.com/tryonlinux/thicc/internal/database"
 	"github.com/tryonlinux/thicc/internal/models"
 )
@@ -79,7 +82,7 @@ func TestDeleteWeight(t *testing.T) {
 	id := weights[0].ID
 
 	// Delete the weight
-	err = models.DeleteWeight(db, id)
+	err = models.TrashWeights(db, []int{id}, "Deleted entry")
 	if err != nil {
 		t.Fatalf("Failed to delete weight: %v", err)
 	}
@@ -115,7 +118,7 @@ func TestModifyWeight(t *testing.T) {
 	// Modify the weight
 	newWeight := 65.0
 	newBMI := 21.2
-	err = models.ModifyWeight(db, id, newWeight, newBMI)
+	err = models.ChangeWeights(db, "modify", "Modified entry", []int{id}, models.WeightUpdate{Weight: &newWeight, BMI: &newBMI})
 	if err != nil {
 		t.Fatalf("Failed to modify weight: %v", err)
 	}
@@ -290,3 +293,364 @@ func TestGoalWeightDifferentUnits(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateWeightFields(t *testing.T) {
+	db := setupTestDB(t)
+
+	id, err := models.InsertWeight(db, models.Weight{Date: "2024-01-01", Weight: 70.0, BMI: 22.8})
+	if err != nil {
+		t.Fatalf("Failed to insert weight: %v", err)
+	}
+
+	original, err := models.GetWeight(db, id)
+	if err != nil {
+		t.Fatalf("Failed to get weight: %v", err)
+	}
+
+	// Change date, time and note but leave weight and BMI alone
+	date := "2024-01-03"
+	timeOfDay := "07:30"
+	note := "after run"
+	err = models.ChangeWeights(db, "modify", "Modified entry", []int{id}, models.WeightUpdate{Date: &date, Time: &timeOfDay, Note: &note})
+	if err != nil {
+		t.Fatalf("Failed to update weight: %v", err)
+	}
+
+	updated, err := models.GetWeight(db, id)
+	if err != nil {
+		t.Fatalf("Failed to get weight after update: %v", err)
+	}
+
+	if updated.ID != id {
+		t.Errorf("Expected ID %d to be kept, got %d", id, updated.ID)
+	}
+	if updated.Date != date || updated.Time != timeOfDay || updated.Note != note {
+		t.Errorf("Expected %s %s %q, got %s %s %q", date, timeOfDay, note, updated.Date, updated.Time, updated.Note)
+	}
+	if updated.Weight != 70.0 || updated.BMI != 22.8 {
+		t.Errorf("Expected weight and BMI to be unchanged, got %.2f and %.2f", updated.Weight, updated.BMI)
+	}
+	if updated.CreatedAt != original.CreatedAt {
+		t.Errorf("Expected created_at %s to be kept, got %s", original.CreatedAt, updated.CreatedAt)
+	}
+}
+
+func TestUpdateWeightNotFound(t *testing.T) {
+	db := setupTestDB(t)
+
+	note := "missing"
+	err := models.ChangeWeights(db, "modify", "Modified entry", []int{42}, models.WeightUpdate{Note: &note})
+	if err != models.ErrWeightNotFound {
+		t.Errorf("Expected ErrWeightNotFound, got %v", err)
+	}
+
+	if _, err := models.GetWeight(db, 42); err != models.ErrWeightNotFound {
+		t.Errorf("Expected ErrWeightNotFound from GetWeight, got %v", err)
+	}
+}
+
+func TestTrashRestoreAndEmpty(t *testing.T) {
+	db := setupTestDB(t)
+
+	keepID, _ := models.InsertWeight(db, models.Weight{Date: "2024-01-01", Weight: 70.0, BMI: 22.8})
+	trashID, _ := models.InsertWeight(db, models.Weight{Date: "2024-01-02", Weight: 69.5, BMI: 22.6})
+
+	if err := models.TrashWeights(db, []int{trashID}, "Deleted entry"); err != nil {
+		t.Fatalf("Failed to delete weight: %v", err)
+	}
+
+	// Trashed entries are excluded from every regular query
+	weights, _ := models.GetWeights(db, 10)
+	if len(weights) != 1 || weights[0].ID != keepID {
+		t.Errorf("Expected only entry %d from GetWeights, got %+v", keepID, weights)
+	}
+	weights, _ = models.GetWeightsBetweenDates(db, "2024-01-01", "2024-01-31")
+	if len(weights) != 1 {
+		t.Errorf("Expected 1 entry from GetWeightsBetweenDates, got %d", len(weights))
+	}
+	if _, err := models.GetWeight(db, trashID); err != models.ErrWeightNotFound {
+		t.Errorf("Expected trashed entry to be hidden from GetWeight, got %v", err)
+	}
+
+	// Exports select trashed entries only when asked to
+	weights, _ = models.SelectWeights(db, models.WeightSelection{From: "2024-01-01"})
+	if len(weights) != 1 || weights[0].ID != keepID {
+		t.Errorf("Expected only entry %d from SelectWeights, got %+v", keepID, weights)
+	}
+	weights, _ = models.SelectWeights(db, models.WeightSelection{From: "2024-01-01", IncludeTrash: true})
+	if len(weights) != 2 || weights[0].ID != trashID || weights[0].DeletedAt == "" {
+		t.Errorf("Expected trashed entry %d first with IncludeTrash, got %+v", trashID, weights)
+	}
+	weights, _ = models.SelectWeights(db, models.WeightSelection{Limit: 1, IncludeTrash: true})
+	if len(weights) != 1 || weights[0].ID != trashID {
+		t.Errorf("Expected only the newest entry with Limit 1, got %+v", weights)
+	}
+
+	trashed, err := models.GetTrashedWeights(db)
+	if err != nil {
+		t.Fatalf("Failed to get trash: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].ID != trashID || trashed[0].DeletedAt == "" {
+		t.Fatalf("Expected entry %d in the trash with a deletion time, got %+v", trashID, trashed)
+	}
+
+	// Restore brings it back
+	if err := models.RestoreWeight(db, trashID); err != nil {
+		t.Fatalf("Failed to restore weight: %v", err)
+	}
+	if err := models.RestoreWeight(db, trashID); err != models.ErrNotInTrash {
+		t.Errorf("Expected ErrNotInTrash when restoring twice, got %v", err)
+	}
+	weights, _ = models.GetWeights(db, 10)
+	if len(weights) != 2 {
+		t.Errorf("Expected 2 entries after restore, got %d", len(weights))
+	}
+
+	// Emptying only removes trashed entries
+	models.TrashWeights(db, []int{trashID}, "Deleted entry")
+	if err := models.PurgeWeights(db, trashID, keepID); err != nil {
+		t.Fatalf("Failed to purge weights: %v", err)
+	}
+	trashed, _ = models.GetTrashedWeights(db)
+	if len(trashed) != 0 {
+		t.Errorf("Expected empty trash, got %d entries", len(trashed))
+	}
+	if _, err := models.GetWeight(db, keepID); err != nil {
+		t.Errorf("Expected entry %d that was not in the trash to be kept, got %v", keepID, err)
+	}
+
+	// Emptying is journaled, so undo puts the entry back in the trash
+	if _, err := models.UndoOperation(db); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	trashed, _ = models.GetTrashedWeights(db)
+	if len(trashed) != 1 || trashed[0].ID != trashID {
+		t.Errorf("Expected entry %d back in the trash after undo, got %+v", trashID, trashed)
+	}
+}
+
+func TestSelectAndBulkUpdateWeights(t *testing.T) {
+	db := setupTestDB(t)
+
+	for _, date := range []string{"2024-01-01", "2024-01-05", "2024-01-10", "2024-01-15"} {
+		models.AddWeight(db, date, 70.0, 22.8)
+	}
+
+	// Date range selection
+	weights, err := models.SelectWeights(db, models.WeightSelection{From: "2024-01-05", To: "2024-01-10"})
+	if err != nil {
+		t.Fatalf("Failed to select weights: %v", err)
+	}
+	if len(weights) != 2 {
+		t.Fatalf("Expected 2 weights in range, got %d", len(weights))
+	}
+
+	// IDs combined with a date range select the intersection
+	weights, _ = models.SelectWeights(db, models.WeightSelection{IDs: []int{1, 2, 3}, From: "2024-01-05"})
+	if len(weights) != 2 {
+		t.Errorf("Expected 2 weights for IDs within range, got %d", len(weights))
+	}
+
+	// Tags are words starting with # in the note, matched whole and ignoring case
+	for id, note := range map[int]string{1: "#Import from app", 2: "scale #import", 3: "#imported", 4: "re#import"} {
+		db.Exec("UPDATE weights SET note = ? WHERE id = ?", note, id)
+	}
+	weights, err = models.SelectWeights(db, models.WeightSelection{Tag: "import"})
+	if err != nil {
+		t.Fatalf("Failed to select weights by tag: %v", err)
+	}
+	if len(weights) != 2 || weights[0].ID != 2 || weights[1].ID != 1 {
+		t.Errorf("Expected entries 2 and 1 tagged #import, got %+v", weights)
+	}
+	weights, _ = models.SelectWeights(db, models.WeightSelection{Tag: "import", From: "2024-01-05"})
+	if len(weights) != 1 || weights[0].ID != 2 {
+		t.Errorf("Expected only entry 2 for the tag within range, got %+v", weights)
+	}
+
+	note := "new scale"
+	if err := models.ChangeWeights(db, "modify", "Modified entries", []int{2, 3}, models.WeightUpdate{Note: &note}); err != nil {
+		t.Fatalf("Failed to update weights: %v", err)
+	}
+	weights, _ = models.SelectWeights(db, models.WeightSelection{IDs: []int{2, 3}})
+	for _, w := range weights {
+		if w.Note != note {
+			t.Errorf("Expected note %q on entry %d, got %q", note, w.ID, w.Note)
+		}
+	}
+
+	// A missing ID rolls back the whole transaction
+	if err := models.TrashWeights(db, []int{1, 99}, "Deleted entries"); err != models.ErrWeightNotFound {
+		t.Errorf("Expected ErrWeightNotFound, got %v", err)
+	}
+	weights, _ = models.GetWeights(db, 10)
+	if len(weights) != 4 {
+		t.Errorf("Expected failed bulk delete to change nothing, got %d weights", len(weights))
+	}
+
+	if err := models.TrashWeights(db, []int{1, 4}, "Deleted entries"); err != nil {
+		t.Fatalf("Failed to delete weights: %v", err)
+	}
+	weights, _ = models.GetWeights(db, 10)
+	if len(weights) != 2 {
+		t.Errorf("Expected 2 weights after bulk delete, got %d", len(weights))
+	}
+}
+
+func TestRecordAchievements(t *testing.T) {
+	db := setupTestDB(t)
+
+	a := models.Achievement{Key: "lost-1", Title: "Lost 2.00 kg", Date: "2024-01-10", WeightID: 2}
+	recorded, err := models.RecordAchievement(db, a)
+	if err != nil || !recorded {
+		t.Fatalf("RecordAchievement() = %v, %v; want true, nil", recorded, err)
+	}
+	// The same milestone is only recorded once
+	if recorded, err := models.RecordAchievement(db, a); err != nil || recorded {
+		t.Errorf("RecordAchievement() again = %v, %v; want false, nil", recorded, err)
+	}
+	if _, err := models.RecordAchievement(db, models.Achievement{Key: "goal-75", Title: "Reached your goal", Date: "2024-03-01", WeightID: 5}); err != nil {
+		t.Fatalf("RecordAchievement() returned error: %v", err)
+	}
+
+	achievements, err := models.GetAchievements(db)
+	if err != nil {
+		t.Fatalf("GetAchievements() returned error: %v", err)
+	}
+	if len(achievements) != 2 || achievements[0].Key != "goal-75" || achievements[1].Title != "Lost 2.00 kg" {
+		t.Errorf("GetAchievements() = %+v, want goal-75 then lost-1", achievements)
+	}
+
+	if _, err := models.ResetData(db, false); err != nil {
+		t.Fatalf("ResetData() returned error: %v", err)
+	}
+	if achievements, _ := models.GetAchievements(db); len(achievements) != 0 {
+		t.Errorf("%d achievements left after ResetData()", len(achievements))
+	}
+}
+
+func TestPruneAchievements(t *testing.T) {
+	db := setupTestDB(t)
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 70}
+
+	models.AddWeight(db, "2024-01-01", 80, 24.7)
+	reachedID, _ := models.InsertWeight(db, models.Weight{Date: "2024-01-05", Weight: 77.5, BMI: 23.9})
+	nextID, _ := models.InsertWeight(db, models.Weight{Date: "2024-01-08", Weight: 77.8, BMI: 24.0})
+
+	// record stores the milestones reached by the current entries, as the commands do
+	record := func() map[string]int {
+		weights, _ := models.SelectWeights(db, models.WeightSelection{})
+		for _, m := range analytics.Milestones(weights, settings) {
+			models.RecordAchievement(db, models.Achievement{Key: m.Key, Title: m.Key, Date: m.Date, WeightID: m.WeightID})
+		}
+		achievements, _ := models.GetAchievements(db)
+		byKey := make(map[string]int)
+		for _, a := range achievements {
+			byKey[a.Key] = a.WeightID
+		}
+		return byKey
+	}
+
+	if got := record(); got["lost-1"] != reachedID {
+		t.Fatalf("lost-1 reached by entry %d, want %d", got["lost-1"], reachedID)
+	}
+
+	// Trashing the entry drops its achievement, and the next entry to reach the milestone gets it
+	models.TrashWeights(db, []int{reachedID}, "Deleted entry")
+	if err := models.PruneAchievements(db); err != nil {
+		t.Fatalf("PruneAchievements() returned error: %v", err)
+	}
+	if achievements, _ := models.GetAchievements(db); len(achievements) != 0 {
+		t.Errorf("Expected no achievements for trashed entries, got %+v", achievements)
+	}
+	if got := record(); got["lost-1"] != nextID {
+		t.Errorf("lost-1 reached by entry %d after trashing, want %d", got["lost-1"], nextID)
+	}
+
+	// Modifying the entry so it no longer reaches the milestone revokes it
+	weight := 78.5
+	if err := models.ChangeWeights(db, "modify", "Modified entry", []int{nextID}, models.WeightUpdate{Weight: &weight}); err != nil {
+		t.Fatalf("ChangeWeights() returned error: %v", err)
+	}
+	achievements, _ := models.GetAchievements(db)
+	weights, _ := models.SelectWeights(db, models.WeightSelection{})
+	var stale []int
+	for _, a := range analytics.StaleAchievements(achievements, weights, settings) {
+		stale = append(stale, a.ID)
+	}
+	if err := models.DeleteAchievements(db, stale...); err != nil {
+		t.Fatalf("DeleteAchievements() returned error: %v", err)
+	}
+	if got := record(); len(got) != 0 {
+		t.Errorf("Expected no achievements after the weight no longer reaches one, got %v", got)
+	}
+
+	// Without an entry reaching it, the milestone is gone
+	weight = 77.8
+	models.ChangeWeights(db, "modify", "Modified entry", []int{nextID}, models.WeightUpdate{Weight: &weight})
+	record()
+	models.TrashWeights(db, []int{nextID}, "Deleted entry")
+	models.PruneAchievements(db)
+	if got := record(); len(got) != 0 {
+		t.Errorf("Expected no achievements with only the first entry, got %v", got)
+	}
+}
+
+func TestImportGrowthReference(t *testing.T) {
+	db := setupTestDB(t)
+
+	models.SetSetting(db, "weight_unit", "kg")
+	models.SetSetting(db, "height_unit", "cm")
+	models.SetSetting(db, "height", "140")
+	models.SetGoalWeight(db, 35)
+
+	err := models.ImportGrowthReference(db, []calculator.LMS{
+		{Sex: calculator.Male, AgeMonths: 24, L: -1, M: 16, S: 0.08},
+		{Sex: calculator.Female, AgeMonths: 24, L: -1, M: 15.5, S: 0.08},
+		{Sex: calculator.Female, AgeMonths: 36, L: -1, M: 15.8, S: 0.08},
+	})
+	if err != nil {
+		t.Fatalf("ImportGrowthReference failed: %v", err)
+	}
+
+	// Importing one sex again replaces only its ages
+	if err := models.ImportGrowthReference(db, []calculator.LMS{{Sex: calculator.Female, AgeMonths: 30, L: -1, M: 15.6, S: 0.08}}); err != nil {
+		t.Fatalf("ImportGrowthReference failed: %v", err)
+	}
+	table, err := models.GetGrowthReference(db)
+	if err != nil {
+		t.Fatalf("GetGrowthReference failed: %v", err)
+	}
+	if len(table[calculator.Male]) != 1 || len(table[calculator.Female]) != 1 || table[calculator.Female][0].AgeMonths != 30 {
+		t.Errorf("Expected the female ages replaced, got %+v", table)
+	}
+
+	// A sex with nothing imported falls back to the CDC reference, while an
+	// imported one replaces it
+	db.Exec("DELETE FROM growth_references WHERE sex = ?", calculator.Male)
+	table, err = models.GetGrowthReference(db)
+	if err != nil {
+		t.Fatalf("GetGrowthReference failed: %v", err)
+	}
+	cdc := calculator.NewLMSTable(calculator.CDCGrowthReference())
+	if !slices.Equal(table[calculator.Male], cdc[calculator.Male]) {
+		t.Errorf("Expected the CDC male ages, got %d of %d", len(table[calculator.Male]), len(cdc[calculator.Male]))
+	}
+	if len(table[calculator.Female]) != 1 || table[calculator.Female][0].M != 15.6 {
+		t.Errorf("Expected only the imported female age, got %+v", table[calculator.Female])
+	}
+	models.ImportGrowthReference(db, []calculator.LMS{{Sex: calculator.Male, AgeMonths: 24, L: -1, M: 16, S: 0.08}})
+
+	// The reference is loaded with the settings once a birth date is set
+	settings, _ := models.GetSettings(db)
+	if settings.BirthDate != "" || settings.Growth != nil {
+		t.Errorf("Expected no birth date or reference by default, got %+v", settings)
+	}
+	models.SetSetting(db, models.BirthDateKey, "2020-03-01")
+	settings, _ = models.GetSettings(db)
+	if settings.BirthDate != "2020-03-01" || len(settings.Growth) != 2 {
+		t.Errorf("Expected the birth date and reference, got %+v", settings)
+	}
+	if age, ok := settings.AgeMonths("2022-03-01"); !ok || age < 23.9 || age > 24.1 {
+		t.Errorf("AgeMonths() = %.2f, %v; want 24", age, ok)
+	}
+}
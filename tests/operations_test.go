@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tryonlinux/thicc/internal/models"
+)
+
+func TestUndoRedoDelete(t *testing.T) {
+	db := setupTestDB(t)
+
+	id, err := models.InsertWeight(db, models.Weight{Date: "2024-01-01", Weight: 70.0, BMI: 22.8, Note: "start"})
+	if err != nil {
+		t.Fatalf("Failed to insert weight: %v", err)
+	}
+
+	before, err := models.CaptureWeights(db, id)
+	if err != nil || len(before) != 1 {
+		t.Fatalf("Failed to capture weight: %v", err)
+	}
+
+	if err := models.TrashWeights(db, []int{id}, "Deleted entry"); err != nil {
+		t.Fatalf("Failed to delete weight: %v", err)
+	}
+
+	// Undo brings the entry back with its ID, note and creation time
+	if _, err := models.UndoOperation(db); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	restored, err := models.GetWeight(db, id)
+	if err != nil {
+		t.Fatalf("Expected entry to be restored: %v", err)
+	}
+	if *restored != before[0] {
+		t.Errorf("Expected restored entry %+v, got %+v", before[0], *restored)
+	}
+
+	if _, err := models.UndoOperation(db); err != models.ErrNothingToUndo {
+		t.Errorf("Expected ErrNothingToUndo, got %v", err)
+	}
+
+	// Redo moves it to the trash again
+	if _, err := models.RedoOperation(db); err != nil {
+		t.Fatalf("Failed to redo: %v", err)
+	}
+	if _, err := models.GetWeight(db, id); err != models.ErrWeightNotFound {
+		t.Errorf("Expected entry to be deleted after redo, got %v", err)
+	}
+}
+
+func TestUndoSettingsAndRedoDiscard(t *testing.T) {
+	db := setupTestDB(t)
+
+	db.Exec("INSERT INTO settings (key, value) VALUES ('goal_weight', '150')")
+
+	setGoal := func(value string) {
+		before, _ := models.CaptureSettings(db, "goal_weight")
+		db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES ('goal_weight', ?)", value)
+		after, _ := models.CaptureSettings(db, "goal_weight")
+		if err := models.RecordOperation(db, "goal", "Set goal", models.Snapshot{Settings: before}, models.Snapshot{Settings: after}); err != nil {
+			t.Fatalf("Failed to record operation: %v", err)
+		}
+	}
+
+	setGoal("145")
+	setGoal("140")
+
+	if _, err := models.UndoOperation(db); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	values, _ := models.CaptureSettings(db, "goal_weight")
+	if values["goal_weight"] != "145" {
+		t.Errorf("Expected goal_weight 145 after undo, got %s", values["goal_weight"])
+	}
+
+	// A new change discards the undone one
+	setGoal("130")
+	if _, err := models.RedoOperation(db); err != models.ErrNothingToRedo {
+		t.Errorf("Expected ErrNothingToRedo, got %v", err)
+	}
+
+	ops, err := models.GetOperations(db, 10)
+	if err != nil {
+		t.Fatalf("Failed to get operations: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Errorf("Expected 2 operations in history, got %d", len(ops))
+	}
+}
+
+func TestJournaledChanges(t *testing.T) {
+	db := setupTestDB(t)
+	db.Exec("INSERT INTO settings (key, value) VALUES ('weight_unit', 'kg')")
+
+	id, _, err := models.AddEntry(db, models.Weight{Date: "2024-01-01", Weight: 70.0, BMI: 22.8})
+	if err != nil {
+		t.Fatalf("Failed to add entry: %v", err)
+	}
+	if err := models.TrashWeights(db, []int{id}, "Deleted entry"); err != nil {
+		t.Fatalf("Failed to trash entry: %v", err)
+	}
+	count, err := models.ResetData(db, false)
+	if err != nil || count != 1 {
+		t.Fatalf("ResetData() = %d, %v, want 1 entry", count, err)
+	}
+
+	// Each change is journaled with it, so undoing the reset and the delete brings the entry back
+	for range 2 {
+		if _, err := models.UndoOperation(db); err != nil {
+			t.Fatalf("Failed to undo: %v", err)
+		}
+	}
+	if _, err := models.GetWeight(db, id); err != nil {
+		t.Errorf("Expected entry back after undoing reset and delete: %v", err)
+	}
+
+	// A unit change is journaled with every entry it converted
+	if converted, err := models.ChangeWeightUnit(db, "kg", "lbs"); err != nil || converted != 1 {
+		t.Fatalf("ChangeWeightUnit() = %d, %v, want 1 entry", converted, err)
+	}
+	if _, err := models.UndoOperation(db); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if w, _ := models.GetWeight(db, id); w == nil || w.Weight != 70.0 {
+		t.Errorf("Expected the weight back in kg after undoing the unit change, got %+v", w)
+	}
+
+	// A change whose journal entry can't be written is not kept
+	if _, err := db.Exec("DROP TABLE operations"); err != nil {
+		t.Fatalf("Failed to drop journal: %v", err)
+	}
+	if _, _, err := models.AddEntry(db, models.Weight{Date: "2024-01-02", Weight: 69.0, BMI: 22.5}); err == nil {
+		t.Error("Expected AddEntry to fail without a journal")
+	}
+	if err := models.TrashWeights(db, []int{id}, "Deleted entry"); err == nil {
+		t.Error("Expected TrashWeights to fail without a journal")
+	}
+	note := "changed"
+	if err := models.ChangeWeights(db, "modify", "Modified entry", []int{id}, models.WeightUpdate{Note: &note}); err == nil {
+		t.Error("Expected ChangeWeights to fail without a journal")
+	}
+	if _, err := models.ResetData(db, false); err == nil {
+		t.Error("Expected ResetData to fail without a journal")
+	}
+	db.Exec("UPDATE weights SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", id)
+	if err := models.RestoreWeight(db, id); err == nil {
+		t.Error("Expected RestoreWeight to fail without a journal")
+	}
+	if err := models.PurgeWeights(db, id); err == nil {
+		t.Error("Expected PurgeWeights to fail without a journal")
+	}
+	db.Exec("UPDATE weights SET deleted_at = NULL WHERE id = ?", id)
+	if err := models.SetSettingJournaled(db, "weight_unit", "lbs", "Set weight_unit to lbs"); err == nil {
+		t.Error("Expected SetSettingJournaled to fail without a journal")
+	}
+	if _, err := models.ChangeWeightUnit(db, "kg", "lbs"); err == nil {
+		t.Error("Expected ChangeWeightUnit to fail without a journal")
+	}
+
+	weights, _ := models.CaptureAllWeights(db)
+	if len(weights) != 1 || weights[0].DeletedAt != "" || weights[0].Note != "" || weights[0].Weight != 70.0 {
+		t.Errorf("Expected the one entry untouched, got %+v", weights)
+	}
+	if settings, _ := models.CaptureSettings(db); settings["weight_unit"] != "kg" {
+		t.Errorf("Expected settings kept, got %v", settings)
+	}
+}
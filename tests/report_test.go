@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tryonlinux/thicc/internal/analytics"
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/pdf"
+	"github.com/tryonlinux/thicc/internal/report"
+)
+
+func TestWriteHTML(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 75}
+	weights := []models.Weight{
+		{ID: 3, Date: "2024-02-10", Weight: 80, BMI: 24.7},
+		{ID: 2, Date: "2024-02-01", Weight: 83, BMI: 25.6, Note: "<b>new</b> plan"},
+		{ID: 1, Date: "2024-01-01", Weight: 85, BMI: 26.2},
+	}
+
+	var out strings.Builder
+	if err := report.WriteHTML(&out, weights, settings, time.Date(2024, 2, 11, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteHTML() returned error: %v", err)
+	}
+	html := out.String()
+
+	for _, want := range []string{
+		"<svg xmlns=",    // embedded chart
+		"80.00 kg",       // latest weight
+		"-5.00 kg",       // change
+		"50% of the way", // 85 to 80 of 85 to 75
+		"January 2024",   // monthly summary
+		"February 2024",
+		"&lt;b&gt;new&lt;/b&gt; plan", // escaped note
+		"February 11, 2024",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report doesn't contain %q", want)
+		}
+	}
+
+	// The report is self-contained
+	for _, external := range []string{"<link", "<script", "src=", "url("} {
+		if strings.Contains(html, external) {
+			t.Errorf("report refers to an external asset: %q", external)
+		}
+	}
+
+	// Progress towards a range goal is measured to its nearest bound, so
+	// reaching the range is all the way
+	ranged := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 77.5, GoalLow: 75, GoalHigh: 80}
+	out.Reset()
+	if err := report.WriteHTML(&out, weights, ranged, time.Date(2024, 2, 11, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteHTML() returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "100% of the way") {
+		t.Error("report with a range goal reached isn't 100% of the way")
+	}
+
+	if err := report.WriteHTML(&out, nil, settings, time.Now()); err == nil {
+		t.Error("WriteHTML() with no weights should return an error")
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 75}
+	var weights []models.Weight
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		weights = append([]models.Weight{{
+			ID:     i + 1,
+			Date:   start.AddDate(0, 0, 3*i).Format("2006-01-02"),
+			Weight: 90 - float64(i)*0.25,
+			BMI:    27.8 - float64(i)*0.08,
+		}}, weights...)
+	}
+
+	var out bytes.Buffer
+	if err := report.WritePDF(&out, weights, settings, pdf.Letter, time.Now()); err != nil {
+		t.Fatalf("WritePDF() returned error: %v", err)
+	}
+	data := out.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("report isn't framed as a PDF file")
+	}
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 612 792]")) {
+		t.Error("report doesn't use Letter pages")
+	}
+	// Monthly averages for six months and the notable events need a second page
+	if pages := bytes.Count(data, []byte("/Type /Page ")); pages < 2 {
+		t.Errorf("report has %d pages, want at least 2", pages)
+	}
+
+	// Each cross-reference entry points at its object
+	var xref int
+	if _, err := fmt.Sscanf(string(data[bytes.LastIndex(data, []byte("startxref"))+len("startxref\n"):]), "%d", &xref); err != nil {
+		t.Fatalf("reading startxref: %v", err)
+	}
+	var count int
+	if _, err := fmt.Sscanf(string(data[xref:]), "xref\n0 %d\n", &count); err != nil {
+		t.Fatalf("reading xref table: %v", err)
+	}
+	entries := strings.Split(string(data[xref:]), "\n")[3 : 3+count-1]
+	for i, entry := range entries {
+		var offset int
+		fmt.Sscanf(entry, "%d", &offset)
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[offset:offset+10])
+		}
+	}
+
+	if err := report.WritePDF(&out, nil, settings, pdf.A4, time.Now()); err == nil {
+		t.Error("WritePDF() with no weights should return an error")
+	}
+}
+
+func TestPageSizeByName(t *testing.T) {
+	for name, want := range map[string]pdf.PageSize{"a4": pdf.A4, "Letter": pdf.Letter} {
+		if got, err := pdf.PageSizeByName(name); err != nil || got != want {
+			t.Errorf("PageSizeByName(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := pdf.PageSizeByName("legal"); err == nil {
+		t.Error("PageSizeByName(\"legal\") should return an error")
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	settings := &models.Settings{WeightUnit: "kg", HeightUnit: "cm", Height: 180, GoalWeight: 75}
+	weights := []models.Weight{
+		{Date: "2024-02-10", Weight: 80},
+		{Date: "2024-01-20", Weight: 81.333},
+		{Date: "2024-01-06", Weight: 83},
+	}
+	records := report.SummaryRecords(analytics.Summaries(weights, analytics.Month), analytics.Month, settings)
+
+	var csvOut strings.Builder
+	if err := report.WriteSummaryCSV(&csvOut, records); err != nil {
+		t.Fatalf("WriteSummaryCSV() returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	want := []string{
+		"period,start,end,entries,mean,min,max,first,last,change,rate_per_week,unit",
+		"month,2024-01-01,2024-01-31,2,82.17,81.33,83,83,81.33,-1.67,-0.83,kg",
+		"month,2024-02-01,2024-02-29,1,80,80,80,80,80,0,0,kg",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("WriteSummaryCSV() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	var jsonOut bytes.Buffer
+	if err := report.WriteSummaryJSON(&jsonOut, records); err != nil {
+		t.Fatalf("WriteSummaryJSON() returned error: %v", err)
+	}
+	var decoded []report.SummaryRecord
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteSummaryJSON() wrote invalid JSON: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0] != records[0] {
+		t.Errorf("WriteSummaryJSON() round trip = %+v, want %+v", decoded, records)
+	}
+
+	// Stones are rounded to the precision of their pounds, not to tenths of a stone
+	settings = &models.Settings{WeightUnit: "st", HeightUnit: "cm", Height: 180}
+	weights = []models.Weight{
+		{Date: "2024-01-20", Weight: 158.2 / 14},
+		{Date: "2024-01-06", Weight: 158.5 / 14},
+	}
+	records = report.SummaryRecords(analytics.Summaries(weights, analytics.Month), analytics.Month, settings)
+	if len(records) != 1 || records[0].First != 11.3214 || records[0].Last != 11.3 || records[0].Change != -0.0214 {
+		t.Errorf("SummaryRecords() in stones = %+v, want first 11.3214, last 11.3 and change -0.0214", records)
+	}
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/tryonlinux/thicc/internal/models"
+	"github.com/tryonlinux/thicc/internal/validation"
+)
+
+func TestParseTag(t *testing.T) {
+	for input, expected := range map[string]string{"import": "import", "#Import": "import", " new-scale_2 ": "new-scale_2"} {
+		if tag, err := validation.ParseTag(input); err != nil || tag != expected {
+			t.Errorf("ParseTag(%q) = %q, %v; want %q", input, tag, err, expected)
+		}
+	}
+	for _, input := range []string{"", "#", "two words", "im*port", "café"} {
+		if _, err := validation.ParseTag(input); err != validation.ErrInvalidTag {
+			t.Errorf("ParseTag(%q) error = %v, want ErrInvalidTag", input, err)
+		}
+	}
+}
+
+func TestParseIDList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{"3", []int{3}},
+		{"3,5", []int{3, 5}},
+		{"3,5,9-14", []int{3, 5, 9, 10, 11, 12, 13, 14}},
+		{" 9 - 11 , 2 ", []int{2, 9, 10, 11}},
+		{"5,3-6", []int{3, 4, 5, 6}},
+	}
+
+	for _, tt := range tests {
+		ids, err := validation.ParseIDList(tt.input)
+		if err != nil {
+			t.Errorf("ParseIDList(%q) error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("ParseIDList(%q) = %v, want %v", tt.input, ids, tt.expected)
+		}
+	}
+}
+
+func TestParseIDListErrors(t *testing.T) {
+	for _, input := range []string{"", "abc", "0", "-3", "5-3", "3,,5", "1-20000"} {
+		if _, err := validation.ParseIDList(input); err == nil {
+			t.Errorf("ParseIDList(%q) expected error, got nil", input)
+		}
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"30d", 30},
+		{"30", 30},
+		{"4w", 28},
+		{"1D", 1},
+	}
+
+	for _, tt := range tests {
+		days, err := validation.ParseDays(tt.input)
+		if err != nil || days != tt.expected {
+			t.Errorf("ParseDays(%q) = %d, %v; want %d", tt.input, days, err, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "0d", "-5d", "30m", "d"} {
+		if _, err := validation.ParseDays(input); err == nil {
+			t.Errorf("ParseDays(%q) expected error, got nil", input)
+		}
+	}
+}
+
+func TestParseWeightInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		unit     string
+		locale   string
+		expected float64
+	}{
+		{"75.4", "kg", "en", 75.4},
+		{" 75.4 ", "kg", "en", 75.4},
+		{"75,4", "kg", "de", 75.4},
+		{"75,4", "kg", "pt-BR", 75.4},
+		{"75.4", "kg", "de", 75.4},
+		{"75kg", "kg", "en", 75.0},
+		{"165.4lb", "lbs", "en", 165.4},
+		{"165.4 lbs", "lbs", "en", 165.4},
+		{"100kg", "lbs", "en", 220.462},
+		{"220.462lb", "kg", "en", 100.0},
+		{"11st 4lb", "lbs", "en", 158.0},
+		{"11st", "lbs", "en", 154.0},
+		{"11 st 4,5", "lbs", "fr", 158.5},
+		{"10st", "kg", "en", 63.503},
+	}
+
+	for _, tt := range tests {
+		weight, err := validation.ParseWeightInput(tt.input, tt.unit, tt.locale)
+		if err != nil {
+			t.Errorf("ParseWeightInput(%q, %s, %s) error: %v", tt.input, tt.unit, tt.locale, err)
+			continue
+		}
+		if math.Abs(weight-tt.expected) > 0.001 {
+			t.Errorf("ParseWeightInput(%q, %s, %s) = %.3f, want %.3f", tt.input, tt.unit, tt.locale, weight, tt.expected)
+		}
+	}
+}
+
+func TestParseWeightInputErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		locale string
+	}{
+		{"75,4", "en"},
+		{"", "en"},
+		{"-75", "en"},
+		{"75oz", "en"},
+		{"abc", "en"},
+		{"75.4.1", "en"},
+		{"5000kg", "en"},
+	}
+
+	for _, tt := range tests {
+		if _, err := validation.ParseWeightInput(tt.input, "kg", tt.locale); err == nil {
+			t.Errorf("ParseWeightInput(%q, kg, %s) expected error, got nil", tt.input, tt.locale)
+		}
+	}
+}
+
+func TestValidatePrecisionAndIncrement(t *testing.T) {
+	for _, value := range []string{"auto", "0", "2", "4"} {
+		if err := validation.ValidatePrecision(value); err != nil {
+			t.Errorf("ValidatePrecision(%q) returned error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "-1", "5", "1.5", "two"} {
+		if err := validation.ValidatePrecision(value); err == nil {
+			t.Errorf("ValidatePrecision(%q) expected error", value)
+		}
+	}
+
+	for _, value := range []string{"none", "0.2", "0.05", "1", "10"} {
+		if err := validation.ValidateIncrement(value); err != nil {
+			t.Errorf("ValidateIncrement(%q) returned error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "0", "-0.1", "11", "abc", "nan", "NaN", "inf", "-inf"} {
+		if err := validation.ValidateIncrement(value); err == nil {
+			t.Errorf("ValidateIncrement(%q) expected error", value)
+		}
+	}
+
+	// Rounding to a coarse increment can take a valid weight to zero, so
+	// rounded weights are validated again
+	settings := &models.Settings{WeightUnit: "kg", Increment: 10}
+	if err := validation.ValidateWeight(4); err != nil {
+		t.Fatalf("ValidateWeight(4) returned error: %v", err)
+	}
+	if rounded := settings.RoundWeight(4); validation.ValidateWeight(rounded) == nil {
+		t.Errorf("ValidateWeight(%g) of 4 rounded to 10 expected error", rounded)
+	}
+	if err := validation.ValidateWeight(math.NaN()); err == nil {
+		t.Error("ValidateWeight(NaN) expected error")
+	}
+}
+
+func TestValidateBMIStandard(t *testing.T) {
+	for _, value := range []string{"who", "asian"} {
+		if err := validation.ValidateBMIStandard(value); err != nil {
+			t.Errorf("ValidateBMIStandard(%q) returned error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "WHO", "us"} {
+		if err := validation.ValidateBMIStandard(value); err == nil {
+			t.Errorf("ValidateBMIStandard(%q) expected error", value)
+		}
+	}
+}
+
+func TestValidateBirthDate(t *testing.T) {
+	for _, value := range []string{"none", "2015-04-02"} {
+		if err := validation.ValidateBirthDate(value); err != nil {
+			t.Errorf("ValidateBirthDate(%q) returned error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "2015-02-30", "04/02/2015", "2999-01-01"} {
+		if err := validation.ValidateBirthDate(value); err == nil {
+			t.Errorf("ValidateBirthDate(%q) expected error", value)
+		}
+	}
+}
+
+func TestValidateSex(t *testing.T) {
+	for _, value := range []string{"male", "female", "none"} {
+		if err := validation.ValidateSex(value); err != nil {
+			t.Errorf("ValidateSex(%q) returned error: %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "m", "other"} {
+		if err := validation.ValidateSex(value); err == nil {
+			t.Errorf("ValidateSex(%q) expected error", value)
+		}
+	}
+}